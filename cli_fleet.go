@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/db"
+	"github.com/goodwaysIT/inspect4oracle/internal/fleet"
+	"github.com/goodwaysIT/inspect4oracle/internal/handler"
+	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+)
+
+// unsafeFileChars matches characters that should not appear in generated report file names.
+// Letters and digits of any script are kept, so names such as "核心库" stay readable.
+var unsafeFileChars = regexp.MustCompile(`[^\p{L}\p{N}._-]+`)
+
+// runFleetCommand implements the "fleet" subcommand: it inspects every database listed in an
+// inventory file with bounded concurrency and writes one report per database plus a run summary.
+func runFleetCommand(args []string) int {
+	flags := flag.NewFlagSet("fleet", flag.ContinueOnError)
+	inventoryPath := flags.String("inventory", "", "Inventory file (JSON) listing the databases to inspect")
+	outDir := flags.String("out-dir", "reports", "Directory that receives the reports and summary.json")
+	concurrency := flags.Int("concurrency", 4, "Maximum number of databases inspected at the same time")
+	format := flags.String("format", "html", "Report format: html, json, pdf, xlsx, md or txt")
+	thresholdsPath := flags.String("thresholds", "", "Thresholds file (JSON) with findings threshold profiles")
+	tnsnames := flags.String("tnsnames", "", "tnsnames.ora used to resolve TNS aliases (defaults to $TNS_ADMIN/tnsnames.ora)")
+	notifyConfig := flags.String("notify-config", "", "Notifications file (JSON); every inspection outcome is sent to its configured recipients and webhooks")
+	queryTimeout := flags.Duration("query-timeout", db.DefaultQueryTimeout, "Cancel a single query after this long and record it as a module error (0 = no limit)")
+	moduleTimeout := flags.Duration("module-timeout", handler.DefaultModuleTimeout, "Cancel the remaining queries of an inspection module after this long (0 = no limit)")
+	excludeOwnSessions := flags.Bool("exclude-own-sessions", true, "Leave the tool's own sessions (MODULE inspect4oracle) out of the session overview, wait events and ASH history")
+	debug := flags.Bool("debug", false, "Debug mode")
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s fleet:\n", os.Args[0])
+		flags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExample:\n")
+		fmt.Fprintf(os.Stderr, "  %s fleet --inventory fleet.json --out-dir reports/2025-06 --concurrency 8\n", os.Args[0])
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *inventoryPath == "" {
+		fmt.Fprintln(os.Stderr, "Missing --inventory")
+		flags.Usage()
+		return 2
+	}
+	outFormat, err := resolveOutputFormat(*format, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	logger.Init(*debug)
+	db.SetTNSNamesFile(*tnsnames)
+	db.SetQueryTimeout(*queryTimeout)
+	handler.SetModuleTimeout(*moduleTimeout)
+	db.SetExcludeOwnSessions(*excludeOwnSessions)
+	if *thresholdsPath != "" {
+		if err := handler.LoadThresholdProfiles(*thresholdsPath); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+	if *notifyConfig != "" {
+		if err := setupNotifications(*notifyConfig); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+
+	inv, err := fleet.LoadInventory(*inventoryPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create output directory: %v\n", err)
+		return 1
+	}
+
+	runStamp := time.Now().Format("20060102_150405")
+	fileNames := reportFileNames(inv.Databases)
+	// Reports go through writeReportFile, so HTML reports open without the server like those of "inspect"
+	summary := fleet.Run(inv, *concurrency, func(entry fleet.Entry, reportID string, reportData handler.ReportData) (string, error) {
+		path := filepath.Join(*outDir, fmt.Sprintf("%s_%s.%s", fileNames[entry.Name], runStamp, outFormat))
+		if err := writeReportFile(path, outFormat, reportData); err != nil {
+			return path, err
+		}
+		handler.NotifyReportFinished(reportID, reportData)
+		return path, nil
+	})
+
+	summaryPath := filepath.Join(*outDir, "summary.json")
+	if err := writeJSONFile(summaryPath, summary); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write run summary: %v\n", err)
+	}
+	printFleetSummary(summary)
+
+	if summary.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// reportFileNames maps every entry name to the file name part of its report. Names that are the same
+// after replacing unsafe characters, compared case-insensitively for Windows and macOS, such as
+// "CRM 1" and "CRM/1", get the entry's position in the inventory appended.
+func reportFileNames(entries []fleet.Entry) map[string]string {
+	names := make(map[string]string, len(entries))
+	used := make(map[string]bool, len(entries))
+	for i, entry := range entries {
+		base := unsafeFileChars.ReplaceAllString(entry.Name, "_")
+		name := base
+		for n := i + 1; used[strings.ToLower(name)]; n++ {
+			name = fmt.Sprintf("%s_%d", base, n)
+		}
+		used[strings.ToLower(name)] = true
+		names[entry.Name] = name
+	}
+	return names
+}
+
+// printFleetSummary writes a human-readable table of the run to stdout.
+func printFleetSummary(summary fleet.Summary) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "DATABASE\tSTATUS\tMODULE ERRORS\tDURATION\tREPORT / ERROR")
+	for _, r := range summary.Results {
+		status, detail := "OK", r.ReportFile
+		if !r.Success {
+			status, detail = "FAILED", r.Error
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", r.Name, status, r.ModuleErrors, r.Duration, detail)
+	}
+	tw.Flush()
+	fmt.Printf("\n%d databases: %d succeeded, %d failed\n", summary.Total, summary.Succeeded, summary.Failed)
+}
+
+// writeJSONFile writes v as indented JSON to path.
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
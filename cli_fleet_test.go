@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/fleet"
+)
+
+func TestReportFileNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    []string
+	}{
+		{"safe names", []string{"crm-prod", "erp.prod_1"}, []string{"crm-prod", "erp.prod_1"}},
+		{"CJK names", []string{"核心库", "账务库"}, []string{"核心库", "账务库"}},
+		{"same after sanitizing", []string{"CRM 1", "CRM/1", "CRM:1"}, []string{"CRM_1", "CRM_1_2", "CRM_1_3"}},
+		{"case-insensitive", []string{"crm", "CRM"}, []string{"crm", "CRM_2"}},
+		{"suffix taken by another entry", []string{"a_2", "a b", "a/b"}, []string{"a_2", "a_b", "a_b_3"}},
+		{"suffixed name taken", []string{"x y", "x_y_2", "x/y"}, []string{"x_y", "x_y_2", "x_y_3"}},
+		{"index already used", []string{"x y", "x/y", "x_y_2"}, []string{"x_y", "x_y_2", "x_y_2_3"}},
+		{"only unsafe characters", []string{"  ", "//"}, []string{"_", "__2"}},
+	}
+	for _, tt := range tests {
+		var entries []fleet.Entry
+		for _, name := range tt.entries {
+			entries = append(entries, fleet.Entry{Name: name})
+		}
+		names := reportFileNames(entries)
+		var got []string
+		for _, name := range tt.entries {
+			got = append(got, names[name])
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: file names = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/handler"
+)
+
+// runGrantsCommand implements the "grants" subcommand: it writes the SQL*Plus script that creates a
+// least-privilege monitoring user for the given inspection items, the same script as /api/grants.
+func runGrantsCommand(args []string) int {
+	flags := flag.NewFlagSet("grants", flag.ContinueOnError)
+	user := flags.String("user", "", "Monitoring user to create and grant to, e.g. MONITOR or C##MONITOR")
+	items := flags.String("items", defaultInspectItems, "Comma-separated inspection items the user must be able to run")
+	out := flags.String("out", "-", "Output file, or - for stdout")
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s grants:\n", os.Args[0])
+		flags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExample:\n")
+		fmt.Fprintf(os.Stderr, "  %s grants --user monitor --items storage,backup --out grants.sql\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  sqlplus / as sysdba @grants.sql\n")
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *user == "" {
+		fmt.Fprintln(os.Stderr, "Missing --user")
+		flags.Usage()
+		return 2
+	}
+
+	script, err := handler.GrantScript(*user, strings.Split(*items, ","))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if *out == "-" {
+		fmt.Print(script)
+		return 0
+	}
+	if err := os.WriteFile(*out, []byte(script), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write %s: %v\n", *out, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/db"
+	"github.com/goodwaysIT/inspect4oracle/internal/handler"
+	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+)
+
+// defaultInspectItems is used by the headless commands when no --items flag is given.
+const defaultInspectItems = "dbinfo,params,storage,sessions,objects,performance,security,backup"
+
+// passwordEnvVar is read when --password is not supplied, so that the password does not show up in the process list.
+const passwordEnvVar = "INSPECT4ORACLE_PASSWORD"
+
+// runInspectCommand implements the "inspect" subcommand: it runs a single inspection without
+// starting the web server and writes the rendered report to disk (or stdout).
+func runInspectCommand(args []string) int {
+	flags := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	host := flags.String("host", "", "Database host")
+	port := flags.String("port", "1521", "Database listener port")
+	service := flags.String("service", "", "Service name, or SID with --connection-type SID")
+	connectionType := flags.String("connection-type", "SERVICE_NAME", "How --service identifies the database: SERVICE_NAME or SID")
+	connect := flags.String("connect", "", "EZConnect string, (DESCRIPTION=...) descriptor or TNS alias; replaces --host, --port and --service")
+	protocol := flags.String("protocol", "TCP", "Listener protocol for --host and --port: TCP or TCPS")
+	wallet := flags.String("wallet", "", "TCPS wallet directory with cwallet.sso and/or PEM certificates (defaults to the system roots)")
+	serverDN := flags.String("server-dn", "", "TCPS: DN the database server certificate must carry, e.g. \"CN=db01,O=Example\"")
+	privilege := flags.String("privilege", "", "Connect AS SYSDBA, SYSDG or SYSBACKUP (e.g. for a mounted standby); empty for a normal session")
+	proxyClient := flags.String("proxy-client", "", "Proxy authentication: --user logs in and the session runs as this user (same as --user \"proxy[client]\")")
+	tnsnames := flags.String("tnsnames", "", "tnsnames.ora used to resolve TNS aliases (defaults to $TNS_ADMIN/tnsnames.ora)")
+	user := flags.String("user", "", "Database user")
+	password := flags.String("password", "", "Database password (defaults to $"+passwordEnvVar+")")
+	business := flags.String("business", "", "Business system name shown in the report")
+	items := flags.String("items", defaultInspectItems, "Comma-separated inspection items")
+	lang := flags.String("lang", "en", "Report language: zh, en or jp")
+	out := flags.String("out", "report.html", "Output file, or - for stdout")
+	format := flags.String("format", "", "Output format: html, json, pdf, xlsx, md or txt (defaults to the --out extension)")
+	thresholdsPath := flags.String("thresholds", "", "Thresholds file (JSON) with findings threshold profiles")
+	profile := flags.String("profile", "", "Threshold profile to evaluate findings with (defaults to the file's default profile)")
+	notifyConfig := flags.String("notify-config", "", "Notifications file (JSON); the inspection outcome is sent to the configured recipients and webhooks")
+	queryTimeout := flags.Duration("query-timeout", db.DefaultQueryTimeout, "Cancel a single query after this long and record it as a module error (0 = no limit)")
+	moduleTimeout := flags.Duration("module-timeout", handler.DefaultModuleTimeout, "Cancel the remaining queries of an inspection module after this long (0 = no limit)")
+	excludeOwnSessions := flags.Bool("exclude-own-sessions", true, "Leave the tool's own sessions (MODULE inspect4oracle) out of the session overview, wait events and ASH history")
+	debug := flags.Bool("debug", false, "Debug mode")
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s inspect:\n", os.Args[0])
+		flags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExample:\n")
+		fmt.Fprintf(os.Stderr, "  %s inspect --host 10.0.0.5 --port 1521 --service ORCLPDB1 --user system --items storage,backup --lang en --out report.html\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s inspect --host 10.0.0.5 --service ORCLPDB1 --user system --thresholds thresholds.json --profile test\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s inspect --host 10.0.0.9 --service ORCL11 --connection-type SID --user system\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s inspect --connect CRMPROD --tnsnames /etc/oracle/tnsnames.ora --user system\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s inspect --host 10.0.0.5 --service ORCLPDB1 --user \"jsmith[MONITOR_USER]\"\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s inspect --host stby01 --service STBY --user sys --privilege SYSDG --items storage,backup\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s inspect --host db01 --port 2484 --service PROD --protocol TCPS --wallet /etc/oracle/wallet --server-dn \"CN=db01,O=Example\" --user system\n", os.Args[0])
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	logger.Init(*debug)
+	db.SetTNSNamesFile(*tnsnames)
+	db.SetQueryTimeout(*queryTimeout)
+	handler.SetModuleTimeout(*moduleTimeout)
+	db.SetExcludeOwnSessions(*excludeOwnSessions)
+	if *thresholdsPath != "" {
+		if err := handler.LoadThresholdProfiles(*thresholdsPath); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+	if *notifyConfig != "" {
+		if err := setupNotifications(*notifyConfig); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+
+	req := &handler.DBConnectionRequest{
+		Business:         *business,
+		Host:             *host,
+		Port:             *port,
+		Service:          *service,
+		ConnectionType:   *connectionType,
+		ConnectString:    *connect,
+		Protocol:         *protocol,
+		WalletDir:        *wallet,
+		ServerCertDN:     *serverDN,
+		Privilege:        *privilege,
+		ProxyClient:      *proxyClient,
+		Username:         *user,
+		Password:         *password,
+		Items:            splitItems(*items),
+		Lang:             *lang,
+		ThresholdProfile: *profile,
+	}
+	if req.Password == "" {
+		req.Password = os.Getenv(passwordEnvVar)
+	}
+	if err := handler.ValidateInspectParameters(req); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid parameters: %v\n", err)
+		flags.Usage()
+		return 2
+	}
+
+	outFormat, err := resolveOutputFormat(*format, *out)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	reportData, reportID, err := handler.RunInspection(context.Background(), req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Inspection failed: %v\n", err)
+		handler.NotifyInspectionFailed(handler.NewInspectionFailure(req, err))
+		return 1
+	}
+
+	if err := writeReportFile(*out, outFormat, reportData); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+		return 1
+	}
+	logger.Infof("Report %s written to %s (%s)", reportID, *out, outFormat)
+	handler.NotifyReportFinished(reportID, reportData)
+	return 0
+}
+
+// splitItems turns "storage, backup" into []string{"storage", "backup"}, dropping empty entries.
+func splitItems(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// resolveOutputFormat returns the explicit format or derives it from the output file extension.
+func resolveOutputFormat(format, out string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(out)), ".")
+		if format == "" || format == "htm" {
+			format = "html"
+		}
+	}
+	if format == "html" {
+		return format, nil
+	}
+	for _, supported := range handler.ExportFormats() {
+		if format == supported {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported output format %q (use html or %s)", format, strings.Join(handler.ExportFormats(), ", "))
+}
+
+// writeReportFile renders reportData in the given format to path ("-" means stdout).
+func writeReportFile(path, format string, reportData handler.ReportData) error {
+	if path == "-" {
+		return renderReport(os.Stdout, format, reportData)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := renderReport(f, format, reportData); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// renderReport writes reportData to w in the given format.
+func renderReport(w io.Writer, format string, reportData handler.ReportData) error {
+	return handler.WriteReport(w, content, format, reportData)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/handler"
+	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+	"github.com/goodwaysIT/inspect4oracle/internal/notify"
+)
+
+// setupNotifications registers the notifiers configured in the notifications file at path.
+func setupNotifications(path string) error {
+	cfg, err := notify.LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	for _, n := range cfg.Notifiers(content) {
+		handler.AddReportNotifier(n)
+		logger.Infof("Report notifications enabled: %s", n.Name())
+	}
+	return nil
+}
+
+// runNotifyCommand implements the "notify" subcommand: it sends the notifications for a JSON report
+// document, which is a quick way to check a notifications file against a local SMTP or webhook stand-in.
+func runNotifyCommand(args []string) int {
+	flags := flag.NewFlagSet("notify", flag.ContinueOnError)
+	configPath := flags.String("config", "", "Notifications file (JSON)")
+	in := flags.String("in", "", "JSON report document to send")
+	reportID := flags.String("id", "", "Report ID used for the link to the stored report (optional)")
+	debug := flags.Bool("debug", false, "Debug mode")
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s notify:\n", os.Args[0])
+		flags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExample:\n")
+		fmt.Fprintf(os.Stderr, "  %s notify --config notify.json --in report.json\n", os.Args[0])
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *configPath == "" || *in == "" {
+		fmt.Fprintln(os.Stderr, "Missing --config or --in")
+		flags.Usage()
+		return 2
+	}
+
+	logger.Init(*debug)
+	cfg, err := notify.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	f, err := os.Open(*in)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	reportData, err := handler.ReadReportDocument(f)
+	f.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read %s: %v\n", *in, err)
+		return 1
+	}
+
+	// Unlike handler.NotifyReportFinished, report delivery errors here so that the exit code reflects them.
+	status := 0
+	for _, n := range cfg.Notifiers(content) {
+		if err := n.Notify(*reportID, reportData); err != nil {
+			fmt.Fprintf(os.Stderr, "Notification %s failed: %v\n", n.Name(), err)
+			status = 1
+		}
+	}
+	return status
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/handler"
+	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+)
+
+// runRenderCommand implements the "render" subcommand: it reads a JSON report document written by
+// "inspect --out report.json" (or /api/report/export) and renders it with the regular report template.
+func runRenderCommand(args []string) int {
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	in := flags.String("in", "", "JSON report document to render, or - for stdin")
+	out := flags.String("out", "report.html", "Output file, or - for stdout")
+	format := flags.String("format", "", "Output format: html, json, pdf, xlsx, md or txt (defaults to the --out extension)")
+	debug := flags.Bool("debug", false, "Debug mode")
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s render:\n", os.Args[0])
+		flags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExample:\n")
+		fmt.Fprintf(os.Stderr, "  %s render --in report.json --out report.html\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s render --in report.json --out report.pdf\n", os.Args[0])
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *in == "" {
+		fmt.Fprintln(os.Stderr, "Missing --in")
+		flags.Usage()
+		return 2
+	}
+	outFormat, err := resolveOutputFormat(*format, *out)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	logger.Init(*debug)
+
+	var src io.Reader = os.Stdin
+	if *in != "-" {
+		f, err := os.Open(*in)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer f.Close()
+		src = f
+	}
+	reportData, err := handler.ReadReportDocument(src)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read %s: %v\n", *in, err)
+		return 1
+	}
+
+	if err := writeReportFile(*out, outFormat, reportData); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+		return 1
+	}
+	logger.Infof("Report from %s written to %s (%s)", *in, *out, outFormat)
+	return 0
+}
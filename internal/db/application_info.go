@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+)
+
+// ApplicationModule is the PROGRAM and MODULE of the tool's sessions, as seen in V$SESSION, ASH and the audit trail.
+const ApplicationModule = "inspect4oracle"
+
+// setApplicationInfo tags the session through DBMS_APPLICATION_INFO.
+const setApplicationInfo = `BEGIN DBMS_APPLICATION_INFO.SET_MODULE(:1, :2); DBMS_APPLICATION_INFO.SET_CLIENT_INFO(:3); END;`
+
+// Column sizes of V$SESSION.ACTION and CLIENT_INFO; longer values are rejected by DBMS_APPLICATION_INFO.
+const (
+	maxActionLength     = 32
+	maxClientInfoLength = 64
+)
+
+// excludeOwnSessions leaves the tool's own sessions out of the session overview, see SetExcludeOwnSessions.
+var (
+	excludeOwnSessions     = true
+	excludeOwnSessionsLock sync.RWMutex
+)
+
+// applicationInfo is the ACTION and CLIENT_INFO the queries of a context run with.
+type applicationInfo struct {
+	action     string // Current inspection module, e.g. storage
+	clientInfo string // Report ID
+}
+
+type applicationInfoKey struct{}
+
+// SetExcludeOwnSessions sets whether the session overview, wait events and ASH history leave out
+// the sessions tagged with ApplicationModule. It is on by default.
+func SetExcludeOwnSessions(exclude bool) {
+	excludeOwnSessionsLock.Lock()
+	excludeOwnSessions = exclude
+	excludeOwnSessionsLock.Unlock()
+}
+
+// WithAction returns a context whose queries report action, e.g. the inspection module, in V$SESSION.ACTION.
+func WithAction(ctx context.Context, action string) context.Context {
+	info := applicationInfoFrom(ctx)
+	info.action = truncate(action, maxActionLength)
+	return context.WithValue(ctx, applicationInfoKey{}, info)
+}
+
+// WithClientInfo returns a context whose queries report clientInfo, e.g. the report ID, in V$SESSION.CLIENT_INFO.
+func WithClientInfo(ctx context.Context, clientInfo string) context.Context {
+	info := applicationInfoFrom(ctx)
+	info.clientInfo = truncate(clientInfo, maxClientInfoLength)
+	return context.WithValue(ctx, applicationInfoKey{}, info)
+}
+
+func applicationInfoFrom(ctx context.Context) applicationInfo {
+	info, _ := ctx.Value(applicationInfoKey{}).(applicationInfo)
+	return info
+}
+
+// taggedSession takes a connection from the pool and tags it with the MODULE, ACTION and CLIENT_INFO of ctx.
+// The pool may hand out any session, so this costs one round trip per query. A failed tag is only logged.
+// The caller must close the connection once the query is done.
+func taggedSession(ctx context.Context, db *sql.DB) (*sql.Conn, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info := applicationInfoFrom(ctx)
+	if _, err := conn.ExecContext(ctx, setApplicationInfo, ApplicationModule, info.action, info.clientInfo); err != nil {
+		if ctx.Err() != nil {
+			conn.Close()
+			return nil, err
+		}
+		logger.Debugf("Failed to set the session's application info: %v", err)
+	}
+	return conn, nil
+}
+
+// ownSessionsFilter returns the condition that leaves out the tool's sessions from GV$SESSION and ASH,
+// or an always-true condition when they are included.
+func ownSessionsFilter() string {
+	excludeOwnSessionsLock.RLock()
+	defer excludeOwnSessionsLock.RUnlock()
+	if !excludeOwnSessions {
+		return "1 = 1"
+	}
+	return "(module IS NULL OR module <> '" + ApplicationModule + "')"
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,342 @@
+package db
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// tnsNamesFile is the tnsnames.ora set with SetTNSNamesFile; empty falls back to $TNS_ADMIN and $ORACLE_HOME.
+var (
+	tnsNamesFile     string
+	tnsNamesFileLock sync.RWMutex
+)
+
+// Descriptor fields read back from a connect descriptor.
+var (
+	descriptorAddress  = regexp.MustCompile(`(?i)\(\s*ADDRESS\s*=((?:\s*\([^()]*\))+)\s*\)`)
+	descriptorHost     = regexp.MustCompile(`(?i)\(\s*HOST\s*=\s*([^()\s]+)\s*\)`)
+	descriptorPort     = regexp.MustCompile(`(?i)\(\s*PORT\s*=\s*(\d+)\s*\)`)
+	descriptorProtocol = regexp.MustCompile(`(?i)\(\s*PROTOCOL\s*=\s*(\w+)\s*\)`)
+	descriptorService  = regexp.MustCompile(`(?i)\(\s*SERVICE_NAME\s*=\s*([^()\s]+)\s*\)`)
+	descriptorSID      = regexp.MustCompile(`(?i)\(\s*SID\s*=\s*([^()\s]+)\s*\)`)
+	tnsAliasPattern    = regexp.MustCompile(`^[A-Za-z0-9_$#.-]+$`)
+)
+
+// ConnectAddress is one listener address of a connect descriptor.
+type ConnectAddress struct {
+	Protocol string // TCP or TCPS
+	Host     string
+	Port     int
+}
+
+// ConnectDescriptor is a resolved connect string: the full (DESCRIPTION=...) sent to the listener,
+// and the fields read back from it for display and report IDs.
+type ConnectDescriptor struct {
+	Descriptor  string
+	Addresses   []ConnectAddress // In failover order
+	ServiceName string
+	SID         string
+	Alias       string // TNS alias the descriptor was resolved from, if any
+}
+
+// SetTNSNamesFile sets the tnsnames.ora used to resolve TNS aliases. The file is read at every lookup,
+// so edits apply without a restart.
+func SetTNSNamesFile(path string) {
+	tnsNamesFileLock.Lock()
+	tnsNamesFile = path
+	tnsNamesFileLock.Unlock()
+}
+
+// TNSNamesPath returns the tnsnames.ora in use: the configured file, else $TNS_ADMIN/tnsnames.ora,
+// else $ORACLE_HOME/network/admin/tnsnames.ora. It returns "" when none is set.
+func TNSNamesPath() string {
+	tnsNamesFileLock.RLock()
+	path := tnsNamesFile
+	tnsNamesFileLock.RUnlock()
+	switch {
+	case path != "":
+		return path
+	case os.Getenv("TNS_ADMIN") != "":
+		return filepath.Join(os.Getenv("TNS_ADMIN"), "tnsnames.ora")
+	case os.Getenv("ORACLE_HOME") != "":
+		return filepath.Join(os.Getenv("ORACLE_HOME"), "network", "admin", "tnsnames.ora")
+	}
+	return ""
+}
+
+// ParseConnectString resolves a full (DESCRIPTION=...) descriptor, an EZConnect string
+// ([tcps://][//]host[:port][,host2[:port2]...][/service][:server][/instance]) or a TNS alias from tnsnames.ora.
+func ParseConnectString(connectString string) (*ConnectDescriptor, error) {
+	s := strings.TrimSpace(connectString)
+	switch {
+	case s == "":
+		return nil, fmt.Errorf("empty connect string")
+	case strings.HasPrefix(s, "("):
+		return parseDescriptor(s)
+	case tnsAliasPattern.MatchString(s) && !strings.Contains(s, ":"):
+		descriptor, err := LookupTNSAlias(s)
+		if err != nil {
+			return nil, err
+		}
+		cd, err := parseDescriptor(descriptor)
+		if err != nil {
+			return nil, fmt.Errorf("TNS alias %s: %w", s, err)
+		}
+		cd.Alias = s
+		return cd, nil
+	default:
+		descriptor, err := ezConnectDescriptor(s)
+		if err != nil {
+			return nil, err
+		}
+		return parseDescriptor(descriptor)
+	}
+}
+
+// parseDescriptor checks a connect descriptor and reads back its addresses and service.
+func parseDescriptor(descriptor string) (*ConnectDescriptor, error) {
+	descriptor = strings.Join(strings.Fields(descriptor), " ")
+	depth := 0
+	for _, r := range descriptor {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		if depth < 0 {
+			break
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced parentheses in connect descriptor")
+	}
+
+	cd := &ConnectDescriptor{Descriptor: descriptor}
+	for _, m := range descriptorAddress.FindAllStringSubmatch(descriptor, -1) {
+		host := descriptorHost.FindStringSubmatch(m[1])
+		if host == nil {
+			continue
+		}
+		addr := ConnectAddress{Protocol: ProtocolTCP, Host: host[1], Port: 1521}
+		if p := descriptorProtocol.FindStringSubmatch(m[1]); p != nil {
+			addr.Protocol = strings.ToUpper(p[1])
+		}
+		if p := descriptorPort.FindStringSubmatch(m[1]); p != nil {
+			addr.Port, _ = strconv.Atoi(p[1])
+		}
+		cd.Addresses = append(cd.Addresses, addr)
+	}
+	if len(cd.Addresses) == 0 {
+		return nil, fmt.Errorf("connect descriptor has no (ADDRESS=(HOST=...)) entry")
+	}
+	if m := descriptorService.FindStringSubmatch(descriptor); m != nil {
+		cd.ServiceName = m[1]
+	}
+	if m := descriptorSID.FindStringSubmatch(descriptor); m != nil {
+		cd.SID = m[1]
+	}
+	if cd.ServiceName == "" && cd.SID == "" {
+		return nil, fmt.Errorf("connect descriptor has neither SERVICE_NAME nor SID")
+	}
+	return cd, nil
+}
+
+// ezConnectDescriptor turns an EZConnect string into a connect descriptor. Several hosts become an
+// address list tried in order; a port applies to the hosts listed before it without their own port.
+func ezConnectDescriptor(s string) (string, error) {
+	s = strings.TrimPrefix(s, "//")
+	protocol := ProtocolTCP
+	if i := strings.Index(s, "://"); i >= 0 {
+		p, err := ParseProtocol(s[:i])
+		if err != nil {
+			return "", fmt.Errorf("EZConnect string: %w", err)
+		}
+		protocol, s = p, s[i+3:]
+	}
+	if strings.Contains(s, "?") {
+		return "", fmt.Errorf("EZConnect parameters (?...) are not supported; use a connect descriptor instead")
+	}
+
+	hostPart, rest, _ := strings.Cut(s, "/")
+	var service, server, instance string
+	if rest != "" {
+		rest, instance, _ = strings.Cut(rest, "/")
+		service, server, _ = strings.Cut(rest, ":")
+	}
+	if service == "" {
+		return "", fmt.Errorf("EZConnect string %q has no service name", s)
+	}
+
+	type hostPort struct {
+		host string
+		port int
+	}
+	var hosts []hostPort
+	pending := 0 // Hosts at the end of the list still waiting for a port
+	for _, entry := range strings.Split(hostPart, ",") {
+		entry = strings.TrimSpace(entry)
+		host, port := entry, ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			host, port = h, p
+		} else if strings.HasPrefix(entry, "[") && strings.HasSuffix(entry, "]") {
+			host = entry[1 : len(entry)-1]
+		}
+		if host == "" {
+			return "", fmt.Errorf("EZConnect string %q has an empty host", s)
+		}
+		hosts = append(hosts, hostPort{host: host})
+		pending++
+		if port != "" {
+			n, err := strconv.Atoi(port)
+			if err != nil || n <= 0 || n > 65535 {
+				return "", fmt.Errorf("invalid port %q in EZConnect string", port)
+			}
+			for i := len(hosts) - pending; i < len(hosts); i++ {
+				hosts[i].port = n
+			}
+			pending = 0
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("(DESCRIPTION=")
+	if len(hosts) > 1 {
+		b.WriteString("(FAILOVER=ON)(LOAD_BALANCE=OFF)(ADDRESS_LIST=")
+	}
+	for _, h := range hosts {
+		if h.port == 0 {
+			h.port = 1521
+		}
+		fmt.Fprintf(&b, "(ADDRESS=(PROTOCOL=%s)(HOST=%s)(PORT=%d))", protocol, h.host, h.port)
+	}
+	if len(hosts) > 1 {
+		b.WriteString(")")
+	}
+	fmt.Fprintf(&b, "(CONNECT_DATA=(SERVICE_NAME=%s)", service)
+	if server != "" {
+		fmt.Fprintf(&b, "(SERVER=%s)", strings.ToUpper(server))
+	}
+	if instance != "" {
+		fmt.Fprintf(&b, "(INSTANCE_NAME=%s)", instance)
+	}
+	b.WriteString("))")
+	return b.String(), nil
+}
+
+// LookupTNSAlias returns the connect descriptor of alias from the tnsnames.ora in use.
+// Aliases match case-insensitively, also without their domain (CRM matches CRM.WORLD); a name
+// without domain that matches several entries, e.g. CRM.WORLD and CRM.PROD, is an error.
+func LookupTNSAlias(alias string) (string, error) {
+	path := TNSNamesPath()
+	if path == "" {
+		return "", fmt.Errorf("cannot resolve TNS alias %s: no tnsnames.ora configured (set -tnsnames or TNS_ADMIN)", alias)
+	}
+	entries, err := ReadTNSNames(path)
+	if err != nil {
+		return "", err
+	}
+	key := strings.ToUpper(alias)
+	if descriptor, ok := entries[key]; ok {
+		return descriptor, nil
+	}
+	var matches []string
+	for name := range entries {
+		if base, _, ok := strings.Cut(name, "."); ok && base == key {
+			matches = append(matches, name)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("TNS alias %s not found in %s", alias, path)
+	case 1:
+		return entries[matches[0]], nil
+	}
+	sort.Strings(matches)
+	return "", fmt.Errorf("TNS alias %s is ambiguous in %s: it matches %s; use the full name", alias, path, strings.Join(matches, ", "))
+}
+
+// ReadTNSNames parses a tnsnames.ora file into upper-case aliases and their descriptors.
+// IFILE entries are followed, relative to the including file.
+func ReadTNSNames(path string) (map[string]string, error) {
+	entries := make(map[string]string)
+	if err := readTNSNames(path, entries, 0); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func readTNSNames(path string, entries map[string]string, depth int) error {
+	if depth > 8 {
+		return fmt.Errorf("%s: IFILE nesting too deep", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read tnsnames.ora: %w", err)
+	}
+
+	// Drop comments, then split into "names = value" entries at the top level.
+	var text strings.Builder
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		text.WriteString(line + "\n")
+	}
+	s := text.String()
+	for len(strings.TrimSpace(s)) > 0 {
+		eq := strings.Index(s, "=")
+		if eq < 0 {
+			return fmt.Errorf("%s: expected alias = descriptor near %q", path, strings.TrimSpace(s))
+		}
+		names := strings.TrimSpace(s[:eq])
+		s = strings.TrimLeft(s[eq+1:], " \t\r\n")
+
+		var value string
+		if strings.HasPrefix(s, "(") {
+			level, end := 0, -1
+			for i, r := range s {
+				if r == '(' {
+					level++
+				} else if r == ')' {
+					level--
+					if level == 0 {
+						end = i + 1
+						break
+					}
+				}
+			}
+			if end < 0 {
+				return fmt.Errorf("%s: unbalanced parentheses in entry %s", path, names)
+			}
+			value, s = s[:end], s[end:]
+		} else {
+			line, rest, _ := strings.Cut(s, "\n")
+			value, s = strings.TrimSpace(line), rest
+		}
+
+		if strings.EqualFold(names, "IFILE") {
+			include := strings.Trim(value, `"'`)
+			if !filepath.IsAbs(include) {
+				include = filepath.Join(filepath.Dir(path), include)
+			}
+			if err := readTNSNames(include, entries, depth+1); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, name := range strings.Split(names, ",") {
+			if name = strings.ToUpper(strings.TrimSpace(name)); name != "" {
+				entries[name] = strings.Join(strings.Fields(value), " ")
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseConnectString(t *testing.T) {
+	tests := []struct {
+		name       string
+		in         string
+		addresses  []ConnectAddress
+		service    string
+		sid        string
+		descriptor string // Substring expected in the descriptor, if set
+	}{
+		{
+			name:      "host and port",
+			in:        "db1.example.com:1522/ORCLPDB",
+			addresses: []ConnectAddress{{ProtocolTCP, "db1.example.com", 1522}},
+			service:   "ORCLPDB",
+		},
+		{
+			name:      "leading slashes and default port",
+			in:        "//db1/ORCLPDB",
+			addresses: []ConnectAddress{{ProtocolTCP, "db1", 1521}},
+			service:   "ORCLPDB",
+		},
+		{
+			name:       "port applies to the hosts before it",
+			in:         "db1,db2:1522,db3:1523/ORCL",
+			addresses:  []ConnectAddress{{ProtocolTCP, "db1", 1522}, {ProtocolTCP, "db2", 1522}, {ProtocolTCP, "db3", 1523}},
+			service:    "ORCL",
+			descriptor: "(DESCRIPTION=(FAILOVER=ON)(LOAD_BALANCE=OFF)(ADDRESS_LIST=(ADDRESS=(PROTOCOL=TCP)(HOST=db1)(PORT=1522))",
+		},
+		{
+			name:      "trailing host without port",
+			in:        "db1:1525,db2/ORCL",
+			addresses: []ConnectAddress{{ProtocolTCP, "db1", 1525}, {ProtocolTCP, "db2", 1521}},
+			service:   "ORCL",
+		},
+		{
+			name:      "IPv6 with port",
+			in:        "[2001:db8::10]:1522/ORCL",
+			addresses: []ConnectAddress{{ProtocolTCP, "2001:db8::10", 1522}},
+			service:   "ORCL",
+		},
+		{
+			name:      "IPv6 without port",
+			in:        "[::1]/ORCL",
+			addresses: []ConnectAddress{{ProtocolTCP, "::1", 1521}},
+			service:   "ORCL",
+		},
+		{
+			name:       "tcps",
+			in:         "tcps://db1.example.com:2484/ORCL",
+			addresses:  []ConnectAddress{{ProtocolTCPS, "db1.example.com", 2484}},
+			service:    "ORCL",
+			descriptor: "(PROTOCOL=TCPS)",
+		},
+		{
+			name:       "server and instance",
+			in:         "db1/ORCL:dedicated/orcl1",
+			addresses:  []ConnectAddress{{ProtocolTCP, "db1", 1521}},
+			service:    "ORCL",
+			descriptor: "(CONNECT_DATA=(SERVICE_NAME=ORCL)(SERVER=DEDICATED)(INSTANCE_NAME=orcl1))",
+		},
+		{
+			name:      "descriptor with SID",
+			in:        "(DESCRIPTION =\n  (ADDRESS = (PROTOCOL = tcps)(HOST = db1)(PORT = 2484))\n  (CONNECT_DATA = (SID = ORCL)))",
+			addresses: []ConnectAddress{{ProtocolTCPS, "db1", 2484}},
+			sid:       "ORCL",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cd, err := ParseConnectString(tt.in)
+			if err != nil {
+				t.Fatalf("ParseConnectString(%q): %v", tt.in, err)
+			}
+			if !reflect.DeepEqual(cd.Addresses, tt.addresses) {
+				t.Errorf("addresses = %+v, want %+v", cd.Addresses, tt.addresses)
+			}
+			if cd.ServiceName != tt.service || cd.SID != tt.sid {
+				t.Errorf("service = %q, SID = %q, want %q, %q", cd.ServiceName, cd.SID, tt.service, tt.sid)
+			}
+			if !strings.Contains(cd.Descriptor, tt.descriptor) {
+				t.Errorf("descriptor %s does not contain %s", cd.Descriptor, tt.descriptor)
+			}
+		})
+	}
+}
+
+func TestParseConnectStringErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", "empty connect string"},
+		{"db1:1521", "no service name"},
+		{"db1:99999/ORCL", "invalid port"},
+		{"db1,:1521/ORCL", "empty host"},
+		{"ftp://db1/ORCL", "EZConnect string"},
+		{"db1/ORCL?connect_timeout=5", "not supported"},
+		{"(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db1)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=ORCL))", "unbalanced parentheses"},
+		{"(DESCRIPTION=(ADDRESS=(HOST=db1)))(CONNECT_DATA=(SERVICE_NAME=ORCL)))(", "unbalanced parentheses"},
+		{"(DESCRIPTION=(CONNECT_DATA=(SERVICE_NAME=ORCL)))", "no (ADDRESS=(HOST=...)) entry"},
+		{"(DESCRIPTION=(ADDRESS=(HOST=db1)(PORT=1521)))", "neither SERVICE_NAME nor SID"},
+	}
+	for _, tt := range tests {
+		if _, err := ParseConnectString(tt.in); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseConnectString(%q) error = %v, want %q", tt.in, err, tt.want)
+		}
+	}
+}
+
+// writeTNSNames writes the named files into a temporary directory and returns its path.
+func writeTNSNames(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+const (
+	crmWorld = "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=crm1)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=CRM)))"
+	crmProd  = "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=crm2)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=CRMPROD)))"
+	hrWorld  = "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=hr1)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=HR)))"
+	erp      = "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCPS)(HOST=erp1)(PORT=2484))(CONNECT_DATA=(SERVICE_NAME=ERP)))"
+)
+
+func TestReadTNSNames(t *testing.T) {
+	dir := writeTNSNames(t, map[string]string{
+		"tnsnames.ora": `# Production databases
+CRM.WORLD, crm.prod =
+  (DESCRIPTION =
+    (ADDRESS = (PROTOCOL = TCP)(HOST = crm1)(PORT = 1521))   # primary
+    (CONNECT_DATA = (SERVICE_NAME = CRM))
+  )
+IFILE = common.ora
+`,
+		"common.ora": "HR.WORLD = " + hrWorld + "\nIFILE = \"sub/erp.ora\"\n",
+	})
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	// IFILE is relative to the including file, here common.ora
+	if err := os.WriteFile(filepath.Join(dir, "sub", "erp.ora"), []byte("erp=\n"+erp+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ReadTNSNames(filepath.Join(dir, "tnsnames.ora"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	crm := "(DESCRIPTION = (ADDRESS = (PROTOCOL = TCP)(HOST = crm1)(PORT = 1521)) (CONNECT_DATA = (SERVICE_NAME = CRM)) )"
+	want := map[string]string{"CRM.WORLD": crm, "CRM.PROD": crm, "HR.WORLD": hrWorld, "ERP": erp}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("entries = %v, want %v", entries, want)
+	}
+}
+
+func TestReadTNSNamesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unbalanced parentheses", "CRM = (DESCRIPTION=(ADDRESS=(HOST=crm1))\n", "unbalanced parentheses in entry CRM"},
+		{"missing equals sign", "CRM\n", "expected alias = descriptor"},
+		{"missing IFILE", "IFILE = missing.ora\n", "failed to read tnsnames.ora"},
+		{"IFILE loop", "IFILE = tnsnames.ora\n", "IFILE nesting too deep"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTNSNames(t, map[string]string{"tnsnames.ora": tt.content})
+			if _, err := ReadTNSNames(filepath.Join(dir, "tnsnames.ora")); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupTNSAlias(t *testing.T) {
+	dir := writeTNSNames(t, map[string]string{
+		"tnsnames.ora": "CRM.WORLD = " + crmWorld + "\nCRM.PROD = " + crmProd + "\nHR.WORLD = " + hrWorld + "\nERP = " + erp + "\n",
+	})
+	SetTNSNamesFile(filepath.Join(dir, "tnsnames.ora"))
+	t.Cleanup(func() { SetTNSNamesFile("") })
+
+	tests := []struct {
+		alias string
+		want  string
+		err   string
+	}{
+		{alias: "erp", want: erp},
+		{alias: "crm.prod", want: crmProd},
+		{alias: "HR", want: hrWorld},
+		{alias: "CRM", err: "TNS alias CRM is ambiguous"},
+		{alias: "crm", err: "matches CRM.PROD, CRM.WORLD"},
+		{alias: "SALES", err: "TNS alias SALES not found"},
+	}
+	for _, tt := range tests {
+		got, err := LookupTNSAlias(tt.alias)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("LookupTNSAlias(%q) error = %v, want %q", tt.alias, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("LookupTNSAlias(%q) = %q, %v, want %q", tt.alias, got, err, tt.want)
+		}
+	}
+
+	cd, err := ParseConnectString("hr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cd.Alias != "hr" || cd.ServiceName != "HR" || cd.Addresses[0].Host != "hr1" {
+		t.Errorf("ParseConnectString(hr) = %+v", cd)
+	}
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultQueryTimeout is the query timeout used until SetQueryTimeout is called.
+const DefaultQueryTimeout = 2 * time.Minute
+
+// ErrQueryTimeout is returned by queries that ran longer than the query timeout.
+var ErrQueryTimeout = errors.New("query timed out")
+
+// queryTimeout limits every single query; 0 means no limit other than the caller's context.
+var (
+	queryTimeout     = DefaultQueryTimeout
+	queryTimeoutLock sync.RWMutex
+)
+
+// SetQueryTimeout sets how long a single query may run before it is cancelled. 0 disables the limit.
+func SetQueryTimeout(d time.Duration) {
+	queryTimeoutLock.Lock()
+	queryTimeout = d
+	queryTimeoutLock.Unlock()
+}
+
+// QueryTimeout returns the current query timeout.
+func QueryTimeout() time.Duration {
+	queryTimeoutLock.RLock()
+	defer queryTimeoutLock.RUnlock()
+	return queryTimeout
+}
+
+// timeoutLogKey carries the *timeoutLog of TrackTimeouts in a context.
+type timeoutLogKey struct{}
+
+type timeoutLog struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+// TrackTimeouts returns a context that records the queries timing out under it, so that callers
+// which only show per-query errors as cards can still report them; see TimedOutQueries.
+func TrackTimeouts(ctx context.Context) context.Context {
+	return context.WithValue(ctx, timeoutLogKey{}, &timeoutLog{})
+}
+
+// TimedOutQueries returns the queries that timed out under a context from TrackTimeouts, in order.
+func TimedOutQueries(ctx context.Context) []error {
+	log, ok := ctx.Value(timeoutLogKey{}).(*timeoutLog)
+	if !ok {
+		return nil
+	}
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	return append([]error(nil), log.errs...)
+}
+
+// queryContext derives the context of one query from ctx, limited by the query timeout.
+func queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if d := QueryTimeout(); d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+	return context.WithCancel(ctx)
+}
+
+// queryError explains err of a query run under qctx, derived from ctx by queryContext.
+// A query that hit its own deadline becomes ErrQueryTimeout and is recorded for TimedOutQueries;
+// a query stopped by ctx (module timeout, client gone) reports the cause of ctx.
+func queryError(ctx, qctx context.Context, query string, err error) error {
+	if err == nil || qctx.Err() == nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("query cancelled: %w", context.Cause(ctx))
+	}
+	err = fmt.Errorf("%w after %s", ErrQueryTimeout, QueryTimeout())
+	if log, ok := ctx.Value(timeoutLogKey{}).(*timeoutLog); ok {
+		log.mu.Lock()
+		log.errs = append(log.errs, fmt.Errorf("%s: %w", summarizeQuery(query), err))
+		log.mu.Unlock()
+	}
+	return err
+}
+
+// summarizeQuery shortens a query to its first words for error messages.
+func summarizeQuery(query string) string {
+	s := strings.Join(strings.Fields(query), " ")
+	if r := []rune(s); len(r) > 80 {
+		s = string(r[:77]) + "..."
+	}
+	return s
+}
+
+// queryRow runs a single-row query under the query timeout and scans the row into dest.
+func queryRow(ctx context.Context, db *sql.DB, query string, dest ...interface{}) error {
+	qctx, cancel := queryContext(ctx)
+	defer cancel()
+	conn, err := taggedSession(qctx, db)
+	if err != nil {
+		return queryError(ctx, qctx, query, err)
+	}
+	defer conn.Close()
+	return queryError(ctx, qctx, query, conn.QueryRowContext(qctx, query).Scan(dest...))
+}
+
+// queryRows runs query on a tagged session. done closes the rows and returns the session to the pool.
+func queryRows(qctx context.Context, db *sql.DB, query string, args ...interface{}) (rows *sql.Rows, done func(), err error) {
+	conn, err := taggedSession(qctx, db)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rows, err = conn.QueryContext(qctx, query, args...); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return rows, func() {
+		rows.Close()
+		conn.Close()
+	}, nil
+}
+
+// ping checks the connection on a tagged session, so that even the first round trip is identifiable.
+func ping(ctx context.Context, db *sql.DB) error {
+	pctx, cancel := queryContext(ctx)
+	defer cancel()
+	conn, err := taggedSession(pctx, db)
+	if err == nil {
+		err = conn.PingContext(pctx)
+		conn.Close()
+	}
+	return queryError(ctx, pctx, "ping", err)
+}
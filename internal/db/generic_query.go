@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -13,13 +14,17 @@ import (
 // ExecuteGenericQuery 执行一个通用的SQL查询并返回结果。
 // It returns a slice of maps, where each map represents a row with column names as keys.
 // It also returns a slice of column names and any error that occurred.
-func ExecuteGenericQuery(db *sql.DB, query string, args ...interface{}) ([]map[string]interface{}, []string, error) {
-	rows, err := db.Query(query, args...)
+// The query is cancelled with ctx or after the query timeout, see SetQueryTimeout.
+func ExecuteGenericQuery(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]map[string]interface{}, []string, error) {
+	qctx, cancel := queryContext(ctx)
+	defer cancel()
+	rows, done, err := queryRows(qctx, db, query, args...)
 	if err != nil {
+		err = queryError(ctx, qctx, query, err)
 		logger.Errorf("Failed to execute query: %s, error: %v", query, err)
 		return nil, nil, fmt.Errorf("failed to execute query '%s': %w", query, err)
 	}
-	defer rows.Close()
+	defer done()
 
 	columns, err := rows.Columns()
 	if err != nil {
@@ -62,6 +67,7 @@ func ExecuteGenericQuery(db *sql.DB, query string, args ...interface{}) ([]map[s
 	}
 
 	if err = rows.Err(); err != nil {
+		err = queryError(ctx, qctx, query, err)
 		logger.Errorf("Error while iterating over result set: %s, error: %v", query, err)
 		return results, columns, fmt.Errorf("error iterating over result set for query '%s': %w", query, err)
 	}
@@ -132,12 +138,13 @@ func findScanDestination(colName string, structType reflect.Type, structVal refl
 }
 
 // ExecuteQueryAndScanToStructs executes a query and scans the results directly into a slice of structs.
+// - ctx: Cancels the query; it is also cancelled after the query timeout, see SetQueryTimeout.
 // - db: The database connection.
 // - destSlice: A pointer to a slice of structs (e.g., *[]MyStruct) where results will be stored.
 // - query: The SQL query string.
 // - args: Arguments for the query.
 // This function uses reflection and maps columns to struct fields by comparing their uppercase names.
-func ExecuteQueryAndScanToStructs(db *sql.DB, destSlice interface{}, query string, args ...interface{}) error {
+func ExecuteQueryAndScanToStructs(ctx context.Context, db *sql.DB, destSlice interface{}, query string, args ...interface{}) error {
 	destVal := reflect.ValueOf(destSlice)
 	if destVal.Kind() != reflect.Ptr {
 		return fmt.Errorf("destSlice must be a pointer to a slice, got %T", destSlice)
@@ -152,12 +159,15 @@ func ExecuteQueryAndScanToStructs(db *sql.DB, destSlice interface{}, query strin
 		return fmt.Errorf("slice elements must be structs, got %s", structType.Kind())
 	}
 
-	rows, err := db.Query(query, args...)
+	qctx, cancel := queryContext(ctx)
+	defer cancel()
+	rows, done, err := queryRows(qctx, db, query, args...)
 	if err != nil {
+		err = queryError(ctx, qctx, query, err)
 		logger.Errorf("Failed to execute query: %s, error: %v", query, err)
 		return fmt.Errorf("failed to execute query '%s': %w", query, err)
 	}
-	defer rows.Close()
+	defer done()
 
 	columns, err := rows.Columns()
 	if err != nil {
@@ -189,6 +199,7 @@ func ExecuteQueryAndScanToStructs(db *sql.DB, destSlice interface{}, query strin
 	}
 
 	if err = rows.Err(); err != nil {
+		err = queryError(ctx, qctx, query, err)
 		logger.Errorf("Error while iterating over result set: %s, error: %v", query, err)
 		return fmt.Errorf("error iterating over result set for query '%s': %w", query, err)
 	}
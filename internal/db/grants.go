@@ -0,0 +1,94 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// publicViews are granted to PUBLIC by the database and need no grant of their own.
+var publicViews = map[string]bool{
+	"nls_database_parameters": true,
+	"role_role_privs":         true,
+}
+
+// userNamePattern accepts the unquoted Oracle user names GrantScript writes into the script, e.g. MONITOR or C##MONITOR.
+var userNamePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_$#]*$`)
+
+// maxUserNameLength is the longest user name since 12.2.
+const maxUserNameLength = 128
+
+// GrantScript returns a SQL*Plus script that creates user and grants it CREATE SESSION and SELECT on
+// exactly views, for a dedicated least-privilege monitoring account. The password is prompted for
+// when the script runs; common users (C##) are created and granted in all containers.
+func GrantScript(user string, views []string) (string, error) {
+	user = strings.ToUpper(strings.TrimSpace(user))
+	if len(user) > maxUserNameLength || !userNamePattern.MatchString(user) {
+		return "", fmt.Errorf("invalid user name %q", user)
+	}
+	container := ""
+	if strings.HasPrefix(user, "C##") {
+		container = " CONTAINER=ALL"
+	}
+
+	var b strings.Builder
+	b.WriteString("SET DEFINE ON\nSET VERIFY OFF\n")
+	fmt.Fprintf(&b, "ACCEPT password CHAR PROMPT 'Password for %s: ' HIDE\n\n", user)
+	if container != "" {
+		b.WriteString("-- Common user: run in CDB$ROOT\n")
+	}
+	b.WriteString("-- Fails harmlessly when the user exists; the grants below are still applied\n")
+	fmt.Fprintf(&b, "CREATE USER %s IDENTIFIED BY \"&password\"%s;\n", user, container)
+	fmt.Fprintf(&b, "GRANT CREATE SESSION TO %s%s;\n\n", user, container)
+	for _, view := range uniqueViews(views) {
+		if publicViews[view] {
+			fmt.Fprintf(&b, "-- %s is granted to PUBLIC\n", strings.ToUpper(view))
+			continue
+		}
+		// SQL*Plus only ends a statement at a semicolon at the end of the line, so notes go above it
+		if needsDiagnosticsPack(view) {
+			b.WriteString("-- Requires the Diagnostics Pack license\n")
+		}
+		fmt.Fprintf(&b, "GRANT SELECT ON %s TO %s%s;\n", grantObject(view), user, container)
+	}
+	b.WriteString("\nUNDEFINE password\n")
+	return b.String(), nil
+}
+
+// grantObject returns the SYS object SELECT is granted on. V$ and GV$ names are public synonyms
+// of the SYS.V_$ and SYS.GV_$ views, and Oracle does not accept grants on them.
+func grantObject(view string) string {
+	name := strings.ToUpper(view)
+	switch {
+	case strings.HasPrefix(name, "V$"):
+		name = "V_$" + name[len("V$"):]
+	case strings.HasPrefix(name, "GV$"):
+		name = "GV_$" + name[len("GV$"):]
+	}
+	return "SYS." + name
+}
+
+// isFixedView reports whether view is a dynamic performance (V$/GV$) view, which stays readable while the database is only MOUNTED.
+func isFixedView(view string) bool {
+	return strings.HasPrefix(view, "v$") || strings.HasPrefix(view, "gv$")
+}
+
+// needsDiagnosticsPack reports whether view belongs to ASH or AWR.
+func needsDiagnosticsPack(view string) bool {
+	return strings.HasPrefix(view, "dba_hist_") || strings.HasSuffix(view, "active_session_history")
+}
+
+// uniqueViews lower-cases views and drops duplicates, keeping the first occurrence.
+func uniqueViews(views []string) []string {
+	seen := make(map[string]bool, len(views))
+	unique := make([]string, 0, len(views))
+	for _, view := range views {
+		view = strings.ToLower(strings.TrimSpace(view))
+		if view == "" || seen[view] {
+			continue
+		}
+		seen[view] = true
+		unique = append(unique, view)
+	}
+	return unique
+}
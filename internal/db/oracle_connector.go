@@ -1,8 +1,10 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/goodwaysIT/inspect4oracle/internal/logger"
@@ -10,6 +12,19 @@ import (
 	go_ora "github.com/sijms/go-ora/v2"
 )
 
+// Connection types: how DBName identifies the database.
+const (
+	ConnectionTypeServiceName = "SERVICE_NAME"
+	ConnectionTypeSID         = "SID" // For older databases that are only registered by SID
+)
+
+// Administrative privileges a session can connect with, as in CONNECT ... AS SYSDBA.
+const (
+	PrivilegeSYSDBA    = "SYSDBA"
+	PrivilegeSYSDG     = "SYSDG"     // Data Guard administration, enough for mounted standbys
+	PrivilegeSYSBACKUP = "SYSBACKUP" // RMAN backup and recovery
+)
+
 // ConnectionDetails holds all necessary information for connecting to Oracle DB.
 // This can be expanded later if more specific go-ora parameters are needed.
 type ConnectionDetails struct {
@@ -18,34 +33,135 @@ type ConnectionDetails struct {
 	Host           string
 	Port           int
 	DBName         string // SID or Service Name
-	ConnectionType string // "SID" or "SERVICE_NAME"
+	ConnectionType string // "SID" or "SERVICE_NAME"; empty means SERVICE_NAME
+	Descriptor     string // Full (DESCRIPTION=...) connect descriptor; replaces Host, Port and DBName when set
+	Protocol       string // "TCP" (default) or "TCPS" for Host and Port; descriptors name their own protocol
+	WalletDir      string // TCPS: directory with cwallet.sso and/or PEM certificates
+	ServerCertDN   string // TCPS: expected server certificate DN; defaults to SSL_SERVER_CERT_DN of the descriptor
+	Privilege      string // Empty for a normal session, or SYSDBA, SYSDG or SYSBACKUP
+	ProxyClient    string // Proxy authentication: User and Password log in, the session runs as ProxyClient
+}
+
+// SplitProxyUser splits the "proxy_user[client_user]" login syntax of SQL*Plus. Without brackets,
+// client is empty.
+func SplitProxyUser(user string) (proxyUser, client string) {
+	open := strings.Index(user, "[")
+	if open < 0 || !strings.HasSuffix(user, "]") {
+		return user, ""
+	}
+	return strings.TrimSpace(user[:open]), strings.TrimSpace(user[open+1 : len(user)-1])
+}
+
+// ParseConnectionType normalizes a connection type, case-insensitively. Empty means SERVICE_NAME.
+func ParseConnectionType(connectionType string) (string, error) {
+	switch strings.ToUpper(strings.TrimSpace(connectionType)) {
+	case "", ConnectionTypeServiceName:
+		return ConnectionTypeServiceName, nil
+	case ConnectionTypeSID:
+		return ConnectionTypeSID, nil
+	default:
+		return "", fmt.Errorf("unknown connection type %q (use %s or %s)", connectionType, ConnectionTypeServiceName, ConnectionTypeSID)
+	}
+}
+
+// ParsePrivilege normalizes an administrative privilege, case-insensitively. Empty means a normal session.
+func ParsePrivilege(privilege string) (string, error) {
+	switch p := strings.ToUpper(strings.TrimSpace(privilege)); p {
+	case "", PrivilegeSYSDBA, PrivilegeSYSDG, PrivilegeSYSBACKUP:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown privilege %q (use %s, %s or %s)", privilege, PrivilegeSYSDBA, PrivilegeSYSDG, PrivilegeSYSBACKUP)
+	}
 }
 
 // Connect establishes a connection to the Oracle database using the provided details.
 // It returns a sql.DB object or an error if the connection fails.
-func Connect(details ConnectionDetails) (*sql.DB, error) {
+// The first round trip is cancelled with ctx or after the query timeout.
+func Connect(ctx context.Context, details ConnectionDetails) (*sql.DB, error) {
+	connectionType, err := ParseConnectionType(details.ConnectionType)
+	if err != nil {
+		return nil, err
+	}
+	protocol, err := ParseProtocol(details.Protocol)
+	if err != nil {
+		return nil, err
+	}
+	privilege, err := ParsePrivilege(details.Privilege)
+	if err != nil {
+		return nil, err
+	}
+
 	// Set connection timeout to 30 seconds
 	urlOptions := map[string]string{
 		"CONNECTION TIMEOUT": "30",
+		"PROGRAM":            ApplicationModule,
+	}
+	if privilege != "" {
+		urlOptions["DBA PRIVILEGE"] = privilege
+	}
+	if details.ProxyClient != "" {
+		urlOptions["PROXY CLIENT NAME"] = details.ProxyClient
 	}
 
-	// 使用 go-ora 的 BuildUrl 构建连接字符串
-	connStr := go_ora.BuildUrl(
-		details.Host,
-		details.Port,
-		details.DBName, // SID or Service Name
-		details.User,
-		details.Password,
-		urlOptions,
-	)
+	// TCPS 连接统一通过描述符建立，协议由每个 ADDRESS 指定
+	descriptor := details.Descriptor
+	if descriptor == "" && protocol == ProtocolTCPS {
+		descriptor = fmt.Sprintf("(DESCRIPTION=(ADDRESS=(PROTOCOL=TCPS)(HOST=%s)(PORT=%d))(CONNECT_DATA=(%s=%s)))",
+			details.Host, details.Port, connectionType, details.DBName)
+	}
+
+	// 使用 go-ora 的 BuildUrl 构建连接字符串；SID 通过 URL 参数传递，服务名为空
+	var connStr string
+	useTLS := false
+	if descriptor != "" {
+		cd, err := parseDescriptor(descriptor)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range cd.Addresses {
+			useTLS = useTLS || addr.Protocol == ProtocolTCPS
+		}
+		// go-ora 依次尝试描述符中的所有地址（RAC SCAN、Data Guard 故障转移）。
+		// BuildUrl splits option values at commas, which a DN in the descriptor contains, so append it escaped.
+		connStr = go_ora.BuildUrl("", 0, "", details.User, details.Password, urlOptions) + "&connStr=" + url.QueryEscape(cd.Descriptor)
+	} else {
+		service := details.DBName
+		if connectionType == ConnectionTypeSID {
+			urlOptions["SID"] = details.DBName
+			service = ""
+		}
+		connStr = go_ora.BuildUrl(
+			details.Host,
+			details.Port,
+			service,
+			details.User,
+			details.Password,
+			urlOptions,
+		)
+	}
 
 	// 使用 sijms/go-ora/v2 驱动打开连接
-	db, err := sql.Open("oracle", connStr)
-	if err != nil {
-		return nil, fmt.Errorf("error opening database connection: %w", err)
+	var db *sql.DB
+	if useTLS {
+		serverDN := details.ServerCertDN
+		if m := descriptorServerDN.FindStringSubmatch(descriptor); serverDN == "" && m != nil {
+			serverDN = m[1]
+		}
+		cfg, err := tlsConfig(details.WalletDir, serverDN)
+		if err != nil {
+			return nil, err
+		}
+		connector := go_ora.NewConnector(connStr).(*go_ora.OracleConnector)
+		connector.WithTLSConfig(cfg)
+		db = sql.OpenDB(connector)
+	} else {
+		db, err = sql.Open("oracle", connStr)
+		if err != nil {
+			return nil, fmt.Errorf("error opening database connection: %w", err)
+		}
 	}
 
-	err = db.Ping()
+	err = ping(ctx, db)
 	if err != nil {
 		db.Close() // Close the connection if ping fails
 		return nil, fmt.Errorf("error pinging database: %w", err)
@@ -58,53 +174,41 @@ func Connect(details ConnectionDetails) (*sql.DB, error) {
 type PrivilegeCheckResult struct {
 	ViewName  string `json:"view_name"`
 	HasAccess bool   `json:"has_access"`
+	Skipped   bool   `json:"skipped,omitempty"` // Dictionary view not checked because the database is only MOUNTED
 	Error     string `json:"error,omitempty"`
 }
 
-// ValidatePrivileges 验证数据库连接是否具有查询关键系统视图的权限
-func ValidatePrivileges(db *sql.DB) ([]PrivilegeCheckResult, error) {
-	// 关键系统视图列表
-	criticalViews := []string{
-		// v$ views
-		"v$active_session_history",
-		"v$asm_diskgroup", // If ASM is used and needs checking
-		"v$database",
-		"v$instance",
-		"v$session",
-		"v$sql",
-		"v$sqlarea",
-		"v$sysmetric",
-		"v$system_parameter",
-		"v$temp_extent_pool",
-		"v$version",
-		// dba_ views
-		"dba_data_files",
-		"dba_free_space",
-		"dba_objects",
-		"dba_roles",
-		"dba_role_privs",
-		"dba_segments",
-		"dba_sys_privs",
-		"dba_tablespaces",
-		"dba_temp_files",
-		"dba_users",
-	}
-
-	results := make([]PrivilegeCheckResult, 0, len(criticalViews))
+// ValidatePrivileges 验证数据库连接是否具有查询指定视图的权限
+// views are the lower-case view names the selected inspection modules query; duplicates are checked once.
+func ValidatePrivileges(ctx context.Context, db *sql.DB, views []string) ([]PrivilegeCheckResult, error) {
+	views = uniqueViews(views)
+	results := make([]PrivilegeCheckResult, 0, len(views))
+
+	// MOUNTED 状态下只能查询固定视图（v$/gv$），数据字典视图跳过检查
+	mounted, err := IsMounted(ctx, db)
+	if err != nil {
+		logger.Debugf("Could not read the instance status: %v", err)
+	}
 
 	// 检查每个视图的查询权限
-	for _, view := range criticalViews {
+	for _, view := range views {
 		result := PrivilegeCheckResult{
 			ViewName:  view,
 			HasAccess: false,
 		}
+		if mounted && !isFixedView(view) {
+			result.Skipped = true
+			result.Error = ErrDictionaryUnavailable.Error()
+			results = append(results, result)
+			continue
+		}
 
 		// 构建查询语句
 		query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE ROWNUM = 1", view)
 
 		// 尝试执行查询
 		var count int
-		err := db.QueryRow(query).Scan(&count)
+		err := queryRow(ctx, db, query, &count)
 
 		if err != nil {
 			// Check the error type, if it's insufficient privileges, log it and continue
@@ -126,24 +230,24 @@ func ValidatePrivileges(db *sql.DB) ([]PrivilegeCheckResult, error) {
 	return results, nil
 }
 
-// CheckDatabaseConnection 验证数据库连接并检查权限
-func CheckDatabaseConnection(db *sql.DB) (bool, []PrivilegeCheckResult, error) {
+// CheckDatabaseConnection 验证数据库连接并检查 views 的查询权限
+func CheckDatabaseConnection(ctx context.Context, db *sql.DB, views []string) (bool, []PrivilegeCheckResult, error) {
 	// 首先验证连接是否有效
-	err := db.Ping()
+	err := ping(ctx, db)
 	if err != nil {
 		return false, nil, fmt.Errorf("database connection failed: %v", err)
 	}
 
 	// 检查权限
-	privilegeResults, err := ValidatePrivileges(db)
+	privilegeResults, err := ValidatePrivileges(ctx, db, views)
 	if err != nil {
 		return true, privilegeResults, fmt.Errorf("permission check failed: %v", err)
 	}
 
-	// 检查是否有任何关键视图没有访问权限
+	// 检查是否有任何视图没有访问权限
 	allAccessGranted := true
 	for _, result := range privilegeResults {
-		if !result.HasAccess {
+		if !result.HasAccess && !result.Skipped {
 			allAccessGranted = false
 			break
 		}
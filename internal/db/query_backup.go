@@ -2,6 +2,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -14,10 +15,10 @@ type ArchivelogModeInfo struct {
 }
 
 // GetArchivelogMode gets the current log mode of the database.
-func GetArchivelogMode(db *sql.DB) (ArchivelogModeInfo, error) {
+func GetArchivelogMode(ctx context.Context, db *sql.DB) (ArchivelogModeInfo, error) {
 	var info ArchivelogModeInfo
 	query := `SELECT LOG_MODE AS LogMode FROM V$DATABASE`
-	err := db.QueryRow(query).Scan(&info.LogMode)
+	err := queryRow(ctx, db, query, &info.LogMode)
 	if err != nil {
 		return info, fmt.Errorf("failed to get database log mode: %w", err)
 	}
@@ -39,7 +40,7 @@ type RMANBackupJobInfo struct {
 }
 
 // GetRecentRMANBackupJobs gets recent RMAN backup jobs (e.g., last 7 days).
-func GetRecentRMANBackupJobs(db *sql.DB) ([]RMANBackupJobInfo, error) {
+func GetRecentRMANBackupJobs(ctx context.Context, db *sql.DB) ([]RMANBackupJobInfo, error) {
 	query := `
 SELECT 
     SESSION_KEY AS SessionKey, 
@@ -56,7 +57,7 @@ WHERE START_TIME >= SYSDATE - 7
 ORDER BY START_TIME DESC`
 
 	var jobs []RMANBackupJobInfo
-	err := ExecuteQueryAndScanToStructs(db, &jobs, query)
+	err := ExecuteQueryAndScanToStructs(ctx, db, &jobs, query)
 	if err != nil {
 		// V$RMAN_BACKUP_JOB_DETAILS 可能不存在或无权限，尝试 V$BACKUP_SET 作为备选
 		logger.Warnf("Failed to query V$RMAN_BACKUP_JOB_DETAILS (%v), trying V$BACKUP_SET", err)
@@ -74,7 +75,7 @@ SELECT
 FROM V$BACKUP_SET 
 WHERE COMPLETION_TIME >= SYSDATE - 7 AND BACKUP_TYPE != 'L' -- Exclude pure archive log backups, focus on data file backups
 ORDER BY COMPLETION_TIME DESC`
-		err = ExecuteQueryAndScanToStructs(db, &jobs, queryBackupSet)
+		err = ExecuteQueryAndScanToStructs(ctx, db, &jobs, queryBackupSet)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get RMAN backup job information (tried V$RMAN_BACKUP_JOB_DETAILS and V$BACKUP_SET): %w", err)
 		}
@@ -92,7 +93,7 @@ type FlashbackStatusInfo struct {
 }
 
 // GetFlashbackStatus gets the status of the flashback database.
-func GetFlashbackStatus(db *sql.DB) (FlashbackStatusInfo, error) {
+func GetFlashbackStatus(ctx context.Context, db *sql.DB) (FlashbackStatusInfo, error) {
 	var info FlashbackStatusInfo
 	query := `
 SELECT 
@@ -104,7 +105,7 @@ FROM V$DATABASE d
 LEFT JOIN V$FLASHBACK_DATABASE_LOG l ON 1=1
 LEFT JOIN V$PARAMETER p ON p.NAME = 'db_flashback_retention_target'`
 
-	err := db.QueryRow(query).Scan(&info.FlashbackOn, &info.OldestFlashbackSCN, &info.OldestFlashbackTime, &info.RetentionTarget)
+	err := queryRow(ctx, db, query, &info.FlashbackOn, &info.OldestFlashbackSCN, &info.OldestFlashbackTime, &info.RetentionTarget)
 	if err != nil && err != sql.ErrNoRows {
 		return info, fmt.Errorf("failed to get flashback database status: %w", err)
 	}
@@ -126,7 +127,7 @@ type RecycleBinObjectInfo struct {
 }
 
 // GetRecycleBinObjects gets objects from the recycle bin (only recoverable ones).
-func GetRecycleBinObjects(db *sql.DB) ([]RecycleBinObjectInfo, error) {
+func GetRecycleBinObjects(ctx context.Context, db *sql.DB) ([]RecycleBinObjectInfo, error) {
 	query := `
 SELECT 
     OWNER AS Owner, 
@@ -143,7 +144,7 @@ WHERE CAN_UNDROP = 'YES' AND TYPE != 'INDEX' -- 排除索引，因为恢复表
 ORDER BY DROPTIME DESC`
 
 	var objects []RecycleBinObjectInfo
-	err := ExecuteQueryAndScanToStructs(db, &objects, query)
+	err := ExecuteQueryAndScanToStructs(ctx, db, &objects, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get recycle bin object information: %w", err)
 	}
@@ -162,7 +163,7 @@ type DataPumpJobInfo struct {
 }
 
 // GetDataPumpJobs gets current or recent Data Pump jobs.
-func GetDataPumpJobs(db *sql.DB) ([]DataPumpJobInfo, error) {
+func GetDataPumpJobs(ctx context.Context, db *sql.DB) ([]DataPumpJobInfo, error) {
 	query := `
 SELECT 
     JOB_NAME AS JobName, 
@@ -175,7 +176,7 @@ FROM DBA_DATAPUMP_JOBS
 ORDER BY OWNER_NAME, JOB_NAME`
 
 	var jobs []DataPumpJobInfo
-	err := ExecuteQueryAndScanToStructs(db, &jobs, query)
+	err := ExecuteQueryAndScanToStructs(ctx, db, &jobs, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get Data Pump job information: %w", err)
 	}
@@ -198,14 +199,20 @@ type AllBackupInfo struct {
 }
 
 // GetAllBackupDetails aggregates all backup-related information.
-func GetAllBackupDetails(db *sql.DB) AllBackupInfo {
+// When mounted is set, the dictionary-based parts report ErrDictionaryUnavailable without querying.
+func GetAllBackupDetails(ctx context.Context, db *sql.DB, mounted bool) AllBackupInfo {
 	var backupInfo AllBackupInfo
 
-	backupInfo.ArchivelogMode, backupInfo.ArchivelogModeError = GetArchivelogMode(db)
-	backupInfo.RMANJobs, backupInfo.RMANJobsError = GetRecentRMANBackupJobs(db)
-	backupInfo.FlashbackStatus, backupInfo.FlashbackStatusError = GetFlashbackStatus(db)
-	backupInfo.RecycleBinItems, backupInfo.RecycleBinError = GetRecycleBinObjects(db)
-	backupInfo.DataPumpJobs, backupInfo.DataPumpJobsError = GetDataPumpJobs(db)
+	backupInfo.ArchivelogMode, backupInfo.ArchivelogModeError = GetArchivelogMode(ctx, db)
+	backupInfo.RMANJobs, backupInfo.RMANJobsError = GetRecentRMANBackupJobs(ctx, db)
+	backupInfo.FlashbackStatus, backupInfo.FlashbackStatusError = GetFlashbackStatus(ctx, db)
+	if mounted {
+		backupInfo.RecycleBinError = ErrDictionaryUnavailable
+		backupInfo.DataPumpJobsError = ErrDictionaryUnavailable
+		return backupInfo
+	}
+	backupInfo.RecycleBinItems, backupInfo.RecycleBinError = GetRecycleBinObjects(ctx, db)
+	backupInfo.DataPumpJobs, backupInfo.DataPumpJobsError = GetDataPumpJobs(ctx, db)
 
 	return backupInfo
 }
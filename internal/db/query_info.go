@@ -1,7 +1,9 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -12,6 +14,10 @@ import (
 	_ "github.com/sijms/go-ora/v2" // Oracle driver
 )
 
+// ErrDictionaryUnavailable is reported instead of querying data dictionary (DBA_*) views while the
+// database is only MOUNTED: until it is opened, only fixed views (V$, GV$) can be read.
+var ErrDictionaryUnavailable = errors.New("database is MOUNTED, not OPEN; data dictionary views are not available")
+
 // InstanceInfo holds information about a specific database instance.
 type InstanceInfo struct {
 	InstanceNumber int    `json:"instance_number" db:"INSTANCE_NUMBER"`
@@ -44,14 +50,52 @@ type DatabaseDetail struct {
 	OverallVersion       string         `json:"overall_version"`                                    // Version string from instance, used for logic
 }
 
+// SessionIdentity is who the inspection session runs as.
+type SessionIdentity struct {
+	User      string `json:"user"`                 // Effective user (SESSION_USER)
+	ProxyUser string `json:"proxy_user,omitempty"` // User that authenticated a proxy session (PROXY_USER)
+}
+
 // FullDBInfo encapsulates all collected database and instance information.
 type FullDBInfo struct {
-	Instances []InstanceInfo `json:"instances"`
-	Database  DatabaseDetail `json:"database"`
+	Instances []InstanceInfo  `json:"instances"`
+	Database  DatabaseDetail  `json:"database"`
+	Session   SessionIdentity `json:"session"`
+}
+
+// GetSessionIdentity reads the effective user and, for proxy sessions, the proxy user of the connection.
+func GetSessionIdentity(ctx context.Context, db *sql.DB) (SessionIdentity, error) {
+	var identity SessionIdentity
+	var proxyUser sql.NullString
+	err := queryRow(ctx, db, "SELECT SYS_CONTEXT('USERENV', 'SESSION_USER'), SYS_CONTEXT('USERENV', 'PROXY_USER') FROM dual", &identity.User, &proxyUser)
+	if err != nil {
+		return identity, fmt.Errorf("failed to read the session user: %w", err)
+	}
+	identity.ProxyUser = proxyUser.String
+	return identity, nil
+}
+
+// Mounted reports whether the instance is started or mounted but the database is not open,
+// e.g. a physical standby without Active Data Guard in MOUNT mode.
+func (f *FullDBInfo) Mounted() bool {
+	return f != nil && len(f.Instances) > 0 && instanceNotOpen(f.Instances[0].Status)
+}
+
+func instanceNotOpen(status string) bool {
+	return status == "MOUNTED" || status == "STARTED"
+}
+
+// IsMounted reads the instance status and reports whether the database is not open yet.
+func IsMounted(ctx context.Context, db *sql.DB) (bool, error) {
+	var status string
+	if err := queryRow(ctx, db, "SELECT status FROM v$instance", &status); err != nil {
+		return false, err
+	}
+	return instanceNotOpen(status), nil
 }
 
 // GetDatabaseInfo retrieves comprehensive information about the Oracle database and its instances.
-func GetDatabaseInfo(db *sql.DB) (*FullDBInfo, error) {
+func GetDatabaseInfo(ctx context.Context, db *sql.DB) (*FullDBInfo, error) {
 	var fullInfo FullDBInfo
 	var firstInstanceVersion string
 
@@ -62,7 +106,7 @@ SELECT instance_number, instance_name, host_name, version,
        status, database_status, instance_role, archiver 
 FROM gv$instance ORDER BY instance_number`
 
-	err := ExecuteQueryAndScanToStructs(db, &fullInfo.Instances, instanceQuery)
+	err := ExecuteQueryAndScanToStructs(ctx, db, &fullInfo.Instances, instanceQuery)
 	if err != nil {
 		return nil, fmt.Errorf("error querying gv$instance using generic scan: %w", err)
 	}
@@ -73,6 +117,11 @@ FROM gv$instance ORDER BY instance_number`
 	firstInstanceVersion = fullInfo.Instances[0].Version
 	fullInfo.Database.OverallVersion = firstInstanceVersion
 
+	// The session identity is informational; the report is still useful without it.
+	if fullInfo.Session, err = GetSessionIdentity(ctx, db); err != nil {
+		logger.Warnf("%v", err)
+	}
+
 	// Determine major version for conditional query
 	majorVersion := 0
 	if len(firstInstanceVersion) > 0 {
@@ -86,7 +135,19 @@ FROM gv$instance ORDER BY instance_number`
 	var dbDetailQuery string
 	// Ensure column aliases in the query match struct field names (case-insensitively)
 	// or are handled by struct tags if ExecuteQueryAndScanToStructs is enhanced to use them.
-	if majorVersion >= 12 {
+	if fullInfo.Mounted() {
+		// nls_database_parameters is a dictionary view; only v$database can be read before OPEN
+		logger.Warnf("Instance %s is %s; character sets are not available until the database is opened.", fullInfo.Instances[0].InstanceName, fullInfo.Instances[0].Status)
+		cdb := "cdb"
+		if majorVersion < 12 {
+			cdb = "'NO' as cdb"
+		}
+		dbDetailQuery = `
+SELECT dbid, name, TO_CHAR(created, 'YYYY-MM-DD HH24:MI:SS') as created, log_mode, open_mode, ` + cdb + `,
+       database_role, protection_mode, force_logging, flashback_on, platform_name,
+       db_unique_name, NULL AS character_set, NULL AS national_character_set
+FROM v$database`
+	} else if majorVersion >= 12 {
 		dbDetailQuery = `
 SELECT dbid, name, TO_CHAR(created, 'YYYY-MM-DD HH24:MI:SS') as created, log_mode, open_mode, cdb, 
        database_role, protection_mode, force_logging, flashback_on, platform_name, 
@@ -105,7 +166,7 @@ FROM v$database`
 	}
 
 	var dbDetails []DatabaseDetail
-	err = ExecuteQueryAndScanToStructs(db, &dbDetails, dbDetailQuery)
+	err = ExecuteQueryAndScanToStructs(ctx, db, &dbDetails, dbDetailQuery)
 	if err != nil {
 		// Log the error but potentially return partial instance info if that's desired behavior
 		logger.Warnf("error querying database details using generic scan: %v. Instance info might be available.", err)
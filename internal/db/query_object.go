@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -42,7 +43,7 @@ type AllObjectInfo struct {
 }
 
 // getObjectOverview gets object overview statistics.
-func getObjectOverview(db *sql.DB) ([]ObjectOverview, error) {
+func getObjectOverview(ctx context.Context, db *sql.DB) ([]ObjectOverview, error) {
 	query := `
 SELECT 
     owner AS Owner, 
@@ -57,7 +58,7 @@ WHERE owner NOT IN ('SYS', 'SYSTEM', 'DBSNMP', 'OUTLN', 'DIP', 'TSMSYS', 'ORACLE
 GROUP BY owner, object_type 
 ORDER BY owner, object_type`
 	var overview []ObjectOverview
-	err := ExecuteQueryAndScanToStructs(db, &overview, query)
+	err := ExecuteQueryAndScanToStructs(ctx, db, &overview, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get object overview: %w", err)
 	}
@@ -67,7 +68,7 @@ ORDER BY owner, object_type`
 }
 
 // getInvalidObjects gets information for all invalid objects.
-func getInvalidObjects(db *sql.DB) ([]InvalidObjectInfo, error) {
+func getInvalidObjects(ctx context.Context, db *sql.DB) ([]InvalidObjectInfo, error) {
 	query := `
 SELECT 
     owner AS Owner, 
@@ -84,7 +85,7 @@ WHERE status = 'INVALID'
   AND owner NOT LIKE 'RDSADMIN%' 
 ORDER BY owner, object_type, object_name`
 	var invalidObjects []InvalidObjectInfo
-	err := ExecuteQueryAndScanToStructs(db, &invalidObjects, query)
+	err := ExecuteQueryAndScanToStructs(ctx, db, &invalidObjects, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get invalid object info: %w", err)
 	}
@@ -93,7 +94,7 @@ ORDER BY owner, object_type, object_name`
 }
 
 // getTopSegments gets information for the top ten largest segments by size.
-func getTopSegments(db *sql.DB) ([]TopSegment, error) {
+func getTopSegments(ctx context.Context, db *sql.DB) ([]TopSegment, error) {
 	// Oracle's ROWNUM is applied *before* ORDER BY in a subquery if not careful.
 	// The subquery correctly orders by size_mb DESC, then the outer query limits to ROWNUM < 11.
 	query := `
@@ -118,7 +119,7 @@ FROM (
 ) 
 WHERE ROWNUM < 11`
 	var segments []TopSegment
-	err := ExecuteQueryAndScanToStructs(db, &segments, query)
+	err := ExecuteQueryAndScanToStructs(ctx, db, &segments, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get top segments info: %w", err)
 	}
@@ -129,21 +130,21 @@ WHERE ROWNUM < 11`
 
 // GetObjectDetails gets all object-related information.
 // It returns AllObjectInfo and the independent error status of each sub-query.
-func GetObjectDetails(db *sql.DB) (allInfo *AllObjectInfo, overviewErr error, topSegmentsErr error, invalidObjectsErr error) {
+func GetObjectDetails(ctx context.Context, db *sql.DB) (allInfo *AllObjectInfo, overviewErr error, topSegmentsErr error, invalidObjectsErr error) {
 	logger.Info("Starting to fetch object module information...")
 	allInfo = &AllObjectInfo{}
 
-	allInfo.Overview, overviewErr = getObjectOverview(db)
+	allInfo.Overview, overviewErr = getObjectOverview(ctx, db)
 	if overviewErr != nil {
 		logger.Warnf("Error getting object overview: %v", overviewErr)
 	}
 
-	allInfo.TopSegments, topSegmentsErr = getTopSegments(db)
+	allInfo.TopSegments, topSegmentsErr = getTopSegments(ctx, db)
 	if topSegmentsErr != nil {
 		logger.Warnf("Error getting top segments info: %v", topSegmentsErr)
 	}
 
-	allInfo.InvalidObjects, invalidObjectsErr = getInvalidObjects(db)
+	allInfo.InvalidObjects, invalidObjectsErr = getInvalidObjects(ctx, db)
 	if invalidObjectsErr != nil {
 		logger.Warnf("Error getting invalid objects info: %v", invalidObjectsErr)
 	}
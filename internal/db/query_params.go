@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 )
@@ -11,11 +12,11 @@ type ParameterInfo struct {
 }
 
 // GetParameterList 查询数据库参数列表
-func GetParameterList(db *sql.DB) ([]ParameterInfo, error) {
+func GetParameterList(ctx context.Context, db *sql.DB) ([]ParameterInfo, error) {
 	query := `SELECT NAME, VALUE FROM V$PARAMETER WHERE ISDEFAULT='FALSE'`
 
 	var result []ParameterInfo
-	err := ExecuteQueryAndScanToStructs(db, &result, query)
+	err := ExecuteQueryAndScanToStructs(ctx, db, &result, query)
 	if err != nil {
 		return nil, fmt.Errorf("GetParameterList failed using ExecuteQueryAndScanToStructs: %w", err)
 	}
@@ -2,6 +2,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/goodwaysIT/inspect4oracle/internal/logger"
@@ -34,7 +35,7 @@ type PerformanceMetricsBundle struct {
 
 // GetSysMetricSummary retrieves data from DBA_HIST_SYSMETRIC_SUMMARY for the last 24 hours
 // for a predefined set of important metrics.
-func GetSysMetricSummary(db *sql.DB) ([]SysMetricSummary, error) {
+func GetSysMetricSummary(ctx context.Context, db *sql.DB) ([]SysMetricSummary, error) {
 	query := `
 	SELECT
 	    BEGIN_TIME,
@@ -72,12 +73,15 @@ func GetSysMetricSummary(db *sql.DB) ([]SysMetricSummary, error) {
 	ORDER BY
 	    METRIC_NAME, BEGIN_TIME`
 
-	rows, err := db.Query(query)
+	qctx, cancel := queryContext(ctx)
+	defer cancel()
+	rows, done, err := queryRows(qctx, db, query)
 	if err != nil {
+		err = queryError(ctx, qctx, query, err)
 		logger.Errorf("Error querying DBA_HIST_SYSMETRIC_SUMMARY: %v", err)
 		return nil, err
 	}
-	defer rows.Close()
+	defer done()
 
 	var metrics []SysMetricSummary
 	var firstScanError error // To store the first error encountered during scanning
@@ -103,6 +107,7 @@ func GetSysMetricSummary(db *sql.DB) ([]SysMetricSummary, error) {
 	}
 
 	if err = rows.Err(); err != nil { // This checks for errors encountered during iteration (e.g., connection issue)
+		err = queryError(ctx, qctx, query, err)
 		logger.Errorf("Error after iterating DBA_HIST_SYSMETRIC_SUMMARY rows: %v", err)
 		// If rows.Err() is not nil, it's usually more critical than a single scan error.
 		// Return this error, potentially masking firstScanError if it was also set.
@@ -120,8 +125,8 @@ func GetSysMetricSummary(db *sql.DB) ([]SysMetricSummary, error) {
 
 // GetAllPerformanceMetrics aggregates all performance related metrics.
 // Currently, it only fetches SysMetricSummary.
-func GetAllPerformanceMetrics(db *sql.DB) PerformanceMetricsBundle {
+func GetAllPerformanceMetrics(ctx context.Context, db *sql.DB) PerformanceMetricsBundle {
 	var bundle PerformanceMetricsBundle
-	bundle.SysMetricsSummary, bundle.SysMetricsError = GetSysMetricSummary(db)
+	bundle.SysMetricsSummary, bundle.SysMetricsError = GetSysMetricSummary(ctx, db)
 	return bundle
 }
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -22,7 +23,7 @@ type NonSystemUserInfo struct {
 }
 
 // GetNonSystemUsers gets information for all non-system users
-func GetNonSystemUsers(db *sql.DB) ([]NonSystemUserInfo, error) {
+func GetNonSystemUsers(ctx context.Context, db *sql.DB) ([]NonSystemUserInfo, error) {
 	query := `
 SELECT 
     USERNAME AS Username, 
@@ -53,7 +54,7 @@ ORDER BY USERNAME`
 
 	var users []NonSystemUserInfo
 	// Assume ExecuteQueryAndScanToStructs can handle sql.NullTime and time.Time
-	err := ExecuteQueryAndScanToStructs(db, &users, query)
+	err := ExecuteQueryAndScanToStructs(ctx, db, &users, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get non-system user info: %w", err)
 	}
@@ -69,7 +70,7 @@ type ProfileInfo struct {
 }
 
 // GetProfiles gets profile configuration information (focusing on password policies and the DEFAULT profile)
-func GetProfiles(db *sql.DB) ([]ProfileInfo, error) {
+func GetProfiles(ctx context.Context, db *sql.DB) ([]ProfileInfo, error) {
 	query := `
 SELECT 
     PROFILE AS Profile, 
@@ -81,7 +82,7 @@ WHERE PROFILE != 'DEFAULT'  -- All settings for all user-defined Profiles
 ORDER BY PROFILE, RESOURCE_NAME`
 
 	var profiles []ProfileInfo
-	err := ExecuteQueryAndScanToStructs(db, &profiles, query)
+	err := ExecuteQueryAndScanToStructs(ctx, db, &profiles, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get profile configuration info: %w", err)
 	}
@@ -96,7 +97,7 @@ type NonSystemRoleInfo struct {
 }
 
 // GetNonSystemRoles gets all roles not maintained by Oracle
-func GetNonSystemRoles(db *sql.DB) ([]NonSystemRoleInfo, error) {
+func GetNonSystemRoles(ctx context.Context, db *sql.DB) ([]NonSystemRoleInfo, error) {
 	query := `
 SELECT ROLE AS RoleName, AUTHENTICATION_TYPE
 FROM DBA_ROLES
@@ -104,7 +105,7 @@ WHERE (ORACLE_MAINTAINED = 'N' OR ORACLE_MAINTAINED IS NULL) -- IS NULL for olde
 ORDER BY ROLE`
 
 	var roles []NonSystemRoleInfo
-	err := ExecuteQueryAndScanToStructs(db, &roles, query)
+	err := ExecuteQueryAndScanToStructs(ctx, db, &roles, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get non-system role list: %w", err)
 	}
@@ -121,7 +122,7 @@ type UserPrivilegedRoleInfo struct {
 }
 
 // GetUsersWithPrivilegedRoles gets information about users who have been granted privileged roles (focusing on non-system users)
-func GetUsersWithPrivilegedRoles(db *sql.DB) ([]UserPrivilegedRoleInfo, error) {
+func GetUsersWithPrivilegedRoles(ctx context.Context, db *sql.DB) ([]UserPrivilegedRoleInfo, error) {
 	query := `
 SELECT drp.GRANTEE, drp.GRANTED_ROLE, drp.ADMIN_OPTION, drp.DEFAULT_ROLE
 FROM DBA_ROLE_PRIVS drp
@@ -131,7 +132,7 @@ WHERE (u.ORACLE_MAINTAINED = 'N' OR u.ORACLE_MAINTAINED IS NULL)
 ORDER BY drp.GRANTEE, drp.GRANTED_ROLE`
 
 	var userRoles []UserPrivilegedRoleInfo
-	err := ExecuteQueryAndScanToStructs(db, &userRoles, query)
+	err := ExecuteQueryAndScanToStructs(ctx, db, &userRoles, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user privileged role info: %w", err)
 	}
@@ -147,7 +148,7 @@ type UserSystemPrivilegeInfo struct {
 }
 
 // GetUsersWithSystemPrivileges gets information about non-system users who have been granted system privileges
-func GetUsersWithSystemPrivileges(db *sql.DB) ([]UserSystemPrivilegeInfo, error) {
+func GetUsersWithSystemPrivileges(ctx context.Context, db *sql.DB) ([]UserSystemPrivilegeInfo, error) {
 	query := `
 SELECT 
     dsp.GRANTEE AS Grantee, 
@@ -159,7 +160,7 @@ WHERE (u.ORACLE_MAINTAINED = 'N' OR u.ORACLE_MAINTAINED IS NULL)
 ORDER BY dsp.GRANTEE, dsp.PRIVILEGE`
 
 	var userSysPrivs []UserSystemPrivilegeInfo
-	err := ExecuteQueryAndScanToStructs(db, &userSysPrivs, query)
+	err := ExecuteQueryAndScanToStructs(ctx, db, &userSysPrivs, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user system privilege info: %w", err)
 	}
@@ -175,7 +176,7 @@ type RoleToRoleGrantInfo struct {
 }
 
 // GetRoleToRoleGrants gets information about roles granted to other roles (mainly focusing on cases where the grantor is a non-system role)
-func GetRoleToRoleGrants(db *sql.DB) ([]RoleToRoleGrantInfo, error) {
+func GetRoleToRoleGrants(ctx context.Context, db *sql.DB) ([]RoleToRoleGrantInfo, error) {
 	query := `
 SELECT rrp.ROLE, rrp.GRANTED_ROLE, rrp.ADMIN_OPTION
 FROM ROLE_ROLE_PRIVS rrp
@@ -186,7 +187,7 @@ WHERE (dr_role.ORACLE_MAINTAINED = 'N' OR dr_role.ORACLE_MAINTAINED IS NULL) --
 ORDER BY rrp.ROLE, rrp.GRANTED_ROLE`
 
 	var roleGrants []RoleToRoleGrantInfo
-	err := ExecuteQueryAndScanToStructs(db, &roleGrants, query)
+	err := ExecuteQueryAndScanToStructs(ctx, db, &roleGrants, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get role-to-role grant info: %w", err)
 	}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -40,8 +41,8 @@ type AllSessionInfo struct {
 	HistoryForChart []SessionHistoryPoint
 }
 
-// getCurrentSessionOverview gets the current session overview
-func getCurrentSessionOverview(db *sql.DB) ([]SessionOverview, error) {
+// getCurrentSessionOverview gets the current session overview, without the tool's own sessions unless configured otherwise
+func getCurrentSessionOverview(ctx context.Context, db *sql.DB) ([]SessionOverview, error) {
 	query := `
 SELECT 
     inst_id AS InstID, 
@@ -50,10 +51,11 @@ SELECT
     status AS Status, 
     count(*) AS SessionCount 
 FROM gv$session 
+WHERE ` + ownSessionsFilter() + `
 GROUP BY inst_id, username, machine, status 
 ORDER BY inst_id, username, machine, status`
 	var overview []SessionOverview
-	err := ExecuteQueryAndScanToStructs(db, &overview, query)
+	err := ExecuteQueryAndScanToStructs(ctx, db, &overview, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current session overview: %w", err)
 	}
@@ -63,16 +65,17 @@ ORDER BY inst_id, username, machine, status`
 }
 
 // getSessionCountByEvent gets the session count grouped by wait event
-func getSessionCountByEvent(db *sql.DB) ([]SessionEventCount, error) {
+func getSessionCountByEvent(ctx context.Context, db *sql.DB) ([]SessionEventCount, error) {
 	query := `
 SELECT 
     event AS Event, 
     count(*) AS SessionCount 
 FROM gv$session 
+WHERE ` + ownSessionsFilter() + `
 GROUP BY event 
 ORDER BY SessionCount DESC, event` // Order by count desc for better readability
 	var byEvent []SessionEventCount
-	err := ExecuteQueryAndScanToStructs(db, &byEvent, query)
+	err := ExecuteQueryAndScanToStructs(ctx, db, &byEvent, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get session count by wait event: %w", err)
 	}
@@ -82,17 +85,18 @@ ORDER BY SessionCount DESC, event` // Order by count desc for better readability
 }
 
 // getDailySessionHistory gets the session history for the last day, for charting
-func getDailySessionHistory(db *sql.DB) ([]SessionHistoryPoint, error) {
+func getDailySessionHistory(ctx context.Context, db *sql.DB) ([]SessionHistoryPoint, error) {
 	query := `
 SELECT 
     to_char(sample_time, 'yyyy-mm-dd hh24:mi') AS SampleTime, 
     count(*) AS SessionCount 
 FROM gv$active_session_history 
 WHERE sample_time > sysdate - INTERVAL '1' DAY 
+  AND ` + ownSessionsFilter() + `
 GROUP BY to_char(sample_time, 'yyyy-mm-dd hh24:mi') 
 ORDER BY SampleTime`
 	var history []SessionHistoryPoint // Ensure history is always initialized
-	err := ExecuteQueryAndScanToStructs(db, &history, query)
+	err := ExecuteQueryAndScanToStructs(ctx, db, &history, query)
 	if err != nil {
 		// Log a warning if ASH is not available or licensed.
 		logger.Warnf("Failed to get session history (ASH) (could be due to ASH not being enabled or license issues): %v", err)
@@ -106,23 +110,23 @@ ORDER BY SampleTime`
 
 // GetSessionDetails gets all session-related information
 // Returns AllSessionInfo and a separate error status for each sub-query
-func GetSessionDetails(db *sql.DB) (allInfo *AllSessionInfo, overviewErr error, eventErr error, historyErr error) {
+func GetSessionDetails(ctx context.Context, db *sql.DB) (allInfo *AllSessionInfo, overviewErr error, eventErr error, historyErr error) {
 	logger.Info("Starting to fetch session module information...")
 	allInfo = &AllSessionInfo{}
 
-	allInfo.Overview, overviewErr = getCurrentSessionOverview(db)
+	allInfo.Overview, overviewErr = getCurrentSessionOverview(ctx, db)
 	if overviewErr != nil {
 		logger.Warnf("Error fetching session overview: %v", overviewErr)
 		// overviewErr is returned directly
 	}
 
-	allInfo.ByEvent, eventErr = getSessionCountByEvent(db)
+	allInfo.ByEvent, eventErr = getSessionCountByEvent(ctx, db)
 	if eventErr != nil {
 		logger.Warnf("Error fetching session count by event: %v", eventErr)
 		// eventErr is returned directly
 	}
 
-	allInfo.HistoryForChart, historyErr = getDailySessionHistory(db)
+	allInfo.HistoryForChart, historyErr = getDailySessionHistory(ctx, db)
 	if historyErr != nil {
 		logger.Warnf("Error fetching session history for chart: %v", historyErr)
 		// historyErr is returned directly. ASH data is often considered optional.
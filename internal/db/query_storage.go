@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strconv"
@@ -82,40 +83,44 @@ type ASMDiskgroupInfo struct {
 // GetStorageInfo fetches all storage-related information
 // dbVersion is used to determine if certain queries are applicable (e.g., ASM-related views are widely used after specific versions)
 // logMode is used to determine whether to query archive logs (e.g., archive logs are irrelevant in NOARCHIVELOG mode)
-func GetStorageInfo(db *sql.DB) (*StorageInfo, error) {
+// When mounted is set, data files and tablespace usage (dictionary views) are skipped.
+func GetStorageInfo(ctx context.Context, db *sql.DB, mounted bool) (*StorageInfo, error) {
 	logger.Info("Starting to fetch storage information...")
 	startTime := time.Now()
 	storageInfo := &StorageInfo{}
 	var err error
 
 	// 1. Control Files
-	storageInfo.ControlFiles, err = getControlFiles(db)
+	storageInfo.ControlFiles, err = getControlFiles(ctx, db)
 	if err != nil {
 		logger.Warnf("Failed to get control file info: %v. Continuing with other storage items.", err)
 		// Do not interrupt, log the error and continue
 	}
 
 	// 2. Redo Logs
-	storageInfo.RedoLogs, err = getRedoLogs(db)
+	storageInfo.RedoLogs, err = getRedoLogs(ctx, db)
 	if err != nil {
 		logger.Warnf("Failed to get redo log info: %v. Continuing with other storage items.", err)
 	}
 
-	// 3. Data Files
-	storageInfo.DataFiles, err = getDataFiles(db)
-	if err != nil {
-		logger.Warnf("Failed to get data file info: %v. Continuing with other storage items.", err)
-	}
+	// 3. Data Files and 4. Tablespace Usage (DBA_* views, not readable before OPEN)
+	if mounted {
+		logger.Info("Database is MOUNTED, skipping data file and tablespace queries.")
+	} else {
+		storageInfo.DataFiles, err = getDataFiles(ctx, db)
+		if err != nil {
+			logger.Warnf("Failed to get data file info: %v. Continuing with other storage items.", err)
+		}
 
-	// 4. Tablespace Usage
-	storageInfo.Tablespaces, err = getTablespaceUsage(db)
-	if err != nil {
-		logger.Warnf("Failed to get tablespace usage: %v. Continuing with other storage items.", err)
+		storageInfo.Tablespaces, err = getTablespaceUsage(ctx, db)
+		if err != nil {
+			logger.Warnf("Failed to get tablespace usage: %v. Continuing with other storage items.", err)
+		}
 	}
 
 	// 5. Archived Log Summary (only meaningful in ARCHIVELOG mode)
 
-	storageInfo.ArchivedLogsSummary, err = getArchivedLogSummary(db)
+	storageInfo.ArchivedLogsSummary, err = getArchivedLogSummary(ctx, db)
 	if err != nil {
 		logger.Warnf("Failed to get archived log summary: %v. Continuing with other storage items.", err)
 	}
@@ -123,11 +128,11 @@ func GetStorageInfo(db *sql.DB) (*StorageInfo, error) {
 	// 6. ASM Diskgroups (usually require specific permissions and only when using ASM)
 	// Simple version check, in practice more complex logic may be needed to determine the ASM environment
 	// For example, check the 'asm instance' parameter or try to query v$asm_diskgroup, and skip if it fails
-	isASM, errAsmCheck := checkASMInstance(db)
+	isASM, errAsmCheck := checkASMInstance(ctx, db)
 	if errAsmCheck != nil {
 		logger.Warnf("Failed to check ASM environment: %v. Skipping ASM diskgroup query.", errAsmCheck)
 	} else if isASM {
-		storageInfo.ASMDiskgroups, err = getASMDiskgroupInfo(db)
+		storageInfo.ASMDiskgroups, err = getASMDiskgroupInfo(ctx, db)
 		if err != nil {
 			logger.Warnf("Failed to get ASM diskgroup info: %v. Continuing with other storage items.", err)
 		}
@@ -139,10 +144,10 @@ func GetStorageInfo(db *sql.DB) (*StorageInfo, error) {
 	return storageInfo, nil // Return the collected information, even if some queries fail
 }
 
-func getControlFiles(db *sql.DB) ([]ControlFileInfo, error) {
+func getControlFiles(ctx context.Context, db *sql.DB) ([]ControlFileInfo, error) {
 	var files []ControlFileInfo
 	query := "SELECT NAME, round(BLOCK_SIZE*FILE_SIZE_BLKS/1024/1024) AS SIZE_MB FROM V$CONTROLFILE"
-	err := ExecuteQueryAndScanToStructs(db, &files, query)
+	err := ExecuteQueryAndScanToStructs(ctx, db, &files, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get control file info (generic scan): %w", err)
 	}
@@ -151,7 +156,7 @@ func getControlFiles(db *sql.DB) ([]ControlFileInfo, error) {
 	return files, nil
 }
 
-func getRedoLogs(db *sql.DB) ([]RedoLogInfo, error) {
+func getRedoLogs(ctx context.Context, db *sql.DB) ([]RedoLogInfo, error) {
 	var logs []RedoLogInfo
 	// Aliased g.GROUP# to GROUP_NO and g.THREAD# to THREAD_NO for struct field matching.
 	// SequenceNo is not in the original query, so it won't be populated.
@@ -168,7 +173,7 @@ SELECT
 FROM V$LOG g JOIN V$LOGFILE l ON g.GROUP# = l.GROUP#
 ORDER BY g.GROUP#, l.MEMBER`
 
-	err := ExecuteQueryAndScanToStructs(db, &logs, query)
+	err := ExecuteQueryAndScanToStructs(ctx, db, &logs, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get redo log info (generic scan): %w", err)
 	}
@@ -177,7 +182,7 @@ ORDER BY g.GROUP#, l.MEMBER`
 	return logs, nil
 }
 
-func getDataFiles(db *sql.DB) ([]DataFileInfo, error) {
+func getDataFiles(ctx context.Context, db *sql.DB) ([]DataFileInfo, error) {
 	query := `
 SELECT
     df.TABLESPACE_NAME AS TABLESPACE_NAME,
@@ -199,7 +204,7 @@ FROM DBA_TEMP_FILES tf
 ORDER BY TABLESPACE_NAME, FILE_ID`
 
 	var files []DataFileInfo
-	err := ExecuteQueryAndScanToStructs(db, &files, query)
+	err := ExecuteQueryAndScanToStructs(ctx, db, &files, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get data file info (generic scan): %w", err)
 	}
@@ -208,7 +213,7 @@ ORDER BY TABLESPACE_NAME, FILE_ID`
 	return files, nil
 }
 
-func getTablespaceUsage(db *sql.DB) ([]TablespaceInfo, error) {
+func getTablespaceUsage(ctx context.Context, db *sql.DB) ([]TablespaceInfo, error) {
 	// 查询普通表空间（永久、UNDO）
 	// Aliased columns for clarity and direct mapping to struct fields
 	queryPermanentUndo := `
@@ -285,7 +290,7 @@ SELECT
 	var errPU, errTemp error
 
 	// 执行永久和UNDO表空间查询
-	errPU = ExecuteQueryAndScanToStructs(db, &permUndoTablespaces, queryPermanentUndo)
+	errPU = ExecuteQueryAndScanToStructs(ctx, db, &permUndoTablespaces, queryPermanentUndo)
 	if errPU != nil {
 		logger.Errorf("Failed to query permanent/UNDO tablespace usage (generic scan): %v", errPU)
 		// Do not return an error immediately, try to query the temporary tablespace
@@ -294,7 +299,7 @@ SELECT
 	}
 
 	// 执行临时表空间查询
-	errTemp = ExecuteQueryAndScanToStructs(db, &tempTablespaces, queryTemporary)
+	errTemp = ExecuteQueryAndScanToStructs(ctx, db, &tempTablespaces, queryTemporary)
 	if errTemp != nil {
 		logger.Errorf("Failed to query temporary tablespace usage (generic scan): %v", errTemp)
 	} else {
@@ -310,7 +315,7 @@ SELECT
 	return tablespaces, nil
 }
 
-func getArchivedLogSummary(db *sql.DB) ([]ArchivedLogSummary, error) {
+func getArchivedLogSummary(ctx context.Context, db *sql.DB) ([]ArchivedLogSummary, error) {
 	// Query the number and size of archived logs per day for the past 7 days.
 	// COMPLETION_TIME is the time when archiving was completed.
 	query := `
@@ -324,7 +329,7 @@ GROUP BY TRUNC(COMPLETION_TIME)
 ORDER BY Day DESC`
 
 	var summaries []ArchivedLogSummary
-	err := ExecuteQueryAndScanToStructs(db, &summaries, query)
+	err := ExecuteQueryAndScanToStructs(ctx, db, &summaries, query)
 	if err != nil {
 		// It could be a view permission issue, or the view is empty in non-archivelog mode but the query itself does not report an error
 		return nil, fmt.Errorf("failed to get archived log summary (generic scan): %w. Please check permissions or confirm the database is in ARCHIVELOG mode.", err)
@@ -335,7 +340,7 @@ ORDER BY Day DESC`
 	return summaries, nil
 }
 
-func checkASMInstance(db *sql.DB) (bool, error) {
+func checkASMInstance(ctx context.Context, db *sql.DB) (bool, error) {
 	var result string
 	// Try to query a parameter or view that typically has a specific value only on an ASM instance.
 	// For example, one could check instance_type, or directly try to query v$asm_diskgroup and catch the error
@@ -348,7 +353,7 @@ func checkASMInstance(db *sql.DB) (bool, error) {
 
 	// Simplified: Try to query V$ASM_DISKGROUP, if successful (even if 0 rows are returned), it is considered an ASM environment or has access rights.
 	// If ORA-00942 is reported, it is considered not to be ASM or to have no permissions.
-	err := db.QueryRow("SELECT COUNT(*) FROM V$ASM_DISKGROUP").Scan(&result)
+	err := queryRow(ctx, db, "SELECT COUNT(*) FROM V$ASM_DISKGROUP", &result)
 	if err != nil {
 		if strings.Contains(err.Error(), "ORA-00942") { // ORA-00942: table or view does not exist
 			logger.Info("V$ASM_DISKGROUP view does not exist or no access permission, assuming non-ASM environment or unable to query ASM information.")
@@ -362,7 +367,7 @@ func checkASMInstance(db *sql.DB) (bool, error) {
 	return true, nil
 }
 
-func getASMDiskgroupInfo(db *sql.DB) ([]ASMDiskgroupInfo, error) {
+func getASMDiskgroupInfo(ctx context.Context, db *sql.DB) ([]ASMDiskgroupInfo, error) {
 	// 注意：查询V$ASM_DISKGROUP通常需要连接到ASM实例，或者通过DB link从数据库实例访问。
 	// The implementation here assumes that the DB connection can already access the V$ASM_DISKGROUP view.
 	// Aliased columns for direct mapping and clarity.
@@ -378,7 +383,7 @@ FROM V$ASM_DISKGROUP
 ORDER BY name`
 
 	var diskgroups []ASMDiskgroupInfo
-	err := ExecuteQueryAndScanToStructs(db, &diskgroups, query)
+	err := ExecuteQueryAndScanToStructs(ctx, db, &diskgroups, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ASM diskgroup info (generic scan): %w. Please confirm the connected user has permission to access this view and the database environment is configured correctly.", err)
 	}
@@ -0,0 +1,221 @@
+package db
+
+import (
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sijms/go-ora/v2/configurations"
+)
+
+// Network protocols of a listener address.
+const (
+	ProtocolTCP  = "TCP"
+	ProtocolTCPS = "TCPS" // SQL*Net over TLS
+)
+
+// descriptorServerDN reads SSL_SERVER_CERT_DN from the SECURITY section of a descriptor.
+var descriptorServerDN = regexp.MustCompile(`(?i)\(\s*SSL_SERVER_CERT_DN\s*=\s*"?([^")]*)"?\s*\)`)
+
+// ParseProtocol normalizes a protocol, case-insensitively. Empty means TCP.
+func ParseProtocol(protocol string) (string, error) {
+	switch strings.ToUpper(strings.TrimSpace(protocol)) {
+	case "", ProtocolTCP:
+		return ProtocolTCP, nil
+	case ProtocolTCPS:
+		return ProtocolTCPS, nil
+	default:
+		return "", fmt.Errorf("unknown protocol %q (use %s or %s)", protocol, ProtocolTCP, ProtocolTCPS)
+	}
+}
+
+// tlsConfig builds the TLS settings for TCPS connections.
+//
+// Trusted certificates and the client certificate come from walletDir: an auto-login wallet
+// (cwallet.sso) and/or PEM files (*.pem, *.crt). Without trusted certificates the system roots are used.
+// When serverDN is set, the server certificate must carry that subject DN, which replaces the
+// host name check, as with SSL_SERVER_DN_MATCH in sqlnet.ora.
+func tlsConfig(walletDir, serverDN string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if walletDir != "" {
+		roots, clientCerts, err := loadWallet(walletDir)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = roots
+		cfg.Certificates = clientCerts
+	}
+	if serverDN == "" {
+		return cfg, nil
+	}
+
+	expected, err := parseDN(serverDN)
+	if err != nil {
+		return nil, err
+	}
+	roots := cfg.RootCAs
+	// The chain is verified below without the host name, which the DN check replaces.
+	cfg.InsecureSkipVerify = true
+	cfg.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("server sent no certificate")
+		}
+		certs := make([]*x509.Certificate, 0, len(rawCerts))
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("invalid server certificate: %w", err)
+			}
+			certs = append(certs, cert)
+		}
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+		if _, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates}); err != nil {
+			return fmt.Errorf("server certificate not trusted: %w", err)
+		}
+		if actual := certs[0].Subject.String(); !sameDN(expected, actual) {
+			return fmt.Errorf("server certificate DN %q does not match the expected %q", actual, serverDN)
+		}
+		return nil
+	}
+	return cfg, nil
+}
+
+// loadWallet reads trusted certificates and client key pairs from a wallet directory.
+func loadWallet(dir string) (*x509.CertPool, []tls.Certificate, error) {
+	var certs [][]byte // DER
+	var keys []crypto.PrivateKey
+
+	sso := filepath.Join(dir, "cwallet.sso")
+	if _, err := os.Stat(sso); err == nil {
+		wallet, err := configurations.NewWallet(sso)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read wallet %s: %w", sso, err)
+		}
+		certs = append(certs, wallet.Certificates...)
+		for _, der := range wallet.PrivateKeys {
+			if key, err := parsePrivateKey(der); err == nil {
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	var pemFiles []string
+	for _, pattern := range []string{"*.pem", "*.crt"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		pemFiles = append(pemFiles, matches...)
+	}
+	sort.Strings(pemFiles)
+	for _, file := range pemFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read wallet file: %w", err)
+		}
+		for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+			switch {
+			case block.Type == "CERTIFICATE":
+				certs = append(certs, block.Bytes)
+			case strings.HasSuffix(block.Type, "PRIVATE KEY") && !strings.Contains(block.Type, "ENCRYPTED"):
+				if key, err := parsePrivateKey(block.Bytes); err == nil {
+					keys = append(keys, key)
+				}
+			}
+		}
+	}
+	if len(certs) == 0 {
+		return nil, nil, fmt.Errorf("wallet directory %s contains neither cwallet.sso nor PEM certificates", dir)
+	}
+
+	roots := x509.NewCertPool()
+	var clientCerts []tls.Certificate
+	for _, der := range certs {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid certificate in wallet %s: %w", dir, err)
+		}
+		roots.AddCert(cert)
+		for _, key := range keys {
+			if pub, ok := key.(interface{ Public() crypto.PublicKey }); ok && publicKeysEqual(pub.Public(), cert.PublicKey) {
+				clientCerts = append(clientCerts, tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert})
+			}
+		}
+	}
+	return roots, clientCerts, nil
+}
+
+func parsePrivateKey(der []byte) (crypto.PrivateKey, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	return x509.ParsePKCS8PrivateKey(der)
+}
+
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	k, ok := a.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && k.Equal(b)
+}
+
+// parseDN splits a DN such as "CN=db.example.com, O=Example, C=US" into normalized
+// "TYPE=value" attributes. Attribute order does not matter when comparing.
+func parseDN(dn string) ([]string, error) {
+	var attrs []string
+	var current strings.Builder
+	escaped := false
+	flush := func() error {
+		attr := strings.TrimSpace(current.String())
+		current.Reset()
+		key, value, ok := strings.Cut(attr, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("invalid server certificate DN %q", dn)
+		}
+		attrs = append(attrs, strings.ToUpper(strings.TrimSpace(key))+"="+strings.TrimSpace(value))
+		return nil
+	}
+	for _, r := range dn {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',' || r == ';':
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	sort.Strings(attrs)
+	return attrs, nil
+}
+
+// sameDN reports whether the certificate subject DN carries exactly the expected attributes.
+func sameDN(expected []string, actual string) bool {
+	attrs, err := parseDN(actual)
+	if err != nil || len(attrs) != len(expected) {
+		return false
+	}
+	for i := range attrs {
+		if !strings.EqualFold(attrs[i], expected[i]) {
+			return false
+		}
+	}
+	return true
+}
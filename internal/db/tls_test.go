@@ -0,0 +1,266 @@
+package db
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCert is a generated certificate with its key.
+type testCert struct {
+	cert *x509.Certificate
+	der  []byte
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCert creates a certificate for subject, signed by issuer or self-signed as a CA when issuer is nil.
+func newTestCert(t *testing.T, subject pkix.Name, issuer *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{subject.CommonName},
+	}
+	parent, signer := template, key
+	if issuer == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		template.ExtKeyUsage, template.DNSNames = nil, nil
+	} else {
+		parent, signer = issuer.cert, issuer.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, der: der, key: key}
+}
+
+// writePEM writes blocks to a file in dir.
+func writePEM(t *testing.T, dir, name string, blocks ...*pem.Block) {
+	t.Helper()
+	var data []byte
+	for _, block := range blocks {
+		data = append(data, pem.EncodeToMemory(block)...)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func certBlock(c *testCert) *pem.Block {
+	return &pem.Block{Type: "CERTIFICATE", Bytes: c.der}
+}
+
+func keyBlock(t *testing.T, c *testCert) *pem.Block {
+	der, err := x509.MarshalPKCS8PrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+}
+
+// serveTLS accepts one TLS connection presenting server and returns the listener address.
+func serveTLS(t *testing.T, server *testCert) string {
+	t.Helper()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{server.der}, PrivateKey: server.key}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.(*tls.Conn).Handshake() // Fails when the client rejects the certificate
+	}()
+	return ln.Addr().String()
+}
+
+func TestTLSConfigServerDN(t *testing.T) {
+	ca := newTestCert(t, pkix.Name{CommonName: "Test Root CA"}, nil)
+	otherCA := newTestCert(t, pkix.Name{CommonName: "Other Root CA"}, nil)
+	wallet := t.TempDir()
+	writePEM(t, wallet, "ca.pem", certBlock(ca))
+
+	tests := []struct {
+		name     string
+		subject  pkix.Name
+		issuer   *testCert
+		serverDN string
+		err      string
+	}{
+		{
+			name:     "trusted chain with matching DN",
+			subject:  pkix.Name{CommonName: "db.example.com", Organization: []string{"Example"}, Country: []string{"US"}},
+			issuer:   ca,
+			serverDN: "c=US, O=Example, CN=db.example.com",
+		},
+		{
+			name:     "wrong DN",
+			subject:  pkix.Name{CommonName: "db.example.com", Organization: []string{"Example"}},
+			issuer:   ca,
+			serverDN: "CN=other.example.com,O=Example",
+			err:      "does not match the expected",
+		},
+		{
+			name:     "missing DN attribute",
+			subject:  pkix.Name{CommonName: "db.example.com", Organization: []string{"Example"}},
+			issuer:   ca,
+			serverDN: "CN=db.example.com",
+			err:      "does not match the expected",
+		},
+		{
+			name:     "untrusted CA",
+			subject:  pkix.Name{CommonName: "db.example.com", Organization: []string{"Example"}},
+			issuer:   otherCA,
+			serverDN: "CN=db.example.com,O=Example",
+			err:      "server certificate not trusted",
+		},
+		{
+			name:     "escaped comma",
+			subject:  pkix.Name{CommonName: "db.example.com", Organization: []string{"Example, Inc."}},
+			issuer:   ca,
+			serverDN: `CN=db.example.com,O=Example\, Inc.`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := serveTLS(t, newTestCert(t, tt.subject, tt.issuer))
+			cfg, err := tlsConfig(wallet, tt.serverDN)
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn, err := tls.Dial("tcp", addr, cfg)
+			if err == nil {
+				conn.Close()
+			}
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("handshake failed: %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("handshake error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestTLSConfigHostName(t *testing.T) {
+	ca := newTestCert(t, pkix.Name{CommonName: "Test Root CA"}, nil)
+	wallet := t.TempDir()
+	writePEM(t, wallet, "ca.crt", certBlock(ca))
+	cfg, err := tlsConfig(wallet, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.InsecureSkipVerify {
+		t.Fatal("host name verification disabled without a server DN")
+	}
+
+	addr := serveTLS(t, newTestCert(t, pkix.Name{CommonName: "db.example.com"}, ca))
+	cfg.ServerName = "db.example.com"
+	conn, err := tls.Dial("tcp", addr, cfg)
+	if err != nil {
+		t.Fatalf("handshake failed: %v", err)
+	}
+	conn.Close()
+
+	addr = serveTLS(t, newTestCert(t, pkix.Name{CommonName: "db.example.com"}, ca))
+	cfg.ServerName = "other.example.com"
+	if conn, err := tls.Dial("tcp", addr, cfg); err == nil {
+		conn.Close()
+		t.Error("handshake succeeded with a mismatched host name")
+	}
+}
+
+func TestLoadWallet(t *testing.T) {
+	ca := newTestCert(t, pkix.Name{CommonName: "Test Root CA"}, nil)
+	client := newTestCert(t, pkix.Name{CommonName: "inspect4oracle"}, ca)
+	other := newTestCert(t, pkix.Name{CommonName: "unrelated"}, ca)
+	wallet := t.TempDir()
+	writePEM(t, wallet, "ca.pem", certBlock(ca))
+	// The key of other is not in the wallet, so it is only trusted, not offered as a client certificate
+	writePEM(t, wallet, "client.pem", certBlock(client), keyBlock(t, client), certBlock(other))
+	writePEM(t, wallet, "notes.txt", certBlock(other))
+
+	roots, clientCerts, err := loadWallet(wallet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.cert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}); err != nil {
+		t.Errorf("wallet CA not trusted: %v", err)
+	}
+	if len(clientCerts) != 1 || clientCerts[0].Leaf.Subject.CommonName != "inspect4oracle" {
+		t.Errorf("client certificates = %+v", clientCerts)
+	}
+
+	if _, _, err := loadWallet(t.TempDir()); err == nil || !strings.Contains(err.Error(), "neither cwallet.sso nor PEM certificates") {
+		t.Errorf("empty wallet error = %v", err)
+	}
+}
+
+func TestParseDN(t *testing.T) {
+	tests := []struct {
+		dn   string
+		want []string
+		err  bool
+	}{
+		{dn: "CN=db.example.com, O=Example, C=US", want: []string{"C=US", "CN=db.example.com", "O=Example"}},
+		{dn: "cn = db.example.com ; o=Example", want: []string{"CN=db.example.com", "O=Example"}},
+		{dn: `CN=db.example.com,O=Example\, Inc.`, want: []string{"CN=db.example.com", "O=Example, Inc."}},
+		{dn: `CN=a\\b`, want: []string{`CN=a\b`}},
+		{dn: "CN=db.example.com,", err: true},
+		{dn: "db.example.com", err: true},
+		{dn: "=Example", err: true},
+	}
+	for _, tt := range tests {
+		got, err := parseDN(tt.dn)
+		if (err != nil) != tt.err || (!tt.err && !reflect.DeepEqual(got, tt.want)) {
+			t.Errorf("parseDN(%q) = %q, %v, want %q", tt.dn, got, err, tt.want)
+		}
+	}
+
+	expected, _ := parseDN("CN=db.example.com, O=Example")
+	for actual, want := range map[string]bool{
+		"O=Example,CN=db.example.com":         true,
+		"CN=DB.EXAMPLE.COM,O=example":         true,
+		"CN=db.example.com":                   false,
+		"CN=db.example.com,O=Example,C=US":    false,
+		"CN=db.example.com,O=Example\\, Inc.": false,
+	} {
+		if got := sameDN(expected, actual); got != want {
+			t.Errorf("sameDN(%q) = %v, want %v", actual, got, want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Package fleet runs inspections over an inventory of databases.
+package fleet
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/handler"
+)
+
+// Inventory is the on-disk description of the databases to inspect in one run.
+//
+// Example:
+//
+//	{
+//	  "defaults": {"items": ["dbinfo", "storage", "backup"], "lang": "en", "thresholdProfile": "prod"},
+//	  "databases": [
+//	    {"name": "crm-prod", "business": "CRM", "host": "10.0.0.5", "port": 1521,
+//	     "service": "CRMPDB", "username": "monitor", "credentials": "env:CRM_PROD_PWD"}
+//	  ]
+//	}
+type Inventory struct {
+	Defaults  Defaults `json:"defaults"`
+	Databases []Entry  `json:"databases"`
+}
+
+// Defaults are applied to every entry that leaves the corresponding field empty.
+type Defaults struct {
+	Items            []string `json:"items"`
+	Lang             string   `json:"lang"`
+	ThresholdProfile string   `json:"thresholdProfile"`
+}
+
+// Entry describes one database in the inventory.
+type Entry struct {
+	Name             string   `json:"name"` // Unique identifier, also used in report file names
+	Business         string   `json:"business"`
+	Host             string   `json:"host"`
+	Port             int      `json:"port"`
+	Service          string   `json:"service"`
+	ConnectionType   string   `json:"connectionType,omitempty"` // "SERVICE_NAME" (default) or "SID"
+	ConnectString    string   `json:"connectString,omitempty"`  // EZConnect, (DESCRIPTION=...) or TNS alias; replaces host, port and service
+	Protocol         string   `json:"protocol,omitempty"`       // "TCP" (default) or "TCPS"
+	WalletDir        string   `json:"walletDir,omitempty"`      // TCPS wallet directory (cwallet.sso and/or PEM files)
+	ServerCertDN     string   `json:"serverCertDn,omitempty"`   // Expected server certificate DN for TCPS
+	Privilege        string   `json:"privilege,omitempty"`      // "SYSDBA", "SYSDG" or "SYSBACKUP"; empty for a normal session
+	ProxyClient      string   `json:"proxyClient,omitempty"`    // Proxy authentication: username logs in, the session runs as this user
+	Username         string   `json:"username"`
+	Credentials      string   `json:"credentials"` // Password reference: "env:VAR" or "file:/path/to/secret"
+	Items            []string `json:"items,omitempty"`
+	Lang             string   `json:"lang,omitempty"`
+	ThresholdProfile string   `json:"thresholdProfile,omitempty"` // Findings threshold profile, e.g. "test"
+}
+
+// LoadInventory reads and validates an inventory file.
+func LoadInventory(path string) (*Inventory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory file %s: %w", path, err)
+	}
+
+	var inv Inventory
+	if err := json.Unmarshal(data, &inv); err != nil {
+		return nil, fmt.Errorf("failed to parse inventory file %s: %w", path, err)
+	}
+	if len(inv.Databases) == 0 {
+		return nil, fmt.Errorf("inventory file %s does not list any databases", path)
+	}
+
+	seen := make(map[string]bool, len(inv.Databases))
+	for i, entry := range inv.Databases {
+		if entry.Name == "" {
+			return nil, fmt.Errorf("inventory entry #%d has no name", i+1)
+		}
+		if seen[entry.Name] {
+			return nil, fmt.Errorf("inventory entry name %q is used more than once", entry.Name)
+		}
+		seen[entry.Name] = true
+	}
+	return &inv, nil
+}
+
+// Request builds the inspection request for the entry, filling gaps from defaults
+// and resolving the credentials reference into a password.
+func (e Entry) Request(defaults Defaults) (*handler.DBConnectionRequest, error) {
+	password, err := ResolveCredentials(e.Credentials)
+	if err != nil {
+		return nil, fmt.Errorf("database %s: %w", e.Name, err)
+	}
+
+	port := e.Port
+	if port == 0 {
+		port = 1521
+	}
+	items := e.Items
+	if len(items) == 0 {
+		items = defaults.Items
+	}
+	lang := e.Lang
+	if lang == "" {
+		lang = defaults.Lang
+	}
+	profile := e.ThresholdProfile
+	if profile == "" {
+		profile = defaults.ThresholdProfile
+	}
+
+	req := &handler.DBConnectionRequest{
+		Business:         e.Business,
+		Host:             e.Host,
+		Port:             strconv.Itoa(port),
+		Service:          e.Service,
+		ConnectionType:   e.ConnectionType,
+		ConnectString:    e.ConnectString,
+		Protocol:         e.Protocol,
+		WalletDir:        e.WalletDir,
+		ServerCertDN:     e.ServerCertDN,
+		Privilege:        e.Privilege,
+		ProxyClient:      e.ProxyClient,
+		Username:         e.Username,
+		Password:         password,
+		Items:            items,
+		Lang:             lang,
+		ThresholdProfile: profile,
+	}
+	if err := handler.ValidateInspectParameters(req); err != nil {
+		return nil, fmt.Errorf("database %s: %w", e.Name, err)
+	}
+	return req, nil
+}
+
+// ResolveCredentials turns a credentials reference into the secret it points to.
+// Supported forms are "env:VAR_NAME" and "file:/path/to/secret" (trailing newlines are trimmed).
+func ResolveCredentials(ref string) (string, error) {
+	scheme, value, ok := strings.Cut(ref, ":")
+	if !ok || value == "" {
+		return "", fmt.Errorf("invalid credentials reference %q (expected env:VAR or file:PATH)", ref)
+	}
+
+	switch scheme {
+	case "env":
+		secret, found := os.LookupEnv(value)
+		if !found {
+			return "", fmt.Errorf("environment variable %s referenced by credentials is not set", value)
+		}
+		return secret, nil
+	case "file":
+		data, err := os.ReadFile(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to read credentials file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	default:
+		return "", fmt.Errorf("unsupported credentials scheme %q (expected env or file)", scheme)
+	}
+}
@@ -0,0 +1,156 @@
+package fleet
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveCredentials(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	t.Setenv("FLEET_TEST_PWD", "s3cret:with:colons")
+	t.Setenv("FLEET_TEST_EMPTY", "")
+
+	tests := []struct {
+		name string
+		ref  string
+		want string
+		err  string
+	}{
+		{name: "environment variable", ref: "env:FLEET_TEST_PWD", want: "s3cret:with:colons"},
+		{name: "empty environment variable", ref: "env:FLEET_TEST_EMPTY", want: ""},
+		{name: "missing environment variable", ref: "env:FLEET_TEST_MISSING", err: "environment variable FLEET_TEST_MISSING referenced by credentials is not set"},
+		{name: "file", ref: "file:" + write("plain", "secret"), want: "secret"},
+		{name: "trailing newline", ref: "file:" + write("newline", "secret\n"), want: "secret"},
+		{name: "trailing CRLF and blank lines", ref: "file:" + write("crlf", "secret\r\n\r\n"), want: "secret"},
+		{name: "other whitespace is kept", ref: "file:" + write("spaces", " secret \t\n"), want: " secret \t"},
+		{name: "unreadable file", ref: "file:" + filepath.Join(dir, "missing"), err: "failed to read credentials file"},
+		{name: "directory", ref: "file:" + dir, err: "failed to read credentials file"},
+		{name: "unknown scheme", ref: "vault:secret/crm", err: `unsupported credentials scheme "vault"`},
+		{name: "scheme is case-sensitive", ref: "ENV:FLEET_TEST_PWD", err: `unsupported credentials scheme "ENV"`},
+		{name: "no scheme", ref: "FLEET_TEST_PWD", err: "invalid credentials reference"},
+		{name: "empty value", ref: "env:", err: "invalid credentials reference"},
+		{name: "empty", ref: "", err: "invalid credentials reference"},
+	}
+	for _, tt := range tests {
+		got, err := ResolveCredentials(tt.ref)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: error = %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s: ResolveCredentials(%q) = %q, %v, want %q", tt.name, tt.ref, got, err, tt.want)
+		}
+	}
+}
+
+func TestLoadInventory(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		names   []string
+		err     string
+	}{
+		{
+			name:    "valid",
+			content: `{"databases": [{"name": "crm-prod"}, {"name": "核心库"}]}`,
+			names:   []string{"crm-prod", "核心库"},
+		},
+		{name: "invalid JSON", content: `{"databases": [`, err: "failed to parse inventory file"},
+		{name: "wrong type", content: `{"databases": {"name": "crm"}}`, err: "failed to parse inventory file"},
+		{name: "no databases", content: `{"defaults": {"lang": "en"}}`, err: "does not list any databases"},
+		{name: "empty list", content: `{"databases": []}`, err: "does not list any databases"},
+		{name: "empty name", content: `{"databases": [{"name": "crm"}, {"host": "db2"}]}`, err: "inventory entry #2 has no name"},
+		{name: "duplicate name", content: `{"databases": [{"name": "crm"}, {"name": "erp"}, {"name": "crm"}]}`, err: `inventory entry name "crm" is used more than once`},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "fleet.json")
+		if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		inv, err := LoadInventory(path)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: error = %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		var names []string
+		for _, entry := range inv.Databases {
+			names = append(names, entry.Name)
+		}
+		if !reflect.DeepEqual(names, tt.names) {
+			t.Errorf("%s: names = %q, want %q", tt.name, names, tt.names)
+		}
+	}
+
+	if _, err := LoadInventory(filepath.Join(t.TempDir(), "missing.json")); err == nil || !strings.Contains(err.Error(), "failed to read inventory file") {
+		t.Errorf("missing file error = %v", err)
+	}
+}
+
+func TestEntryRequestDefaults(t *testing.T) {
+	t.Setenv("FLEET_TEST_PWD", "secret")
+	defaults := Defaults{Items: []string{"dbinfo", "storage"}, Lang: "zh", ThresholdProfile: "default"}
+	base := Entry{Name: "crm", Host: "db1", Service: "CRMPDB", Username: "monitor", Credentials: "env:FLEET_TEST_PWD"}
+
+	tests := []struct {
+		name     string
+		change   func(e *Entry)
+		port     string
+		items    []string
+		lang     string
+		profile  string
+		password string
+		err      string
+	}{
+		{
+			name: "defaults", change: func(e *Entry) {},
+			port: "1521", items: []string{"dbinfo", "storage"}, lang: "zh", profile: "default", password: "secret",
+		},
+		{
+			name: "entry overrides defaults",
+			change: func(e *Entry) {
+				e.Port, e.Items, e.Lang = 1522, []string{"backup"}, "en"
+			},
+			port: "1522", items: []string{"backup"}, lang: "en", profile: "default", password: "secret",
+		},
+		{name: "missing credentials", change: func(e *Entry) { e.Credentials = "env:FLEET_TEST_MISSING" }, err: "database crm: environment variable FLEET_TEST_MISSING"},
+		{name: "unknown threshold profile", change: func(e *Entry) { e.ThresholdProfile = "nightly" }, err: "nightly"},
+		{name: "missing host", change: func(e *Entry) { e.Host = "" }, err: "database crm:"},
+	}
+	for _, tt := range tests {
+		entry := base
+		tt.change(&entry)
+		req, err := entry.Request(defaults)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: error = %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if req.Port != tt.port || !reflect.DeepEqual(req.Items, tt.items) || req.Lang != tt.lang ||
+			req.ThresholdProfile != tt.profile || req.Password != tt.password {
+			t.Errorf("%s: request = %+v", tt.name, req)
+		}
+	}
+}
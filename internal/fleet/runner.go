@@ -0,0 +1,118 @@
+package fleet
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/handler"
+	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+)
+
+// ReportWriter persists the report produced for entry and returns where it was written.
+type ReportWriter func(entry Entry, reportID string, reportData handler.ReportData) (string, error)
+
+// Result is the outcome of inspecting a single inventory entry.
+type Result struct {
+	Name         string `json:"name"`
+	Business     string `json:"business,omitempty"`
+	Success      bool   `json:"success"`
+	ReportID     string `json:"reportId,omitempty"`
+	ReportFile   string `json:"reportFile,omitempty"`
+	ModuleErrors int    `json:"moduleErrors"` // Number of modules that finished with an error
+	Error        string `json:"error,omitempty"`
+	Duration     string `json:"duration"`
+}
+
+// Summary describes a complete fleet run.
+type Summary struct {
+	StartedAt  string   `json:"startedAt"`
+	FinishedAt string   `json:"finishedAt"`
+	Total      int      `json:"total"`
+	Succeeded  int      `json:"succeeded"`
+	Failed     int      `json:"failed"`
+	Results    []Result `json:"results"` // In inventory order
+}
+
+// Run inspects every database in inv with at most concurrency inspections in flight,
+// handing each finished report to write. A failure on one database never stops the others.
+func Run(inv *Inventory, concurrency int, write ReportWriter) Summary {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	summary := Summary{
+		StartedAt: time.Now().Format("2006-01-02 15:04:05"),
+		Total:     len(inv.Databases),
+		Results:   make([]Result, len(inv.Databases)),
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, entry := range inv.Databases {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, entry Entry) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			summary.Results[i] = inspectEntry(entry, inv.Defaults, write)
+		}(i, entry)
+	}
+	wg.Wait()
+
+	for _, result := range summary.Results {
+		if result.Success {
+			summary.Succeeded++
+		} else {
+			summary.Failed++
+		}
+	}
+	summary.FinishedAt = time.Now().Format("2006-01-02 15:04:05")
+	return summary
+}
+
+// inspectEntry runs the inspection pipeline for a single entry and never panics.
+func inspectEntry(entry Entry, defaults Defaults, write ReportWriter) (result Result) {
+	start := time.Now()
+	result = Result{Name: entry.Name, Business: entry.Business}
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("Fleet inspection of %s panicked: %v", entry.Name, r)
+			result.Success = false
+			result.Error = fmt.Sprintf("panic: %v", r)
+		}
+		result.Duration = time.Since(start).Round(time.Millisecond).String()
+	}()
+
+	req, err := entry.Request(defaults)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	logger.Infof("Fleet: inspecting %s (%s:%s/%s)", entry.Name, req.Host, req.Port, req.Service)
+	reportData, reportID, err := handler.RunInspection(context.Background(), req)
+	if err != nil {
+		logger.Errorf("Fleet: inspection of %s failed: %v", entry.Name, err)
+		result.Error = err.Error()
+		handler.NotifyInspectionFailed(handler.NewInspectionFailure(req, err))
+		return result
+	}
+	result.ReportID = reportID
+	for _, module := range reportData.Modules {
+		if module.Error != "" {
+			result.ModuleErrors++
+		}
+	}
+
+	path, err := write(entry, reportID, reportData)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to write report: %v", err)
+		return result
+	}
+	result.ReportFile = path
+	result.Success = true
+	logger.Infof("Fleet: %s finished, report written to %s", entry.Name, path)
+	return result
+}
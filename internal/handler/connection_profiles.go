@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ConnectionProfileStore looks up saved connection profiles, so that requests can name a profile
+// instead of sending a password.
+type ConnectionProfileStore interface {
+	// ConnectionProfile returns the connection settings saved under id, including the password.
+	ConnectionProfile(id string) (*DBConnectionRequest, error)
+}
+
+var (
+	connectionProfiles     ConnectionProfileStore
+	connectionProfilesLock sync.RWMutex
+)
+
+// SetConnectionProfileStore sets the store that resolves the profileId of /api/inspect and /api/validate.
+func SetConnectionProfileStore(store ConnectionProfileStore) {
+	connectionProfilesLock.Lock()
+	connectionProfiles = store
+	connectionProfilesLock.Unlock()
+}
+
+// applyConnectionProfile replaces the connection fields of the request with those of its profile.
+// They are never mixed: a saved password must only ever be sent to the target saved with it.
+// Business and ThresholdProfile are taken from the profile when the request leaves them empty.
+func applyConnectionProfile(req *DBConnectionRequest) error {
+	id := strings.TrimSpace(req.ProfileID)
+	if id == "" {
+		return nil
+	}
+	connectionProfilesLock.RLock()
+	store := connectionProfiles
+	connectionProfilesLock.RUnlock()
+	if store == nil {
+		return fmt.Errorf(langText("未启用连接配置（启动时指定 -profiles）", "Connection profiles are not enabled (start with -profiles)", "接続プロファイルが有効になっていません（-profiles を指定して起動してください）", req.Lang))
+	}
+	profile, err := store.ConnectionProfile(id)
+	if err != nil {
+		return fmt.Errorf(langText("连接配置 %s: %v", "Connection profile %s: %v", "接続プロファイル %s: %v", req.Lang), id, err)
+	}
+
+	req.Host, req.Port, req.Service, req.ConnectionType = profile.Host, profile.Port, profile.Service, profile.ConnectionType
+	req.ConnectString, req.Protocol, req.WalletDir, req.ServerCertDN = profile.ConnectString, profile.Protocol, profile.WalletDir, profile.ServerCertDN
+	req.Privilege, req.ProxyClient = profile.Privilege, profile.ProxyClient
+	req.Username, req.Password = profile.Username, profile.Password
+	if strings.TrimSpace(req.Business) == "" {
+		req.Business = profile.Business
+	}
+	if strings.TrimSpace(req.ThresholdProfile) == "" {
+		req.ThresholdProfile = profile.ThresholdProfile
+	}
+	return nil
+}
@@ -0,0 +1,397 @@
+package handler
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/db"
+)
+
+// Finding severities, from most to least serious.
+const (
+	SeverityCritical = "critical"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+// Finding is a problem detected in the collected data, with advice on how to address it.
+type Finding struct {
+	Severity       string `json:"severity"` // critical, warning or info
+	Message        string `json:"message"`
+	Recommendation string `json:"recommendation,omitempty"`
+}
+
+// findingContext carries what a rule needs besides the module itself.
+type findingContext struct {
+	lang       string
+	fullDBInfo *db.FullDBInfo
+	thresholds FindingThresholds
+}
+
+// findingRule inspects one module and returns the findings it detects.
+type findingRule func(module *ReportModule, ctx findingContext) []Finding
+
+// findingRules maps module IDs to the rules evaluated for them.
+var findingRules = map[string][]findingRule{
+	"dbinfo":   {ruleArchivelogMode},
+	"storage":  {ruleTablespaceUsage, ruleASMUsage},
+	"backup":   {ruleRMANJobs},
+	"security": {ruleUserAccountStatus, rulePasswordExpiry},
+	"objects":  {ruleInvalidObjects},
+}
+
+// evaluateFindings runs the rules registered for the module and stores the result, most severe first.
+func evaluateFindings(module *ReportModule, lang string, fullDBInfo *db.FullDBInfo, thresholds FindingThresholds) {
+	ctx := findingContext{lang: lang, fullDBInfo: fullDBInfo, thresholds: thresholds}
+	var findings []Finding
+	for _, rule := range findingRules[module.ID] {
+		findings = append(findings, rule(module, ctx)...)
+	}
+	sortFindings(findings)
+	module.Findings = findings
+}
+
+// severityRank orders severities for sorting; unknown values sort last.
+func severityRank(severity string) int {
+	switch severity {
+	case SeverityCritical:
+		return 0
+	case SeverityWarning:
+		return 1
+	case SeverityInfo:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func sortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severityRank(findings[i].Severity) < severityRank(findings[j].Severity)
+	})
+}
+
+// findTable returns the module table with the given key, or nil.
+func findTable(module *ReportModule, key string) *ReportTable {
+	for _, t := range module.Tables {
+		if t.Key == key {
+			return t
+		}
+	}
+	return nil
+}
+
+// parseNumber parses values such as "85.23", " 85.23%" or "1,024".
+func parseNumber(s string) (float64, bool) {
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "%"))
+	s = strings.ReplaceAll(s, ",", "")
+	v, err := strconv.ParseFloat(s, 64)
+	return v, err == nil
+}
+
+// ruleArchivelogMode flags databases that cannot be recovered to a point in time.
+func ruleArchivelogMode(module *ReportModule, ctx findingContext) []Finding {
+	if !ctx.thresholds.RequireArchivelog || ctx.fullDBInfo == nil || ctx.fullDBInfo.Database.LogMode != "NOARCHIVELOG" {
+		return nil
+	}
+	lang := ctx.lang
+	return []Finding{{
+		Severity: SeverityCritical,
+		Message:  langText("数据库运行在 NOARCHIVELOG 模式", "The database runs in NOARCHIVELOG mode", "データベースは NOARCHIVELOG モードで稼働しています", lang),
+		Recommendation: langText("生产库应启用归档模式，否则只能恢复到最近一次冷备份。",
+			"Enable ARCHIVELOG mode for production databases; otherwise only a restore to the last cold backup is possible.",
+			"本番データベースでは ARCHIVELOG モードを有効にしてください。無効の場合、最後のコールドバックアップまでしか復旧できません。", lang),
+	}}
+}
+
+// ruleTablespaceUsage flags tablespaces close to full, measured against their maximum (autoextend) size.
+func ruleTablespaceUsage(module *ReportModule, ctx findingContext) []Finding {
+	table := findTable(module, "tablespaces")
+	if table == nil {
+		return nil
+	}
+	lang := ctx.lang
+	var findings []Finding
+	for _, row := range table.Rows {
+		name, contents := cell(row, 1), cell(row, 2)
+		if contents == "TEMPORARY" || contents == "UNDO" {
+			continue // usage of these tablespaces fluctuates by design
+		}
+		pct, ok := tablespaceUsagePct(row)
+		if !ok {
+			continue
+		}
+
+		severity := ""
+		switch {
+		case pct >= ctx.thresholds.TablespaceCritPct:
+			severity = SeverityCritical
+		case pct >= ctx.thresholds.TablespaceWarnPct:
+			severity = SeverityWarning
+		default:
+			continue
+		}
+		findings = append(findings, Finding{
+			Severity: severity,
+			Message:  fmt.Sprintf(langText("表空间 %s 使用率 %.2f%%", "Tablespace %s is %.2f%% full", "表領域 %s の使用率は %.2f%% です", lang), name, pct),
+			Recommendation: langText("添加数据文件、开启自动扩展或清理无用数据。",
+				"Add a datafile, enable autoextend or purge unused data.",
+				"データファイルの追加、自動拡張の有効化、または不要データの削除を行ってください。", lang),
+		})
+	}
+	return findings
+}
+
+// tablespaceUsagePct returns the used share of a tablespace row's maximum size. The maximum is
+// CANEXTEND_SIZE_MB, the autoextend limit (or the file sizes without autoextend), or the current size when
+// that is larger or the column is missing. Rows without sizes fall back to the used % column.
+func tablespaceUsagePct(row []string) (float64, bool) {
+	// Columns: status, name, type, extent mgmt, segment mgmt, used MB, total MB, used %, max size MB
+	used, okUsed := parseNumber(cell(row, 5))
+	total, okTotal := parseNumber(cell(row, 6))
+	if okUsed && okTotal {
+		size := total
+		if maxSize, ok := parseNumber(cell(row, 8)); ok && maxSize > size {
+			size = maxSize
+		}
+		if size > 0 {
+			return used / size * 100, true
+		}
+	}
+	return parseNumber(cell(row, 7))
+}
+
+// ruleASMUsage flags ASM disk groups close to full.
+func ruleASMUsage(module *ReportModule, ctx findingContext) []Finding {
+	table := findTable(module, "asm_diskgroups")
+	if table == nil {
+		return nil
+	}
+	lang := ctx.lang
+	var findings []Finding
+	for _, row := range table.Rows {
+		pct, ok := parseNumber(cell(row, 3))
+		if !ok {
+			continue
+		}
+		severity := ""
+		switch {
+		case pct >= ctx.thresholds.ASMCritPct:
+			severity = SeverityCritical
+		case pct >= ctx.thresholds.ASMWarnPct:
+			severity = SeverityWarning
+		default:
+			continue
+		}
+		findings = append(findings, Finding{
+			Severity: severity,
+			Message:  fmt.Sprintf(langText("ASM 磁盘组 %s 使用率 %.2f%%", "ASM disk group %s is %.2f%% full", "ASM ディスク・グループ %s の使用率は %.2f%% です", lang), cell(row, 0), pct),
+			Recommendation: langText("向磁盘组添加磁盘，或迁移/清理占用空间的文件。",
+				"Add disks to the disk group or move or purge the files using the space.",
+				"ディスク・グループにディスクを追加するか、領域を使用しているファイルを移動・削除してください。", lang),
+		})
+	}
+	return findings
+}
+
+// ruleRMANJobs flags failed backups and the absence of recent backups.
+func ruleRMANJobs(module *ReportModule, ctx findingContext) []Finding {
+	lang := ctx.lang
+	table := findTable(module, "rman_jobs")
+	if table == nil {
+		if module.Error != "" {
+			return nil // the module could not read the backup history at all
+		}
+		// As in backupAgeFinding: an empty history longer than the backup age limit means the limit is exceeded
+		severity := SeverityWarning
+		if ctx.thresholds.MaxBackupAgeDays > 0 && ctx.thresholds.MaxBackupAgeDays < rmanHistoryDays {
+			severity = SeverityCritical
+		}
+		return []Finding{{
+			Severity: severity,
+			Message:  langText("过去 7 天没有 RMAN 备份作业", "No RMAN backup jobs in the last 7 days", "過去 7 日間に RMAN バックアップジョブがありません", lang),
+			Recommendation: langText("确认备份计划是否正常运行，或者备份是否由其他工具完成。",
+				"Check that the backup schedule is running, or that backups are taken by another tool.",
+				"バックアップスケジュールが動作しているか、別のツールでバックアップが取得されているか確認してください。", lang),
+		}}
+	}
+
+	failed, withErrors := 0, 0
+	for _, row := range table.Rows {
+		// Columns: session key, start, end, status, ...
+		status := strings.ToUpper(cell(row, 3))
+		switch {
+		case status == "FAILED":
+			failed++
+		case strings.Contains(status, "WITH ERRORS"), strings.Contains(status, "WITH WARNINGS"):
+			withErrors++
+		}
+	}
+	var findings []Finding
+	if ctx.thresholds.MaxBackupAgeDays > 0 {
+		if f, ok := backupAgeFinding(table, ctx); ok {
+			findings = append(findings, f)
+		}
+	}
+	if failed > 0 {
+		findings = append(findings, Finding{
+			Severity: SeverityCritical,
+			Message:  fmt.Sprintf(langText("%d 个 RMAN 备份作业失败", "%d RMAN backup job(s) FAILED", "%d 件の RMAN バックアップジョブが失敗しました", lang), failed),
+			Recommendation: langText("检查 RMAN 日志中的错误并重新执行备份。",
+				"Review the RMAN logs for the errors and rerun the backup.",
+				"RMAN ログでエラーを確認し、バックアップを再実行してください。", lang),
+		})
+	}
+	if withErrors > 0 {
+		findings = append(findings, Finding{
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf(langText("%d 个 RMAN 备份作业完成但有错误或警告", "%d RMAN backup job(s) completed with errors or warnings", "%d 件の RMAN バックアップジョブがエラーまたは警告付きで完了しました", lang), withErrors),
+			Recommendation: langText("检查 RMAN 日志，确认备份集是否完整可用。",
+				"Review the RMAN logs and confirm the backup sets are complete and usable.",
+				"RMAN ログを確認し、バックアップセットが完全で使用可能か確認してください。", lang),
+		})
+	}
+	return findings
+}
+
+// rmanHistoryDays is how far back the backup module reads the RMAN job history.
+const rmanHistoryDays = 7
+
+// backupAgeFinding checks the end time of the most recent successful RMAN job against MaxBackupAgeDays.
+func backupAgeFinding(table *ReportTable, ctx findingContext) (Finding, bool) {
+	lang := ctx.lang
+	var latest time.Time
+	for _, row := range table.Rows {
+		// Columns: session key, start, end, status, ...
+		if strings.ToUpper(cell(row, 3)) != "COMPLETED" {
+			continue
+		}
+		end, err := time.ParseInLocation("2006-01-02 15:04:05", cell(row, 2), time.Local)
+		if err == nil && end.After(latest) {
+			latest = end
+		}
+	}
+	recommendation := langText("检查备份作业与调度，尽快完成一次成功的备份。",
+		"Check the backup jobs and their schedule, and complete a successful backup as soon as possible.",
+		"バックアップジョブとスケジュールを確認し、できるだけ早く正常なバックアップを取得してください。", lang)
+	if latest.IsZero() {
+		if ctx.thresholds.MaxBackupAgeDays >= rmanHistoryDays {
+			return Finding{}, false // the collected history is too short to tell
+		}
+		return Finding{
+			Severity:       SeverityCritical,
+			Message:        langText("最近的 RMAN 作业中没有成功完成的备份", "None of the recent RMAN jobs completed successfully", "最近の RMAN ジョブに正常完了したバックアップがありません", lang),
+			Recommendation: recommendation,
+		}, true
+	}
+	ageDays := time.Since(latest).Hours() / 24
+	if ageDays <= ctx.thresholds.MaxBackupAgeDays {
+		return Finding{}, false
+	}
+	return Finding{
+		Severity: SeverityCritical,
+		Message: fmt.Sprintf(langText("最近一次成功备份于 %.1f 天前（%s），超过 %.0f 天的限制", "Last successful backup was %.1f days ago (%s), exceeding the %.0f-day limit", "最後の正常バックアップは %.1f 日前（%s）で、%.0f 日の上限を超えています", lang),
+			ageDays, latest.Format("2006-01-02 15:04"), ctx.thresholds.MaxBackupAgeDays),
+		Recommendation: recommendation,
+	}, true
+}
+
+// ruleUserAccountStatus flags accounts in the password grace period or locked by failed logins.
+// Plain EXPIRED and LOCKED accounts are not reported: expiring or locking an account is how unused
+// accounts are retired, and a password that runs out on its own is reported beforehand by
+// rulePasswordExpiry and again while it is in its grace period.
+func ruleUserAccountStatus(module *ReportModule, ctx findingContext) []Finding {
+	table := findTable(module, "users")
+	if table == nil {
+		return nil
+	}
+	lang := ctx.lang
+	var grace, timedLock []string
+	for _, row := range table.Rows {
+		// Columns: username, account status, ...
+		status := strings.ToUpper(cell(row, 1))
+		switch {
+		case strings.Contains(status, "EXPIRED(GRACE)"):
+			grace = append(grace, cell(row, 0))
+		case strings.Contains(status, "LOCKED(TIMED)"):
+			timedLock = append(timedLock, cell(row, 0))
+		}
+	}
+	var findings []Finding
+	if len(grace) > 0 {
+		findings = append(findings, Finding{
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf(langText("用户密码处于宽限期：%s", "Users in the password grace period: %s", "パスワード猶予期間中のユーザー: %s", lang), strings.Join(grace, ", ")),
+			Recommendation: langText("在宽限期结束前修改密码，避免应用连接失败。",
+				"Change the passwords before the grace period ends to avoid application login failures.",
+				"猶予期間が終了する前にパスワードを変更し、アプリケーションのログイン失敗を防いでください。", lang),
+		})
+	}
+	if len(timedLock) > 0 {
+		findings = append(findings, Finding{
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf(langText("用户因登录失败被锁定：%s", "Users locked after failed logins: %s", "ログイン失敗によりロックされたユーザー: %s", lang), strings.Join(timedLock, ", ")),
+			Recommendation: langText("排查错误密码的来源（应用配置或暴力破解），再解锁账户。",
+				"Find the source of the wrong passwords (application configuration or brute force) before unlocking the accounts.",
+				"誤ったパスワードの原因（アプリケーション設定または総当たり攻撃）を調査してからアカウントをロック解除してください。", lang),
+		})
+	}
+	return findings
+}
+
+// ruleInvalidObjects reports invalid objects left behind by deployments.
+func ruleInvalidObjects(module *ReportModule, ctx findingContext) []Finding {
+	table := findTable(module, "invalid_objects")
+	if table == nil || len(table.Rows) == 0 {
+		return nil
+	}
+	lang := ctx.lang
+	severity := SeverityInfo
+	if len(table.Rows) > ctx.thresholds.MaxInvalidObjects {
+		severity = SeverityWarning
+	}
+	return []Finding{{
+		Severity: severity,
+		Message:  fmt.Sprintf(langText("存在 %d 个无效对象", "%d invalid object(s) found", "無効なオブジェクトが %d 件あります", lang), len(table.Rows)),
+		Recommendation: langText("使用 UTL_RECOMP 或 utlrp.sql 重新编译，并检查无法编译的对象。",
+			"Recompile with UTL_RECOMP or utlrp.sql and investigate the objects that still fail to compile.",
+			"UTL_RECOMP または utlrp.sql で再コンパイルし、コンパイルできないオブジェクトを調査してください。", lang),
+	}}
+}
+
+// rulePasswordExpiry warns about open accounts whose password expires soon.
+func rulePasswordExpiry(module *ReportModule, ctx findingContext) []Finding {
+	table := findTable(module, "users")
+	if table == nil || ctx.thresholds.PasswordExpiryWarnDays <= 0 {
+		return nil
+	}
+	lang := ctx.lang
+	now := time.Now()
+	limit := now.AddDate(0, 0, ctx.thresholds.PasswordExpiryWarnDays)
+	var expiring []string
+	for _, row := range table.Rows {
+		// Columns: username, account status, lock time, expiry time, ...
+		if strings.ToUpper(cell(row, 1)) != "OPEN" {
+			continue
+		}
+		expiry, err := time.ParseInLocation("2006-01-02 15:04:05", cell(row, 3), time.Local)
+		if err != nil || expiry.Before(now) || expiry.After(limit) {
+			continue
+		}
+		expiring = append(expiring, fmt.Sprintf("%s (%s)", cell(row, 0), expiry.Format("2006-01-02")))
+	}
+	if len(expiring) == 0 {
+		return nil
+	}
+	return []Finding{{
+		Severity: SeverityWarning,
+		Message: fmt.Sprintf(langText("%d 天内密码将过期的用户：%s", "Passwords expiring within %d days: %s", "%d 日以内にパスワードが期限切れになるユーザー: %s", lang),
+			ctx.thresholds.PasswordExpiryWarnDays, strings.Join(expiring, ", ")),
+		Recommendation: langText("提前修改密码并同步更新应用配置。",
+			"Change the passwords in advance and update the application configuration accordingly.",
+			"事前にパスワードを変更し、アプリケーション設定も更新してください。", lang),
+	}}
+}
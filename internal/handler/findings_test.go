@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// tablespaceRow builds a storage "tablespaces" row; only the columns the rule reads are filled in.
+func tablespaceRow(name, used, total, pct, maxSize string) []string {
+	return []string{"ONLINE", name, "PERMANENT", "LOCAL", "AUTO", used, total, pct, maxSize}
+}
+
+func TestTablespaceUsagePct(t *testing.T) {
+	tests := []struct {
+		name string
+		row  []string
+		want float64
+		ok   bool
+	}{
+		{"fixed size, max equals current size", tablespaceRow("USERS", "950.00", "1000.00", "95.00%", "1000.00"), 95, true},
+		{"autoextend far below its limit", tablespaceRow("DATA", "950.00", "1000.00", "95.00%", "32768.00"), 950.0 / 32768 * 100, true},
+		{"autoextend close to its limit", tablespaceRow("DATA", "31000.00", "31500.00", "98.41%", "32768.00"), 31000.0 / 32768 * 100, true},
+		{"limit below current size", tablespaceRow("MIXED", "900.00", "1000.00", "90.00%", "500.00"), 90, true},
+		{"max size zero", tablespaceRow("USERS", "950.00", "1000.00", "95.00%", "0.00"), 95, true},
+		{"max size column missing", tablespaceRow("USERS", "950.00", "1000.00", "95.00%", "")[:8], 95, true},
+		{"sizes missing, used % only", tablespaceRow("USERS", "", "", "87.50%", ""), 87.5, true},
+		{"nothing parseable", tablespaceRow("USERS", "", "", "", ""), 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := tablespaceUsagePct(tt.row)
+		if ok != tt.ok || math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("%s: tablespaceUsagePct = %.3f, %v; want %.3f, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRuleTablespaceUsage(t *testing.T) {
+	module := &ReportModule{ID: "storage", Tables: []*ReportTable{{
+		Key: "tablespaces",
+		Rows: [][]string{
+			tablespaceRow("USERS", "950.00", "1000.00", "95.00%", "1000.00"),    // fixed size, 95% full
+			tablespaceRow("APP", "880.00", "1000.00", "88.00%", "1000.00"),      // fixed size, 88% full
+			tablespaceRow("DATA", "950.00", "1000.00", "95.00%", "32768.00"),    // autoextend headroom left
+			tablespaceRow("HIST", "31500.00", "32000.00", "98.44%", "32768.00"), // autoextend limit nearly reached
+		},
+	}}}
+	findings := ruleTablespaceUsage(module, findingContext{lang: "en", thresholds: defaultFindingThresholds})
+
+	want := map[string]string{"USERS": SeverityCritical, "APP": SeverityWarning, "HIST": SeverityCritical}
+	if len(findings) != len(want) {
+		t.Fatalf("got %d findings, want %d: %+v", len(findings), len(want), findings)
+	}
+	for _, f := range findings {
+		matched := false
+		for name, severity := range want {
+			if f.Severity == severity && strings.HasPrefix(f.Message, "Tablespace "+name+" ") {
+				matched = true
+			}
+		}
+		if !matched {
+			t.Errorf("unexpected finding %+v", f)
+		}
+	}
+}
+
+func TestRuleRMANJobsWithoutHistory(t *testing.T) {
+	tests := []struct {
+		maxAgeDays float64
+		want       string
+	}{
+		{2, SeverityCritical}, // the default limit is shorter than the 7 days read
+		{6.5, SeverityCritical},
+		{7, SeverityWarning}, // the history is too short to tell
+		{14, SeverityWarning},
+		{0, SeverityWarning}, // no limit
+	}
+	for _, tt := range tests {
+		thresholds := defaultFindingThresholds
+		thresholds.MaxBackupAgeDays = tt.maxAgeDays
+		findings := ruleRMANJobs(&ReportModule{ID: "backup"}, findingContext{lang: "en", thresholds: thresholds})
+		if len(findings) != 1 || findings[0].Severity != tt.want {
+			t.Errorf("MaxBackupAgeDays %.1f: got %+v, want one %s finding", tt.maxAgeDays, findings, tt.want)
+		}
+	}
+
+	// A module that could not read the history reports its error instead
+	if findings := ruleRMANJobs(&ReportModule{ID: "backup", Error: "ORA-00942"}, findingContext{lang: "en", thresholds: defaultFindingThresholds}); len(findings) != 0 {
+		t.Errorf("module error: got %+v, want no findings", findings)
+	}
+}
+
+func TestRuleUserAccountStatus(t *testing.T) {
+	user := func(name, status string) []string {
+		return []string{name, status, "", "", "USERS", "TEMP", "DEFAULT", "2024-01-01 00:00:00", ""}
+	}
+	module := &ReportModule{ID: "security", Tables: []*ReportTable{{
+		Key: "users",
+		Rows: [][]string{
+			user("APP", "OPEN"),
+			user("BATCH", "EXPIRED(GRACE)"),
+			user("WEB", "LOCKED(TIMED)"),
+			user("ETL", "EXPIRED & LOCKED(TIMED)"),
+			user("OLD_APP", "EXPIRED & LOCKED"), // retired accounts are not reported
+			user("ARCHIVE", "LOCKED"),
+			user("TEMP_USER", "EXPIRED"),
+		},
+	}}}
+	findings := ruleUserAccountStatus(module, findingContext{lang: "en", thresholds: defaultFindingThresholds})
+	want := []string{
+		"Users in the password grace period: BATCH",
+		"Users locked after failed logins: WEB, ETL",
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("got %d findings, want %d: %+v", len(findings), len(want), findings)
+	}
+	for i, f := range findings {
+		if f.Severity != SeverityWarning || f.Message != want[i] {
+			t.Errorf("finding %d = %+v, want warning %q", i, f, want[i])
+		}
+	}
+}
@@ -2,15 +2,19 @@ package handler
 
 // ReportData 结构用于模板渲染
 type ReportData struct {
-	DBFullInfo     string          `json:"dbFullInfo,omitempty"` // Full database information, e.g., "ORCL (v19.3.0.0.0) @ dbhost.example.com"
-	Lang           string          // 语言字段: "zh" 或 "en"
-	Title          string          // 报告主标题
-	BusinessName   string          // Business system name entered by the user
-	DBName         string          // Name of the currently inspected database, used for download filenames and report titles
-	DBConnection   string          // Database connection string, format: ip:port/servicename
-	GeneratedAt    string          // 报告生成时间
-	Modules        []ReportModule  // Data for each module included in the report
-	ReportSections []ReportSection // List of modules for the left navigation menu
+	DBFullInfo       string          `json:"dbFullInfo,omitempty"` // Full database information, e.g., "ORCL (v19.3.0.0.0) @ dbhost.example.com"
+	Lang             string          // 语言字段: "zh" 或 "en"
+	Title            string          // 报告主标题
+	BusinessName     string          // Business system name entered by the user
+	DBName           string          // Name of the currently inspected database, used for download filenames and report titles
+	DBConnection     string          // Database connection string, format: ip:port/servicename
+	SessionUser      string          `json:"sessionUser,omitempty"` // Effective database user the inspection ran as
+	ProxyUser        string          `json:"proxyUser,omitempty"`   // User that authenticated the proxy session, if any
+	GeneratedAt      string          // 报告生成时间
+	Modules          []ReportModule  // Data for each module included in the report
+	ReportSections   []ReportSection // List of modules for the left navigation menu
+	ThresholdProfile string          `json:"thresholdProfile,omitempty"` // Threshold profile the findings were evaluated with
+	HealthScore      int             `json:"healthScore"`                // Weighted 0-100 score over all modules
 }
 
 // ReportSection 结构用于定义报告的左侧导航菜单项
@@ -20,12 +24,13 @@ type ReportSection struct {
 }
 
 // ParsedDSN struct stores information parsed from an Oracle connection string
+// (EZConnect, a full connect descriptor or a TNS alias).
 type ParsedDSN struct {
-	User        string
-	Password    string
-	Host        string
-	Port        string
-	SID         string
-	ServiceName string
-	IsValid     bool // Mark if DSN successfully parsed host and port
+	Host        string   // First address, used for display and report IDs
+	Port        string   // Port of the first address
+	SID         string   // Set when the descriptor connects by SID
+	ServiceName string   // Set when the descriptor connects by service name
+	Descriptor  string   // Full (DESCRIPTION=...) handed to the driver
+	Addresses   []string // host:port of every address, in failover order
+	Alias       string   // TNS alias the descriptor was resolved from, if any
 }
@@ -0,0 +1,80 @@
+package handler
+
+// Health scores run from 0 (many serious problems) to 100 (nothing found). A module starts at 100
+// and loses points for each finding and for a module error; the overall score is the weighted
+// average of the module scores.
+
+// scorePenalties is the number of points a single finding of each severity costs its module.
+var scorePenalties = map[string]int{
+	SeverityCritical: 25,
+	SeverityWarning:  10,
+	SeverityInfo:     2,
+}
+
+// moduleErrorPenalty is deducted when a module could not collect all of its data.
+const moduleErrorPenalty = 30
+
+// moduleScoreWeights gives the modules that guard against data loss and outages more say in the overall score.
+// Modules not listed here have weight 1.
+var moduleScoreWeights = map[string]int{
+	"storage":  3,
+	"backup":   3,
+	"security": 2,
+	"dbinfo":   2,
+}
+
+// Health levels used to colour scores in the report and history pages.
+const (
+	HealthGood = "good" // 80 and above
+	HealthFair = "fair" // 60 to 79
+	HealthPoor = "poor" // below 60
+)
+
+// moduleHealthScore computes the score of a module whose findings have already been evaluated.
+func moduleHealthScore(module *ReportModule) int {
+	score := 100
+	if module.Error != "" {
+		score -= moduleErrorPenalty
+	}
+	for _, f := range module.Findings {
+		score -= scorePenalties[f.Severity]
+	}
+	if score < 0 {
+		score = 0
+	}
+	return score
+}
+
+// overallHealthScore is the weighted average of the module scores, rounded to the nearest integer.
+func overallHealthScore(modules []ReportModule) int {
+	total, weights := 0, 0
+	for _, module := range modules {
+		weight, ok := moduleScoreWeights[module.ID]
+		if !ok {
+			weight = 1
+		}
+		total += module.HealthScore * weight
+		weights += weight
+	}
+	if weights == 0 {
+		return 100
+	}
+	return (total + weights/2) / weights
+}
+
+// healthLevel maps a score to HealthGood, HealthFair or HealthPoor.
+func healthLevel(score int) string {
+	switch {
+	case score >= 80:
+		return HealthGood
+	case score >= 60:
+		return HealthFair
+	default:
+		return HealthPoor
+	}
+}
+
+// HealthLevel is the level of the module's score, for use in templates.
+func (m ReportModule) HealthLevel() string {
+	return healthLevel(m.HealthScore)
+}
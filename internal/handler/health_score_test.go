@@ -0,0 +1,89 @@
+package handler
+
+import "testing"
+
+// repeatFinding returns n findings of the given severity.
+func repeatFinding(severity string, n int) []Finding {
+	out := make([]Finding, n)
+	for i := range out {
+		out[i] = Finding{Severity: severity, Message: "finding"}
+	}
+	return out
+}
+
+func TestModuleHealthScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      string
+		findings []Finding
+		want     int
+	}{
+		{name: "nothing found", want: 100},
+		{name: "one of each severity", findings: append(append(repeatFinding(SeverityCritical, 1), repeatFinding(SeverityWarning, 1)...), repeatFinding(SeverityInfo, 1)...), want: 63},
+		{name: "module error", err: "ORA-00942: table or view does not exist", want: 70},
+		{name: "module error and findings", err: "timeout", findings: repeatFinding(SeverityWarning, 2), want: 50},
+		{name: "unknown severity costs nothing", findings: []Finding{{Severity: "notice"}}, want: 100},
+		{name: "exactly zero", findings: repeatFinding(SeverityCritical, 4), want: 0},
+		{name: "clamped at zero", err: "timeout", findings: repeatFinding(SeverityCritical, 5), want: 0},
+	}
+	for _, tt := range tests {
+		module := &ReportModule{ID: "storage", Error: tt.err, Findings: tt.findings}
+		if got := moduleHealthScore(module); got != tt.want {
+			t.Errorf("%s: score = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOverallHealthScore(t *testing.T) {
+	module := func(id string, score int) ReportModule {
+		return ReportModule{ID: id, HealthScore: score}
+	}
+	tests := []struct {
+		name    string
+		modules []ReportModule
+		want    int
+	}{
+		{name: "no modules", want: 100},
+		{name: "all healthy", modules: []ReportModule{module("storage", 100), module("sessions", 100)}, want: 100},
+		{name: "all at zero", modules: []ReportModule{module("backup", 0), module("objects", 0)}, want: 0},
+		// (0*3 + 100*1) / 4
+		{name: "storage counts three times", modules: []ReportModule{module("storage", 0), module("sessions", 100)}, want: 25},
+		// (0*1 + 100*3) / 4
+		{name: "unweighted module counts once", modules: []ReportModule{module("sessions", 0), module("backup", 100)}, want: 75},
+		// (70*2 + 100*2 + 100*3 + 100*3 + 100*1) / 11 = 94.5
+		{name: "module error in security rounds up", modules: []ReportModule{module("security", 70), module("dbinfo", 100), module("storage", 100), module("backup", 100), module("params", 100)}, want: 95},
+		// (90*3 + 75*2 + 80*1) / 6 = 83.33
+		{name: "rounds down", modules: []ReportModule{module("backup", 90), module("dbinfo", 75), module("performance", 80)}, want: 83},
+	}
+	for _, tt := range tests {
+		if got := overallHealthScore(tt.modules); got != tt.want {
+			t.Errorf("%s: score = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	// A module error is scored through its module
+	failed := ReportModule{ID: "backup", Error: "ORA-01031: insufficient privileges"}
+	failed.HealthScore = moduleHealthScore(&failed)
+	if got := overallHealthScore([]ReportModule{failed, module("sessions", 100)}); got != 78 {
+		t.Errorf("backup module error: score = %d, want 78", got)
+	}
+}
+
+func TestHealthLevel(t *testing.T) {
+	tests := []struct {
+		score int
+		want  string
+	}{
+		{100, HealthGood},
+		{80, HealthGood},
+		{79, HealthFair},
+		{60, HealthFair},
+		{59, HealthPoor},
+		{0, HealthPoor},
+	}
+	for _, tt := range tests {
+		if got := healthLevel(tt.score); got != tt.want {
+			t.Errorf("healthLevel(%d) = %q, want %q", tt.score, got, tt.want)
+		}
+	}
+}
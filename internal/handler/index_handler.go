@@ -48,10 +48,8 @@ func ReportHandler(content embed.FS) http.HandlerFunc {
 			return
 		}
 
-		// Get report data from reportStore
-		reportStoreMutex.RLock()
-		reportData, exists := reportStore[reportId]
-		reportStoreMutex.RUnlock()
+		// Get report data from the report repository
+		reportData, exists := loadReport(reportId)
 
 		if !exists {
 			http.NotFound(w, r)
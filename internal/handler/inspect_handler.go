@@ -1,31 +1,24 @@
 package handler
 
 import (
-	"crypto/rand"
+	"bytes"
+	"context"
 	"crypto/sha256"
 	"database/sql"
 	"embed"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"html/template"
 	"log"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/goodwaysIT/inspect4oracle/internal/db"
 	"github.com/goodwaysIT/inspect4oracle/internal/logger"
 )
 
-// reportStore is used to store generated reports
-var (
-	reportStore      = make(map[string]ReportData)
-	reportStoreMutex sync.RWMutex
-)
-
 // GetReportStatusHandler handles API requests to get report status, returns JSON.
 func GetReportStatusHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -37,9 +30,7 @@ func GetReportStatusHandler() http.HandlerFunc {
 			return
 		}
 
-		reportStoreMutex.RLock()
-		reportData, exists := reportStore[reportId]
-		reportStoreMutex.RUnlock()
+		reportData, exists := loadReport(reportId)
 
 		if !exists {
 			logger.Error(fmt.Sprintf("API Error: Report ID %s not found for /api/report/status", reportId))
@@ -58,6 +49,7 @@ func GetReportStatusHandler() http.HandlerFunc {
 			"modules":        reportData.Modules,
 			"title":          reportData.Title,
 			"reportSections": reportData.ReportSections,
+			"healthScore":    reportData.HealthScore,
 		}
 		if err := json.NewEncoder(w).Encode(responsePayload); err != nil {
 			logger.Error(fmt.Sprintf("API Error: Failed to encode report status for ID %s: %v", reportId, err))
@@ -77,67 +69,61 @@ func ViewReportHandler(content embed.FS) http.HandlerFunc {
 			return
 		}
 
-		reportStoreMutex.RLock()
-		reportData, exists := reportStore[reportId]
-		reportStoreMutex.RUnlock()
+		reportData, exists := loadReport(reportId)
 
 		if !exists {
 			http.NotFound(w, r)
 			return
 		}
 
-		currentYear := time.Now().Format("2006")
-		nonceBytes := make([]byte, 16)
-		_, err := rand.Read(nonceBytes)
-		if err != nil {
-			log.Printf("Warning: Failed to generate CSP Nonce: %v", err)
-		}
-		cspNonce := base64.RawURLEncoding.EncodeToString(nonceBytes)
-		if err != nil {
-			cspNonce = ""
-		}
-
-		templateData := map[string]interface{}{
-			"DbInfo":         reportData.BusinessName, // Use business name
-			"ActualDBName":   reportData.DBName,       // Add this if you need to display the actual database name elsewhere in the template
-			"DbConnection":   reportData.DBConnection,
-			"GeneratedAt":    reportData.GeneratedAt,
-			"Modules":        reportData.Modules,
-			"Title":          reportData.Title,
-			"CopyrightYear":  currentYear,
-			"ReportSections": reportData.ReportSections,
-			"CSPNonce":       cspNonce,
-		}
-
-		tmpl, err := template.New("layout").Funcs(template.FuncMap{
-			"safeJS": func(s interface{}) template.JS {
-				return template.JS(fmt.Sprint(s))
-			},
-		}).ParseFS(content, "templates/layout.html", "templates/report.html")
-		if err != nil {
-			http.Error(w, "无法加载报告模板: "+err.Error(), http.StatusInternalServerError)
+		var buf bytes.Buffer
+		if err := RenderReportHTML(&buf, content, reportData); err != nil {
+			http.Error(w, "无法渲染报告: "+err.Error(), http.StatusInternalServerError)
+			log.Printf("CRITICAL: Template execution error (ViewReportHandler): %v.", err)
 			return
 		}
 
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		if err := tmpl.ExecuteTemplate(w, "layout", templateData); err != nil {
-			http.Error(w, "无法执行模板: "+err.Error(), http.StatusInternalServerError)
-			log.Printf("CRITICAL: Template execution error (ViewReportHandler): %v.", err)
-			return
+		if _, err := buf.WriteTo(w); err != nil {
+			logger.Errorf("Failed to write report page for ID %s: %v", reportId, err)
 		}
 	}
 }
 
 // DBConnectionRequest defines the database connection request structure
 type DBConnectionRequest struct {
-	Business string   `json:"business"`
-	Host     string   `json:"host"`
-	Port     string   `json:"port"`
-	Service  string   `json:"service"`
-	Username string   `json:"username"`
-	Password string   `json:"password"`
-	Items    []string `json:"items"`
-	Lang     string   `json:"lang"`
+	Business string `json:"business"`
+	Host     string `json:"host"`
+	Port     string `json:"port"`
+	Service  string `json:"service"`
+	// ConnectionType tells whether Service is a SERVICE_NAME (the default) or a SID.
+	ConnectionType string `json:"connectionType,omitempty"`
+	// ConnectString is an EZConnect string, a full (DESCRIPTION=...) descriptor or a TNS alias.
+	// When set it replaces Host, Port, Service and ConnectionType, which are filled in from it.
+	ConnectString string `json:"connectString,omitempty"`
+	// Protocol is TCP (the default) or TCPS for Host and Port; a ConnectString names its own protocol.
+	Protocol string `json:"protocol,omitempty"`
+	// WalletDir holds the TCPS trust store and client certificate: cwallet.sso and/or PEM files on the server.
+	WalletDir string `json:"walletDir,omitempty"`
+	// ServerCertDN is the DN the database server certificate must carry (SSL_SERVER_CERT_DN).
+	ServerCertDN string `json:"serverCertDn,omitempty"`
+	// Privilege connects AS SYSDBA, SYSDG or SYSBACKUP, e.g. for mounted standbys; empty is a normal session.
+	Privilege string `json:"privilege,omitempty"`
+	// ProxyClient is the user a proxy session runs as; Username and Password authenticate the proxy user.
+	// A Username of the form "proxy_user[client_user]" sets it as well.
+	ProxyClient string `json:"proxyClient,omitempty"`
+	// ProfileID names a saved connection profile; its connection fields and password replace those of the request.
+	ProfileID string   `json:"profileId,omitempty"`
+	Username  string   `json:"username"`
+	Password  string   `json:"password"`
+	Items     []string `json:"items"`
+	Lang      string   `json:"lang"`
+	// ThresholdProfile selects the findings thresholds (e.g. "prod" or "test"); empty uses the default profile.
+	ThresholdProfile string `json:"thresholdProfile,omitempty"`
+	// ReportID fixes the ID of the report, e.g. for scheduled runs; empty generates one.
+	ReportID string `json:"-"`
+
+	dsn *ParsedDSN // Resolved ConnectString, set by ValidateConnectionParameters
 }
 
 // parseInspectRequest parses parameters from the inspection request.
@@ -167,10 +153,19 @@ func parseInspectRequest(r *http.Request) (*DBConnectionRequest, error) {
 		req.Host = r.FormValue("host")
 		req.Port = r.FormValue("port")
 		req.Service = r.FormValue("service")
+		req.ConnectionType = r.FormValue("connectionType")
+		req.ConnectString = r.FormValue("connectString")
+		req.Protocol = r.FormValue("protocol")
+		req.WalletDir = r.FormValue("walletDir")
+		req.ServerCertDN = r.FormValue("serverCertDn")
+		req.Privilege = r.FormValue("privilege")
+		req.ProxyClient = r.FormValue("proxyClient")
+		req.ProfileID = r.FormValue("profileId")
 		req.Username = r.FormValue("username")
 		req.Password = r.FormValue("password")
 		req.Lang = r.FormValue("lang")
 		req.Business = r.FormValue("business")
+		req.ThresholdProfile = r.FormValue("thresholdProfile")
 
 		// Handle the 'items' parameter, which can appear in two forms:
 		// 1. items=item1,item2,item3 (single comma-separated string)
@@ -205,14 +200,119 @@ func parseInspectRequest(r *http.Request) (*DBConnectionRequest, error) {
 	return &req, nil
 }
 
-// validateInspectParameters validates the inspection request parameters
-func validateInspectParameters(req *DBConnectionRequest) error {
+// ValidateConnectionParameters checks the connection fields shared by /api/validate and /api/inspect,
+// and normalizes ConnectionType, Protocol and Privilege. A ProfileID replaces every connection field with
+// the saved one (Business and ThresholdProfile only when empty), and a ConnectString is resolved and
+// fills in Host, Port and Service.
+func ValidateConnectionParameters(req *DBConnectionRequest) error {
+	req.dsn = nil
+	if err := applyConnectionProfile(req); err != nil {
+		return err
+	}
+	if strings.TrimSpace(req.ConnectString) != "" {
+		dsn, err := parseConnectString(req.ConnectString)
+		if err != nil {
+			return fmt.Errorf(langText("无效的连接串: %v", "Invalid connect string: %v", "無効な接続文字列: %v", req.Lang), err)
+		}
+		req.dsn = dsn
+		req.Host, req.Port = dsn.Host, dsn.Port
+		req.Service, req.ConnectionType = dsn.ServiceName, db.ConnectionTypeServiceName
+		if dsn.ServiceName == "" {
+			req.Service, req.ConnectionType = dsn.SID, db.ConnectionTypeSID
+		}
+	}
+	if proxyUser, client := db.SplitProxyUser(req.Username); client != "" {
+		if req.ProxyClient != "" && !strings.EqualFold(req.ProxyClient, client) {
+			return fmt.Errorf(langText("用户名中的代理客户端 %s 与代理客户端参数 %s 不一致", "Proxy client %s in the username conflicts with the proxy client %s", "ユーザー名のプロキシクライアント %s がプロキシクライアント %s と一致しません", req.Lang), client, req.ProxyClient)
+		}
+		req.Username, req.ProxyClient = proxyUser, client
+	}
+	req.ProxyClient = strings.TrimSpace(req.ProxyClient)
 	if req.Host == "" || req.Port == "" || req.Service == "" || req.Username == "" {
 		return fmt.Errorf(langText("主机、端口、服务名和用户名不能为空", "Host, port, service name and username cannot be empty", "ホスト、ポート、サービス名、ユーザー名は空にできません", req.Lang))
 	}
+	if port, err := strconv.Atoi(req.Port); err != nil || port <= 0 || port > 65535 {
+		return fmt.Errorf(langText("无效的端口号: %s", "Invalid port number: %s", "無効なポート番号: %s", req.Lang), req.Port)
+	}
+	connectionType, err := db.ParseConnectionType(req.ConnectionType)
+	if err != nil {
+		return fmt.Errorf(langText("无效的连接类型: %s（应为 SERVICE_NAME 或 SID）", "Invalid connection type: %s (use SERVICE_NAME or SID)", "無効な接続タイプ: %s（SERVICE_NAME または SID を指定してください）", req.Lang), req.ConnectionType)
+	}
+	req.ConnectionType = connectionType
+	protocol, err := db.ParseProtocol(req.Protocol)
+	if err != nil {
+		return fmt.Errorf(langText("无效的协议: %s（应为 TCP 或 TCPS）", "Invalid protocol: %s (use TCP or TCPS)", "無効なプロトコル: %s（TCP または TCPS を指定してください）", req.Lang), req.Protocol)
+	}
+	req.Protocol = protocol
+	privilege, err := db.ParsePrivilege(req.Privilege)
+	if err != nil {
+		return fmt.Errorf(langText("无效的连接权限: %s（应为 SYSDBA、SYSDG 或 SYSBACKUP）", "Invalid connection privilege: %s (use SYSDBA, SYSDG or SYSBACKUP)", "無効な接続権限: %s（SYSDBA、SYSDG または SYSBACKUP を指定してください）", req.Lang), req.Privilege)
+	}
+	req.Privilege = privilege
+	return nil
+}
+
+// parseConnectString resolves an EZConnect string, a connect descriptor or a TNS alias.
+func parseConnectString(connectString string) (*ParsedDSN, error) {
+	cd, err := db.ParseConnectString(connectString)
+	if err != nil {
+		return nil, err
+	}
+	dsn := &ParsedDSN{
+		Host:        cd.Addresses[0].Host,
+		Port:        strconv.Itoa(cd.Addresses[0].Port),
+		SID:         cd.SID,
+		ServiceName: cd.ServiceName,
+		Descriptor:  cd.Descriptor,
+		Alias:       cd.Alias,
+	}
+	for _, addr := range cd.Addresses {
+		dsn.Addresses = append(dsn.Addresses, net.JoinHostPort(addr.Host, strconv.Itoa(addr.Port)))
+	}
+	return dsn, nil
+}
+
+// connectionDetails returns the driver settings for the validated request.
+func (req *DBConnectionRequest) connectionDetails() db.ConnectionDetails {
+	port, _ := strconv.Atoi(req.Port) // Checked by ValidateConnectionParameters
+	details := db.ConnectionDetails{
+		User:           req.Username,
+		Password:       req.Password,
+		Host:           req.Host,
+		Port:           port,
+		DBName:         req.Service,
+		ConnectionType: req.ConnectionType,
+		Protocol:       req.Protocol,
+		WalletDir:      req.WalletDir,
+		ServerCertDN:   req.ServerCertDN,
+		Privilege:      req.Privilege,
+		ProxyClient:    req.ProxyClient,
+	}
+	if req.dsn != nil {
+		details.Descriptor = req.dsn.Descriptor
+	}
+	return details
+}
+
+// connectionString describes the target as host:port/service, or host:port:SID for SID connections.
+func (req *DBConnectionRequest) connectionString() string {
+	if req.ConnectionType == db.ConnectionTypeSID {
+		return fmt.Sprintf("%s:%s:%s", req.Host, req.Port, req.Service)
+	}
+	return fmt.Sprintf("%s:%s/%s", req.Host, req.Port, req.Service)
+}
+
+// ValidateInspectParameters validates the inspection request parameters
+func ValidateInspectParameters(req *DBConnectionRequest) error {
+	if err := ValidateConnectionParameters(req); err != nil {
+		return err
+	}
 	if len(req.Items) == 0 {
 		return fmt.Errorf(langText("巡检项不能为空", "Inspection items cannot be empty", "検査項目は空にできません", req.Lang))
 	}
+	if _, _, err := resolveThresholdProfile(req.ThresholdProfile); err != nil {
+		return fmt.Errorf(langText("未知的阈值配置: %s", "Unknown threshold profile: %s", "不明なしきい値プロファイル: %s", req.Lang), req.ThresholdProfile)
+	}
 	// More validation logic can be added here, e.g., port number format.
 	return nil
 }
@@ -227,10 +327,10 @@ func handleRequestValidation(r *http.Request) (*DBConnectionRequest, error) {
 		return nil, fmt.Errorf(langText("解析巡检请求失败: %w", "failed to parse inspect request: %w", "検査リクエストの解析に失敗しました: %w", req.Lang), err)
 	}
 
-	logger.Infof("Parsed inspection request: Business='%s', Host='%s', Port='%s', Service='%s', Username='%s', ItemsCount=%d, Lang='%s'",
-			req.Business, req.Host, req.Port, req.Service, req.Username, len(req.Items), req.Lang)
+	logger.Infof("Parsed inspection request: Business='%s', Host='%s', Port='%s', Service='%s', ConnectionType='%s', Username='%s', ItemsCount=%d, Lang='%s'",
+			req.Business, req.Host, req.Port, req.Service, req.ConnectionType, req.Username, len(req.Items), req.Lang)
 
-	if err := validateInspectParameters(req); err != nil {
+	if err := ValidateInspectParameters(req); err != nil {
 		return nil, fmt.Errorf("invalid parameters for request (Business='%s', Host='%s', Port='%s', Service='%s', Username='%s', ItemsCount=%d, Lang='%s'): %w",
 			req.Business, req.Host, req.Port, req.Service, req.Username, len(req.Items), req.Lang, err)
 	}
@@ -238,37 +338,42 @@ func handleRequestValidation(r *http.Request) (*DBConnectionRequest, error) {
 }
 
 // establishDBConnection establishes a database connection and retrieves basic information
-func establishDBConnection(req *DBConnectionRequest) (*sql.DB, *db.FullDBInfo, error) {
-	portInt, convErr := strconv.Atoi(req.Port)
-	if convErr != nil {
+func establishDBConnection(ctx context.Context, req *DBConnectionRequest) (*sql.DB, *db.FullDBInfo, error) {
+	if _, convErr := strconv.Atoi(req.Port); convErr != nil {
 		return nil, nil, fmt.Errorf(langText("无效的端口号 '%s': %w", "invalid port number '%s': %w", "無効なポート番号 '%s': %w", req.Lang), req.Port, convErr)
 	}
+	if req.dsn != nil {
+		logger.Infof("Connecting with connect descriptor (alias '%s'), addresses in order: %s", req.dsn.Alias, strings.Join(req.dsn.Addresses, ", "))
+	}
 
-	dbConn, err := db.Connect(db.ConnectionDetails{
-		User:           req.Username,
-		Password:       req.Password, // 注意：这里仍然使用了 req.Password，实际应用中应考虑安全性
-		Host:           req.Host,
-		Port:           portInt,
-		DBName:         req.Service,
-		ConnectionType: "SERVICE_NAME",
-	})
+	if req.Privilege != "" {
+		logger.Infof("Connecting as %s AS %s", req.Username, req.Privilege)
+	}
+	if req.ProxyClient != "" {
+		logger.Infof("Connecting through proxy user %s as %s", req.Username, req.ProxyClient)
+	}
+
+	dbConn, err := db.Connect(db.WithAction(ctx, "connect"), req.connectionDetails())
 	if err != nil {
 		return nil, nil, fmt.Errorf(langText("连接数据库失败: %w", "failed to connect to database: %w", "データベースへの接続に失敗しました: %w", req.Lang), err)
 	}
 
-	fullDBInfo, err := db.GetDatabaseInfo(dbConn)
+	fullDBInfo, err := db.GetDatabaseInfo(db.WithAction(ctx, "dbinfo"), dbConn)
 	if err != nil {
 		dbConn.Close() // Ensure connection is closed if GetDatabaseInfo fails
 		return nil, nil, fmt.Errorf(langText("获取数据库信息失败: %w", "failed to get database info: %w", "データベース情報の取得に失敗しました: %w", req.Lang), err)
 	}
+	if fullDBInfo.Mounted() {
+		logger.Warnf("Database %s is only MOUNTED; modules that need the data dictionary will be skipped.", req.connectionString())
+	}
 	return dbConn, fullDBInfo, nil
 }
 
 // processInspectionModules processes all selected inspection modules.
-func processInspectionModules(items []string, dbConn *sql.DB, lang string, fullDBInfo *db.FullDBInfo) []ReportModule {
+func processInspectionModules(ctx context.Context, items []string, dbConn *sql.DB, lang string, fullDBInfo *db.FullDBInfo, thresholds FindingThresholds) []ReportModule {
 	var modules []ReportModule
 	for _, item := range items {
-		module, err := ProcessInspectionItem(item, dbConn, lang, fullDBInfo)
+		module, err := ProcessInspectionItem(ctx, item, dbConn, lang, fullDBInfo, thresholds)
 		if err != nil {
 			logger.Error(langText("处理巡检项 %s 时出错: %v", "Error processing inspection item %s: %v", "検査項目 %s の処理中にエラーが発生しました: %v", lang), item, err)
 			module = ReportModule{
@@ -278,7 +383,9 @@ func processInspectionModules(items []string, dbConn *sql.DB, lang string, fullD
 					Title: langText("错误", "Error", "エラー", lang),
 					Value: fmt.Sprintf(langText("处理巡检项时出错: %v", "Error processing inspection item: %v", "検査項目の処理中にエラーが発生しました: %v", lang), err),
 				}},
+				Error: err.Error(),
 			}
+			module.HealthScore = moduleHealthScore(&module)
 		}
 		modules = append(modules, module)
 	}
@@ -286,7 +393,7 @@ func processInspectionModules(items []string, dbConn *sql.DB, lang string, fullD
 }
 
 // prepareReportData 准备报告的整体数据结构
-func prepareReportData(req *DBConnectionRequest, fullDBInfo *db.FullDBInfo, modules []ReportModule, lang string) (ReportData, string) {
+func prepareReportData(req *DBConnectionRequest, fullDBInfo *db.FullDBInfo, modules []ReportModule, lang string, thresholdProfile string) ReportData {
 	reportSections := make([]ReportSection, 0, len(modules))
 	for _, module := range modules {
 		reportSections = append(reportSections, ReportSection{
@@ -300,28 +407,43 @@ func prepareReportData(req *DBConnectionRequest, fullDBInfo *db.FullDBInfo, modu
 		dbInfoStr += fmt.Sprintf(" @ %s", fullDBInfo.Instances[0].HostName)
 	}
 
-	dbConnectionStr := fmt.Sprintf("%s:%s/%s", req.Host, req.Port, req.Service)
-	reportID := generateReportID(req.Host, req.Port, req.Service)
+	dbConnectionStr := req.connectionString()
+
+	// 记录实际会话用户；代理认证时同时记录代理用户，便于审计
+	sessionUser, proxyUser := fullDBInfo.Session.User, fullDBInfo.Session.ProxyUser
+	if sessionUser == "" {
+		sessionUser = strings.ToUpper(req.Username)
+		if req.ProxyClient != "" {
+			sessionUser, proxyUser = strings.ToUpper(req.ProxyClient), strings.ToUpper(req.Username)
+		}
+	}
 
 	reportData := ReportData{
-		Lang:           lang,
-		Title:          langText("Oracle 数据库巡检报告", "Oracle Database Inspection Report", "Oracleデータベース検査レポート", lang),
-		BusinessName:   req.Business,
-		DBName:         fullDBInfo.Database.Name.String,
-		DBFullInfo:     dbInfoStr,
-		DBConnection:   dbConnectionStr,
-		GeneratedAt:    time.Now().Format("2006-01-02 15:04:05"),
-		Modules:        modules,
-		ReportSections: reportSections,
-	}
-	return reportData, reportID
+		Lang:             lang,
+		Title:            langText("Oracle 数据库巡检报告", "Oracle Database Inspection Report", "Oracleデータベース検査レポート", lang),
+		BusinessName:     req.Business,
+		DBName:           fullDBInfo.Database.Name.String,
+		DBFullInfo:       dbInfoStr,
+		DBConnection:     dbConnectionStr,
+		SessionUser:      sessionUser,
+		ProxyUser:        proxyUser,
+		GeneratedAt:      time.Now().Format("2006-01-02 15:04:05"),
+		Modules:          modules,
+		ReportSections:   reportSections,
+		ThresholdProfile: thresholdProfile,
+		HealthScore:      overallHealthScore(modules),
+	}
+	return reportData
 }
 
 // storeAndRespond 保存报告并发送HTTP响应
 func storeAndRespond(w http.ResponseWriter, reportID string, reportData ReportData) {
-	reportStoreMutex.Lock()
-	reportStore[reportID] = reportData
-	reportStoreMutex.Unlock()
+	if err := StoreReport(reportID, reportData); err != nil {
+		logger.Errorf("Failed to store report %s: %v", reportID, err)
+		http.Error(w, "Failed to store report", http.StatusInternalServerError)
+		return
+	}
+	go NotifyReportFinished(reportID, reportData)
 
 	response := map[string]interface{}{
 		"success":  true,
@@ -350,26 +472,53 @@ func InspectHandler(debug bool) http.HandlerFunc {
 			return
 		}
 
-		dbConn, fullDBInfo, err := establishDBConnection(req)
+		reportData, reportID, err := RunInspection(r.Context(), req)
 		if err != nil {
 			logger.Error(fmt.Sprintf("API Error: %v", err))
+			go NotifyInspectionFailed(NewInspectionFailure(req, err))
 			http.Error(w, err.Error(), http.StatusInternalServerError) // Or appropriate status based on error type
 			return
 		}
-		defer func() {
-			if dbConn != nil {
-				if err := dbConn.Close(); err != nil {
-					logger.Error(fmt.Sprintf("Failed to close database connection: %v", err))
-				}
-			}
-		}()
-
-		modules := processInspectionModules(req.Items, dbConn, req.Lang, fullDBInfo)
-		reportData, reportID := prepareReportData(req, fullDBInfo, modules, req.Lang)
 		storeAndRespond(w, reportID, reportData)
 	}
 }
 
+// RunInspection connects to the database described by req, processes every selected
+// inspection module and returns the assembled report together with its ID.
+// It is the shared pipeline behind InspectHandler and the headless CLI; req is expected
+// to have passed ValidateInspectParameters already. Cancelling ctx stops the running queries;
+// no report is returned then.
+func RunInspection(ctx context.Context, req *DBConnectionRequest) (ReportData, string, error) {
+	profile, thresholds, err := resolveThresholdProfile(req.ThresholdProfile)
+	if err != nil {
+		return ReportData{}, "", err
+	}
+
+	// The report ID is known up front, so the database sessions can carry it as their CLIENT_INFO
+	reportID := req.ReportID
+	if reportID == "" {
+		reportID = generateReportID(req.Host, req.Port, req.Service)
+	}
+	ctx = db.WithClientInfo(ctx, reportID)
+
+	dbConn, fullDBInfo, err := establishDBConnection(ctx, req)
+	if err != nil {
+		return ReportData{}, "", err
+	}
+	defer func() {
+		if err := dbConn.Close(); err != nil {
+			logger.Error(fmt.Sprintf("Failed to close database connection: %v", err))
+		}
+	}()
+
+	modules := processInspectionModules(ctx, req.Items, dbConn, req.Lang, fullDBInfo, thresholds)
+	if ctx.Err() != nil {
+		return ReportData{}, "", fmt.Errorf(langText("巡检已取消: %w", "inspection cancelled: %w", "検査がキャンセルされました: %w", req.Lang), context.Cause(ctx))
+	}
+	reportData := prepareReportData(req, fullDBInfo, modules, req.Lang, profile)
+	return reportData, reportID, nil
+}
+
 // generateReportID 根据输入参数生成一个唯一的报告ID
 func generateReportID(host, port, service string) string {
 	timestamp := time.Now().UnixNano()
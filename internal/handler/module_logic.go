@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strconv"
@@ -10,8 +11,8 @@ import (
 )
 
 // processParametersModule handles the "parameters" inspection item.
-func processParametersModule(dbConn *sql.DB, lang string) (cards []ReportCard, tables []*ReportTable, charts []ReportChart, err error) {
-	params, dbErr := db.GetParameterList(dbConn)
+func processParametersModule(ctx context.Context, dbConn *sql.DB, lang string) (cards []ReportCard, tables []*ReportTable, charts []ReportChart, err error) {
+	params, dbErr := db.GetParameterList(ctx, dbConn)
 	if dbErr != nil {
 		cards = append(cards, ReportCard{Title: langText("错误", "Error", "エラー", lang), Value: fmt.Sprintf(langText("获取参数失败: %v", "Failed to get parameters: %v", "パラメータの取得に失敗しました: %v", lang), dbErr)})
 		return cards, nil, nil, dbErr
@@ -19,6 +20,7 @@ func processParametersModule(dbConn *sql.DB, lang string) (cards []ReportCard, t
 	logger.Debugf("Fetched parameters: %v", params)
 	if len(params) > 0 {
 		paramTable := &ReportTable{
+			Key:     "parameters",
 			Name:    langText("参数列表", "Parameter List", "パラメータリスト", lang),
 			Headers: []string{langText("参数名", "Parameter Name", "パラメータ名", lang), langText("值", "Value", "値", lang)},
 			Rows:    [][]string{},
@@ -33,12 +35,12 @@ func processParametersModule(dbConn *sql.DB, lang string) (cards []ReportCard, t
 }
 
 // processDbinfoModule handles the "dbinfo" inspection item.
-func processDbinfoModule(dbConn *sql.DB, lang string, preFetchedInfo *db.FullDBInfo) (cards []ReportCard, tables []*ReportTable, charts []ReportChart, err error) {
+func processDbinfoModule(ctx context.Context, dbConn *sql.DB, lang string, preFetchedInfo *db.FullDBInfo) (cards []ReportCard, tables []*ReportTable, charts []ReportChart, err error) {
 	dbInfoToProcess := preFetchedInfo
 	var fetchErr error
 
 	if dbInfoToProcess == nil {
-		dbInfoToProcess, fetchErr = db.GetDatabaseInfo(dbConn)
+		dbInfoToProcess, fetchErr = db.GetDatabaseInfo(ctx, dbConn)
 		if fetchErr != nil {
 			cards = append(cards, ReportCard{Title: langText("错误", "Error", "エラー", lang), Value: fmt.Sprintf(langText("获取数据库信息失败: %v", "Failed to get database info: %v", "データベース情報の取得に失敗しました: %v", lang), fetchErr)})
 			return cards, nil, nil, fetchErr
@@ -65,6 +67,7 @@ func processDbinfoModule(dbConn *sql.DB, lang string, preFetchedInfo *db.FullDBI
 
 	if len(dbInfoToProcess.Instances) > 0 {
 		instanceTable := &ReportTable{
+			Key:     "instances",
 			Name:    langText("实例信息", "Instance Information", "インスタンス情報", lang),
 			Headers: []string{langText("实例ID", "Inst ID", "インスタンスID", lang), langText("实例名", "Instance Name", "インスタンス名", lang), langText("主机名", "Host Name", "ホスト名", lang), langText("版本", "Version", "バージョン", lang), langText("启动时间", "Startup Time", "起動時間", lang), langText("状态", "Status", "ステータス", lang)},
 			Rows:    [][]string{},
@@ -86,16 +89,21 @@ func processDbinfoModule(dbConn *sql.DB, lang string, preFetchedInfo *db.FullDBI
 }
 
 // processStorageModule handles the "storage" inspection item.
-func processStorageModule(dbConn *sql.DB, lang string) (cards []ReportCard, tables []*ReportTable, charts []ReportChart, err error) {
-	storageData, dbErr := db.GetStorageInfo(dbConn)
+// A mounted database has no data dictionary, so data files and tablespaces are skipped.
+func processStorageModule(ctx context.Context, dbConn *sql.DB, lang string, mounted bool) (cards []ReportCard, tables []*ReportTable, charts []ReportChart, err error) {
+	storageData, dbErr := db.GetStorageInfo(ctx, dbConn, mounted)
 	if dbErr != nil {
 		cards = append(cards, ReportCard{Title: langText("错误", "Error", "エラー", lang), Value: fmt.Sprintf(langText("获取存储信息失败: %v", "Failed to get storage info: %v", "ストレージ情報の取得に失敗しました: %v", lang), dbErr)})
 		return cards, nil, nil, dbErr
 	}
+	if mounted {
+		cards = append(cards, mountedSkipCard(langText("数据文件与表空间", "Data Files & Tablespaces", "データファイルと表領域", lang), lang))
+	}
 
 	// Control Files
 	if len(storageData.ControlFiles) > 0 {
 		cfTable := &ReportTable{
+			Key:     "control_files",
 			Name:    langText("控制文件", "Control Files", "制御ファイル", lang),
 			Headers: []string{langText("文件路径", "File Path", "ファイルパス", lang), langText("大小(MB)", "Size(MB)", "サイズ(MB)", lang)},
 			Rows:    [][]string{},
@@ -112,6 +120,7 @@ func processStorageModule(dbConn *sql.DB, lang string) (cards []ReportCard, tabl
 	// Redo Log Groups
 	if len(storageData.RedoLogs) > 0 {
 		redoTable := &ReportTable{
+			Key:     "redo_logs",
 			Name:    langText("重做日志组", "Redo Log Groups", "REDOログ・グループ", lang),
 			Headers: []string{langText("组号", "Group#", "グループ番号", lang), langText("线程号", "Thread#", "スレッド番号", lang), langText("成员数", "Members", "メンバー数", lang), langText("大小(MB)", "Size(MB)", "サイズ(MB)", lang), langText("成员文件", "Member Files", "メンバーファイル", lang), langText("状态", "Status", "ステータス", lang), langText("已归档", "Archived", "アーカイブ済み", lang), langText("类型", "Type", "タイプ", lang)},
 			Rows:    [][]string{},
@@ -137,6 +146,7 @@ func processStorageModule(dbConn *sql.DB, lang string) (cards []ReportCard, tabl
 	// Tablespace Usage
 	if len(storageData.Tablespaces) > 0 {
 		tsTable := &ReportTable{
+			Key:     "tablespaces",
 			Name:    langText("表空间使用情况", "Tablespace Usage", "表領域使用率", lang),
 			Headers: []string{langText("状态", "Status", "ステータス", lang), langText("表空间名", "Tablespace Name", "表領域名", lang), langText("类型", "Type", "タイプ", lang), langText("区管理", "Extent Management", "エクステント管理", lang), langText("段管理", "Segment Management", "セグメント管理", lang), langText("已用(MB)", "Used(MB)", "使用済み(MB)", lang), langText("总计(MB)", "Total(MB)", "合計(MB)", lang), langText("使用率 %", "Used %", "使用率 %", lang), langText("自动扩展大小(MB)", "Autoextend Size(MB)", "自動拡張サイズ(MB)", lang)},
 			Rows:    [][]string{},
@@ -163,6 +173,7 @@ func processStorageModule(dbConn *sql.DB, lang string) (cards []ReportCard, tabl
 	// Data Files
 	if len(storageData.DataFiles) > 0 {
 		dfTable := &ReportTable{
+			Key:     "datafiles",
 			Name:    langText("数据文件", "Data Files", "データファイル", lang),
 			Headers: []string{langText("文件ID", "File ID", "ファイルID", lang), langText("文件名", "File Name", "ファイル名", lang), langText("表空间", "Tablespace", "表領域", lang), langText("大小(MB)", "Size(MB)", "サイズ(MB)", lang), langText("状态", "Status", "ステータス", lang), langText("自动扩展", "Autoextend", "自動拡張", lang)},
 			Rows:    [][]string{},
@@ -186,6 +197,7 @@ func processStorageModule(dbConn *sql.DB, lang string) (cards []ReportCard, tabl
 	// Archived Log Summary
 	if len(storageData.ArchivedLogsSummary) > 0 {
 		archTable := &ReportTable{
+			Key:     "archived_logs",
 			Name:    langText("归档日志摘要 (最近7天)", "Archived Log Summary (Last 7 Days)", "アーカイブREDOログのサマリー (過去7日間)", lang),
 			Headers: []string{langText("日期", "Day", "日付", lang), langText("日志计数", "Log Count", "ログ数", lang), langText("总大小(MB)", "Total Size(MB)", "合計サイズ(MB)", lang)},
 			Rows:    [][]string{},
@@ -206,6 +218,7 @@ func processStorageModule(dbConn *sql.DB, lang string) (cards []ReportCard, tabl
 	// ASM Disk Groups
 	if len(storageData.ASMDiskgroups) > 0 {
 		asmTable := &ReportTable{
+			Key:     "asm_diskgroups",
 			Name:    langText("ASM磁盘组", "ASM Disk Groups", "ASMディスク・グループ", lang),
 			Headers: []string{langText("磁盘组名", "Diskgroup Name", "ディスクグループ名", lang), langText("总大小(MB)", "Total Size(MB)", "合計サイズ(MB)", lang), langText("空闲(MB)", "Free(MB)", "空き(MB)", lang), langText("使用率 %", "Used %", "使用率 %", lang), langText("状态", "State", "状態", lang), langText("冗余", "Redundancy", "冗長性", lang)},
 			Rows:    [][]string{},
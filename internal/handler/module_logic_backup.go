@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/goodwaysIT/inspect4oracle/internal/db"
@@ -52,6 +54,7 @@ func generateRMANJobsTable(backupData *db.AllBackupInfo, lang string) (card *Rep
 
 	if len(backupData.RMANJobs) > 0 {
 		rmanTable := &ReportTable{
+			Key:  "rman_jobs",
 			Name: langText("最近RMAN备份作业 (过去7天)", "Recent RMAN Backup Jobs (Last 7 Days)", "最近のRMANバックアップジョブ (過去7日間)", lang),
 			Headers: []string{
 				langText("会话键", "Session Key", "セッションキー", lang), langText("开始时间", "Start Time", "開始時間", lang), langText("结束时间", "End Time", "終了時間", lang),
@@ -95,6 +98,10 @@ func generateRMANJobsTable(backupData *db.AllBackupInfo, lang string) (card *Rep
 
 // generateRecycleBinTable generates a report table for recycle bin objects or a card if no data/error.
 func generateRecycleBinTable(backupData *db.AllBackupInfo, lang string) (card *ReportCard, table *ReportTable, err error) {
+	if errors.Is(backupData.RecycleBinError, db.ErrDictionaryUnavailable) {
+		skipped := mountedSkipCard(langText("回收站", "Recycle Bin", "リサイクルビン", lang), lang)
+		return &skipped, nil, nil
+	}
 	if backupData.RecycleBinError != nil {
 		logger.Errorf("Failed to get recycle bin objects: %v", backupData.RecycleBinError)
 		noDataCard := cardFromError("回收站错误", "Recycle Bin Error", "リサイクルビンエラー", backupData.RecycleBinError, lang)
@@ -103,6 +110,7 @@ func generateRecycleBinTable(backupData *db.AllBackupInfo, lang string) (card *R
 
 	if len(backupData.RecycleBinItems) > 0 {
 		rbTable := &ReportTable{
+			Key:  "recyclebin",
 			Name: langText("回收站对象 (可恢复)", "Recycle Bin Objects (Restorable)", "リサイクルビンオブジェクト (復元可能)", lang),
 			Headers: []string{
 				langText("所有者", "Owner", "所有者", lang), langText("对象名称", "Object Name", "オブジェクト名", lang), langText("原始名称", "Original Name", "元の名前", lang),
@@ -135,6 +143,10 @@ func generateRecycleBinTable(backupData *db.AllBackupInfo, lang string) (card *R
 
 // generateDataPumpJobsTable generates a report table for Data Pump jobs or a card if no data/error.
 func generateDataPumpJobsTable(backupData *db.AllBackupInfo, lang string) (card *ReportCard, table *ReportTable, err error) {
+	if errors.Is(backupData.DataPumpJobsError, db.ErrDictionaryUnavailable) {
+		skipped := mountedSkipCard(langText("数据泵作业", "Data Pump Jobs", "データポンプジョブ", lang), lang)
+		return &skipped, nil, nil
+	}
 	if backupData.DataPumpJobsError != nil {
 		logger.Errorf("Failed to get Data Pump jobs: %v", backupData.DataPumpJobsError)
 		noDataCard := cardFromError("数据泵作业错误", "Data Pump Jobs Error", "データポンプジョブエラー", backupData.DataPumpJobsError, lang)
@@ -143,6 +155,7 @@ func generateDataPumpJobsTable(backupData *db.AllBackupInfo, lang string) (card
 
 	if len(backupData.DataPumpJobs) > 0 {
 		dpTable := &ReportTable{
+			Key:  "datapump_jobs",
 			Name: langText("Data Pump 作业", "Data Pump Jobs", "データポンプジョブ", lang),
 			Headers: []string{
 				langText("作业名称", "Job Name", "ジョブ名", lang), langText("所有者", "Owner", "所有者", lang), langText("操作", "Operation", "操作", lang),
@@ -177,10 +190,10 @@ func generateDataPumpJobsTable(backupData *db.AllBackupInfo, lang string) (card
 }
 
 // processBackupModule handles the "backup" inspection item.
-func processBackupModule(dbConn *sql.DB, lang string) (allCards []ReportCard, allTables []*ReportTable, charts []ReportChart, overallErr error) {
+func processBackupModule(ctx context.Context, dbConn *sql.DB, lang string, mounted bool) (allCards []ReportCard, allTables []*ReportTable, charts []ReportChart, overallErr error) {
 	logger.Infof("Starting to process backup module... Language: %s", lang)
 
-	backupData := db.GetAllBackupDetails(dbConn) // backupData is of type db.AllBackupInfo
+	backupData := db.GetAllBackupDetails(ctx, dbConn, mounted) // backupData is of type db.AllBackupInfo
 
 	// If there's an error getting ArchivelogMode, it might indicate a broader issue with DB access for backup info.
 	if backupData.ArchivelogModeError != nil {
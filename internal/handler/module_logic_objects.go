@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -19,10 +20,10 @@ func appendError(existingErr, newErr error) error {
 	return fmt.Errorf("%v; %w", existingErr, newErr)
 }
 
-func processObjectsModule(dbConn *sql.DB, lang string) (cards []ReportCard, tables []*ReportTable, charts []ReportChart, overallErr error) {
+func processObjectsModule(ctx context.Context, dbConn *sql.DB, lang string) (cards []ReportCard, tables []*ReportTable, charts []ReportChart, overallErr error) {
 	logger.Infof("Starting to process objects module... Language: %s", lang)
 
-	allDbObjectInfo, overviewErr, topSegmentsErr, invalidObjectsErr := db.GetObjectDetails(dbConn)
+	allDbObjectInfo, overviewErr, topSegmentsErr, invalidObjectsErr := db.GetObjectDetails(ctx, dbConn)
 
 	// 1. Process object type statistics
 	if overviewErr != nil {
@@ -34,6 +35,7 @@ func processObjectsModule(dbConn *sql.DB, lang string) (cards []ReportCard, tabl
 		overallErr = appendError(overallErr, overviewErr)
 	} else if allDbObjectInfo != nil && len(allDbObjectInfo.Overview) > 0 {
 		objCountTable := &ReportTable{
+			Key:     "object_type_counts",
 			Name:    langText("对象类型统计", "Object Type Counts", "オブジェクトタイプ統計", lang),
 			Headers: []string{langText("所有者", "Owner", "所有者", lang), langText("对象类型", "Object Type", "オブジェクトタイプ", lang), langText("数量", "Count", "数量", lang)}, // Added Owner header
 			Rows:    [][]string{},
@@ -60,6 +62,7 @@ func processObjectsModule(dbConn *sql.DB, lang string) (cards []ReportCard, tabl
 		overallErr = appendError(overallErr, topSegmentsErr)
 	} else if allDbObjectInfo != nil && len(allDbObjectInfo.TopSegments) > 0 {
 		topSegmentsTable := &ReportTable{
+			Key:     "top_segments",
 			Name:    langText("按大小排列的热点段", "Top Segments by Size", "サイズ別トップセグメント", lang),
 			Headers: []string{langText("所有者", "Owner", "所有者", lang), langText("段名", "Segment Name", "セグメント名", lang), langText("段类型", "Segment Type", "セグメントタイプ", lang), langText("大小(GB)", "Size (GB)", "サイズ(GB)", lang)},
 			Rows:    [][]string{},
@@ -87,6 +90,7 @@ func processObjectsModule(dbConn *sql.DB, lang string) (cards []ReportCard, tabl
 		overallErr = appendError(overallErr, invalidObjectsErr)
 	} else if allDbObjectInfo != nil && len(allDbObjectInfo.InvalidObjects) > 0 {
 		invalidObjectsTable := &ReportTable{
+			Key:     "invalid_objects",
 			Name:    langText("无效对象", "Invalid Objects", "無効なオブジェクト", lang),
 			Headers: []string{langText("所有者", "Owner", "所有者", lang), langText("对象名", "Object Name", "オブジェクト名", lang), langText("对象类型", "Object Type", "オブジェクトタイプ", lang), langText("创建时间", "Created", "作成日時", lang), langText("最后DDL时间", "Last DDL Time", "最終DDL時間", lang)},
 			Rows:    [][]string{},
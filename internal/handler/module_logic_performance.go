@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -151,7 +152,7 @@ func generatePerformanceChart(metricNameStr string, metricValues []db.SysMetricS
 }
 
 // processPerformanceModule handles the logic for the performance module, fetching metric data and generating charts.
-func processPerformanceModule(dbConn *sql.DB, lang string) ([]ReportCard, []*ReportTable, []ReportChart, error) {
+func processPerformanceModule(ctx context.Context, dbConn *sql.DB, lang string) ([]ReportCard, []*ReportTable, []ReportChart, error) {
 	var cards []ReportCard
 	var tables []*ReportTable // Performance module currently doesn't generate tables, but we keep the signature consistent
 	var charts []ReportChart
@@ -160,7 +161,7 @@ func processPerformanceModule(dbConn *sql.DB, lang string) ([]ReportCard, []*Rep
 	logger.Info("Starting to process performance module...")
 
 	// 1. Get all performance metrics data
-	metricsBundle := db.GetAllPerformanceMetrics(dbConn)
+	metricsBundle := db.GetAllPerformanceMetrics(ctx, dbConn)
 	metricsData := metricsBundle.SysMetricsSummary
 	err := metricsBundle.SysMetricsError
 	if err != nil {
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -11,8 +12,8 @@ import (
 )
 
 // generateNonSystemUsersTable fetches non-system user information and prepares a ReportTable or ReportCard.
-func generateNonSystemUsersTable(dbConn *sql.DB, lang string) (*ReportTable, *ReportCard, error) {
-	nonSystemUsers, err := db.GetNonSystemUsers(dbConn)
+func generateNonSystemUsersTable(ctx context.Context, dbConn *sql.DB, lang string) (*ReportTable, *ReportCard, error) {
+	nonSystemUsers, err := db.GetNonSystemUsers(ctx, dbConn)
 	if err != nil {
 		msg := fmt.Sprintf(langText("获取非系统用户信息失败: %v", "Failed to get non-system user info: %v", "非システムユーザー情報の取得に失敗しました: %v", lang), err)
 		card := &ReportCard{
@@ -24,6 +25,7 @@ func generateNonSystemUsersTable(dbConn *sql.DB, lang string) (*ReportTable, *Re
 
 	if len(nonSystemUsers) > 0 {
 		usersTable := &ReportTable{
+			Key:  "users",
 			Name: langText("非系统用户账户", "Non-System User Accounts", "非システムユーザーアカウント", lang),
 			Headers: []string{
 				langText("用户名", "Username", "ユーザー名", lang),
@@ -76,8 +78,8 @@ func generateNonSystemUsersTable(dbConn *sql.DB, lang string) (*ReportTable, *Re
 }
 
 // generateProfilesTable fetches Profile configuration information and prepares a ReportTable or ReportCard.
-func generateProfilesTable(dbConn *sql.DB, lang string) (*ReportTable, *ReportCard, error) {
-	profiles, err := db.GetProfiles(dbConn)
+func generateProfilesTable(ctx context.Context, dbConn *sql.DB, lang string) (*ReportTable, *ReportCard, error) {
+	profiles, err := db.GetProfiles(ctx, dbConn)
 	if err != nil {
 		msg := fmt.Sprintf(langText("获取配置文件失败: %v", "Failed to get Profile configuration: %v", "プロファイル構成の取得に失敗しました: %v", lang), err)
 		card := &ReportCard{
@@ -89,6 +91,7 @@ func generateProfilesTable(dbConn *sql.DB, lang string) (*ReportTable, *ReportCa
 
 	if len(profiles) > 0 {
 		profilesTable := &ReportTable{
+			Key:  "profiles",
 			Name: langText("配置文件 (密码策略 & 默认)", "Profile Configuration (Password Policies & DEFAULT)", "プロファイル構成 (パスワードポリシー & デフォルト)", lang),
 			Headers: []string{
 				langText("配置文件名称", "Profile Name", "プロファイル名", lang),
@@ -116,8 +119,8 @@ func generateProfilesTable(dbConn *sql.DB, lang string) (*ReportTable, *ReportCa
 }
 
 // generateNonSystemRolesTable fetches non-system roles information and prepares a ReportTable or ReportCard.
-func generateNonSystemRolesTable(dbConn *sql.DB, lang string) (*ReportTable, *ReportCard, error) {
-	nonSystemRoles, err := db.GetNonSystemRoles(dbConn)
+func generateNonSystemRolesTable(ctx context.Context, dbConn *sql.DB, lang string) (*ReportTable, *ReportCard, error) {
+	nonSystemRoles, err := db.GetNonSystemRoles(ctx, dbConn)
 	if err != nil {
 		msg := fmt.Sprintf(langText("获取非系统角色失败: %v", "Failed to get non-system roles: %v", "非システムロールの取得に失敗しました: %v", lang), err)
 		card := &ReportCard{
@@ -129,6 +132,7 @@ func generateNonSystemRolesTable(dbConn *sql.DB, lang string) (*ReportTable, *Re
 
 	if len(nonSystemRoles) > 0 {
 		rolesTable := &ReportTable{
+			Key:  "roles",
 			Name: langText("非系统角色", "Non-System Roles", "非システムロール", lang),
 			Headers: []string{
 				langText("角色名称", "Role Name", "ロール名", lang),
@@ -151,8 +155,8 @@ func generateNonSystemRolesTable(dbConn *sql.DB, lang string) (*ReportTable, *Re
 }
 
 // generateUsersWithPrivilegedRolesTable fetches users with privileged roles and prepares a ReportTable or ReportCard.
-func generateUsersWithPrivilegedRolesTable(dbConn *sql.DB, lang string) (*ReportTable, *ReportCard, error) {
-	usersWithPrivRoles, err := db.GetUsersWithPrivilegedRoles(dbConn)
+func generateUsersWithPrivilegedRolesTable(ctx context.Context, dbConn *sql.DB, lang string) (*ReportTable, *ReportCard, error) {
+	usersWithPrivRoles, err := db.GetUsersWithPrivilegedRoles(ctx, dbConn)
 	if err != nil {
 		msg := fmt.Sprintf(langText("获取用户特权角色失败: %v", "Failed to get user privileged roles: %v", "ユーザーの特権ロールの取得に失敗しました: %v", lang), err)
 		card := &ReportCard{
@@ -164,6 +168,7 @@ func generateUsersWithPrivilegedRolesTable(dbConn *sql.DB, lang string) (*Report
 
 	if len(usersWithPrivRoles) > 0 {
 		userPrivRolesTable := &ReportTable{
+			Key:  "privileged_role_grants",
 			Name: langText("拥有特权角色的用户", "Users with Privileged Roles", "特権ロールを持つユーザー", lang),
 			Headers: []string{
 				langText("用户名", "Username", "ユーザー名", lang),
@@ -187,11 +192,11 @@ func generateUsersWithPrivilegedRolesTable(dbConn *sql.DB, lang string) (*Report
 }
 
 // processSecurityModule handles the "security" inspection item.
-func processSecurityModule(dbConn *sql.DB, lang string, fullDBInfo *db.FullDBInfo) (cards []ReportCard, tables []*ReportTable, charts []ReportChart, overallErr error) {
+func processSecurityModule(ctx context.Context, dbConn *sql.DB, lang string, fullDBInfo *db.FullDBInfo) (cards []ReportCard, tables []*ReportTable, charts []ReportChart, overallErr error) {
 	logger.Infof("Starting to process security module... Language: %s", lang)
 
 	// 1. Get non-system user information
-	userTable, userCard, userErr := generateNonSystemUsersTable(dbConn, lang)
+	userTable, userCard, userErr := generateNonSystemUsersTable(ctx, dbConn, lang)
 	if userErr != nil {
 		logger.Errorf("Error processing security module - fetching non-system users: %v", userErr)
 		if userCard != nil { // Helper provided a specific error card
@@ -209,7 +214,7 @@ func processSecurityModule(dbConn *sql.DB, lang string, fullDBInfo *db.FullDBInf
 	}
 
 	// 2. Get Profile configuration information
-	profileTable, profileCard, profileErr := generateProfilesTable(dbConn, lang)
+	profileTable, profileCard, profileErr := generateProfilesTable(ctx, dbConn, lang)
 	if profileErr != nil {
 		logger.Errorf("Error processing security module - fetching profile configurations: %v", profileErr)
 		if profileCard != nil { // Helper provided a specific error card
@@ -227,7 +232,7 @@ func processSecurityModule(dbConn *sql.DB, lang string, fullDBInfo *db.FullDBInf
 	}
 
 	// 3. Get non-system role list
-	rolesTable, rolesCard, rolesErr := generateNonSystemRolesTable(dbConn, lang)
+	rolesTable, rolesCard, rolesErr := generateNonSystemRolesTable(ctx, dbConn, lang)
 	if rolesErr != nil {
 		logger.Errorf("Error processing security module - fetching non-system roles: %v", rolesErr)
 		if rolesCard != nil { // Helper provided a specific error card
@@ -245,7 +250,7 @@ func processSecurityModule(dbConn *sql.DB, lang string, fullDBInfo *db.FullDBInf
 	}
 
 	// 4. Get list of users with privileged roles
-	userPrivRolesTable, userPrivRolesCard, userPrivRolesErr := generateUsersWithPrivilegedRolesTable(dbConn, lang)
+	userPrivRolesTable, userPrivRolesCard, userPrivRolesErr := generateUsersWithPrivilegedRolesTable(ctx, dbConn, lang)
 	if userPrivRolesErr != nil {
 		logger.Errorf("Error processing security module - fetching user privileged roles: %v", userPrivRolesErr)
 		if userPrivRolesCard != nil { // Helper provided a specific error card
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json" // For chart data serialization
 	"fmt"
@@ -21,6 +22,7 @@ func generateSessionOverview(sessionData *db.AllSessionInfo, fetchErr error, lan
 
 	if sessionData != nil && len(sessionData.Overview) > 0 {
 		sessionOverviewTable := ReportTable{
+			Key:     "session_overview",
 			Name:    langText("会话总览", "Session Overview", "セッション概要", lang),
 			Headers: []string{langText("实例", "Inst", "インスタンス", lang), langText("用户名", "Username", "ユーザー名", lang), langText("机器", "Machine", "マシン", lang), langText("状态", "Status", "ステータス", lang), langText("会话数", "Count", "セッション数", lang)},
 			Rows:    [][]string{},
@@ -57,6 +59,7 @@ func generateSessionByEvent(sessionData *db.AllSessionInfo, fetchErr error, lang
 
 	if sessionData != nil && len(sessionData.ByEvent) > 0 {
 		sessionByEventTable := ReportTable{
+			Key:     "session_waits",
 			Name:    langText("按等待事件统计会话数", "Session Count by Wait Event", "待機イベント別セッション数", lang),
 			Headers: []string{langText("等待事件", "Wait Event", "待機イベント", lang), langText("会话数", "Count", "セッション数", lang)},
 			Rows:    [][]string{},
@@ -178,10 +181,10 @@ func generateSessionHistoryChart(sessionData *db.AllSessionInfo, fetchErr error,
 }
 
 // processSessionsModule handles the "sessions" inspection item.
-func processSessionsModule(dbConn *sql.DB, lang string) (allCards []ReportCard, allTables []*ReportTable, allCharts []ReportChart, overallErr error) {
+func processSessionsModule(ctx context.Context, dbConn *sql.DB, lang string) (allCards []ReportCard, allTables []*ReportTable, allCharts []ReportChart, overallErr error) {
 	logger.Debugf("Starting to process sessions module, language: %s", lang)
 
-	sessionData, overviewFetchErr, eventFetchErr, historyFetchErr := db.GetSessionDetails(dbConn)
+	sessionData, overviewFetchErr, eventFetchErr, historyFetchErr := db.GetSessionDetails(ctx, dbConn)
 
 	// Helper to manage overall error, ensuring we capture the first non-nil error.
 	setOverallErr := func(e error) {
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/db"
+	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+)
+
+// allInspectionItems are the modules checked and granted when a request selects none.
+var allInspectionItems = []string{"dbinfo", "params", "storage", "sessions", "objects", "performance", "security", "backup"}
+
+// ModuleReadiness tells whether the connected user can query the views of one inspection module.
+type ModuleReadiness struct {
+	ID                   string   `json:"id"`
+	Name                 string   `json:"name"`
+	Ready                bool     `json:"ready"`                          // Every required view is accessible
+	MissingViews         []string `json:"missingViews,omitempty"`         // Required views the module fails without
+	MissingOptionalViews []string `json:"missingOptionalViews,omitempty"` // Views whose parts of the module are left out, e.g. ASM or ASH
+}
+
+// selectedModules resolves the inspection items of a request: dbinfo always comes first, the params alias
+// is folded and duplicates are dropped. No items means all modules.
+func selectedModules(items []string) ([]string, error) {
+	if len(items) == 0 {
+		items = allInspectionItems
+	}
+	modules := []string{"dbinfo"}
+	seen := map[string]bool{"dbinfo": true}
+	for _, item := range items {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "parameters" {
+			item = "params"
+		}
+		if item == "" || seen[item] {
+			continue
+		}
+		if _, ok := moduleProcessors[item]; !ok {
+			return nil, fmt.Errorf("unknown inspection item %q", item)
+		}
+		seen[item] = true
+		modules = append(modules, item)
+	}
+	return modules, nil
+}
+
+// moduleViews returns the views the modules query, required and optional ones apart. A view required by
+// one module is not listed as optional.
+func moduleViews(modules []string) (required, optional []string) {
+	isRequired := make(map[string]bool)
+	for _, item := range modules {
+		for _, view := range moduleProcessors[item].views {
+			if !isRequired[view] {
+				isRequired[view] = true
+				required = append(required, view)
+			}
+		}
+	}
+	isOptional := make(map[string]bool)
+	for _, item := range modules {
+		for _, view := range moduleProcessors[item].optionalViews {
+			if !isRequired[view] && !isOptional[view] {
+				isOptional[view] = true
+				optional = append(optional, view)
+			}
+		}
+	}
+	return required, optional
+}
+
+// moduleReadiness matches the privilege check results against the views of each module.
+// Views skipped because the database is only MOUNTED do not count as missing; those modules are skipped anyway.
+func moduleReadiness(modules []string, results []db.PrivilegeCheckResult, lang string) []ModuleReadiness {
+	denied := make(map[string]bool)
+	for _, result := range results {
+		if !result.HasAccess && !result.Skipped {
+			denied[result.ViewName] = true
+		}
+	}
+	readiness := make([]ModuleReadiness, 0, len(modules))
+	for _, item := range modules {
+		info := moduleProcessors[item]
+		module := ModuleReadiness{ID: item, Name: info.nameFunc(lang), Ready: true}
+		for _, view := range info.views {
+			if denied[view] {
+				module.Ready = false
+				module.MissingViews = append(module.MissingViews, view)
+			}
+		}
+		for _, view := range info.optionalViews {
+			if denied[view] {
+				module.MissingOptionalViews = append(module.MissingOptionalViews, view)
+			}
+		}
+		readiness = append(readiness, module)
+	}
+	return readiness
+}
+
+// GrantScript returns the SQL*Plus script that creates user as a dedicated monitoring account with
+// exactly the privileges the inspection items need (all modules when items is empty).
+// A proxy username "proxy[client]" grants to the client, the user the inspection queries run as.
+func GrantScript(user string, items []string) (string, error) {
+	if _, client := db.SplitProxyUser(user); client != "" {
+		user = client
+	}
+	modules, err := selectedModules(items)
+	if err != nil {
+		return "", err
+	}
+	required, optional := moduleViews(modules)
+	script, err := db.GrantScript(user, append(required, optional...))
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	b.WriteString("-- Least-privilege monitoring user for inspect4oracle\n")
+	fmt.Fprintf(&b, "-- Modules: %s\n", strings.Join(modules, ", "))
+	b.WriteString("-- Run in SQL*Plus or SQLcl as a user allowed to create users and grant on SYS views, e.g. SYSDBA.\n")
+	if len(optional) > 0 {
+		fmt.Fprintf(&b, "-- Optional views (%s) only complete parts of their modules and may be left out.\n", strings.ToUpper(strings.Join(optional, ", ")))
+	}
+	b.WriteString("\n")
+	b.WriteString(script)
+	return b.String(), nil
+}
+
+// GrantScriptHandler serves the GRANT script of GrantScript as a download.
+//
+//	GET /api/grants?user=MONITOR&items=storage,backup
+func GrantScriptHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		user := strings.TrimSpace(query.Get("user"))
+		if user == "" {
+			sendJSONError(w, "Missing user", http.StatusBadRequest)
+			return
+		}
+		var items []string
+		for _, value := range query["items"] {
+			if value = strings.TrimSpace(value); value != "" {
+				items = append(items, strings.Split(value, ",")...)
+			}
+		}
+		script, err := GrantScript(user, items)
+		if err != nil {
+			sendJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logger.Infof("GRANT script generated for user %s", strings.ToUpper(user))
+		w.Header().Set("Content-Type", "application/sql; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="inspect4oracle_grants.sql"`)
+		if _, err := w.Write([]byte(script)); err != nil {
+			logger.Errorf("Failed to write GRANT script: %v", err)
+		}
+	}
+}
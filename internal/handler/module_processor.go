@@ -1,58 +1,79 @@
 package handler
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/goodwaysIT/inspect4oracle/internal/db"
 	"github.com/goodwaysIT/inspect4oracle/internal/logger"
 )
 
+// DefaultModuleTimeout is the module timeout used until SetModuleTimeout is called.
+const DefaultModuleTimeout = 10 * time.Minute
+
+// moduleTimeout limits the total run time of one inspection module; 0 means no limit.
+var (
+	moduleTimeout     = DefaultModuleTimeout
+	moduleTimeoutLock sync.RWMutex
+)
+
+// SetModuleTimeout sets how long one inspection module may run before its remaining queries are cancelled.
+// 0 disables the limit; single queries are still limited by db.SetQueryTimeout.
+func SetModuleTimeout(d time.Duration) {
+	moduleTimeoutLock.Lock()
+	moduleTimeout = d
+	moduleTimeoutLock.Unlock()
+}
+
 // moduleProcessFunc defines the standard signature for all module processing functions.
-// They take a database connection, language, and pre-fetched full database info (which can be nil).
+// They take a context that cancels their queries, a database connection, language, and pre-fetched full database info (which can be nil).
 // They return slices of report cards, tables, charts, and an error.
-type moduleProcessFunc func(dbConn *sql.DB, lang string, fullDBInfo *db.FullDBInfo) ([]ReportCard, []*ReportTable, []ReportChart, error)
+type moduleProcessFunc func(ctx context.Context, dbConn *sql.DB, lang string, fullDBInfo *db.FullDBInfo) ([]ReportCard, []*ReportTable, []ReportChart, error)
 
 // Adapter for processParametersModule
-func adaptParametersModule(dbConn *sql.DB, lang string, _ *db.FullDBInfo) ([]ReportCard, []*ReportTable, []ReportChart, error) {
+func adaptParametersModule(ctx context.Context, dbConn *sql.DB, lang string, _ *db.FullDBInfo) ([]ReportCard, []*ReportTable, []ReportChart, error) {
 	// Original processParametersModule doesn't expect fullDBInfo, so we ignore it here.
 	// It also returns a concrete []ReportChart which is usually nil for this module.
-	return processParametersModule(dbConn, lang)
+	return processParametersModule(ctx, dbConn, lang)
 }
 
 // Adapter for processDbinfoModule - its signature is already compatible
-// func adaptDbinfoModule(dbConn *sql.DB, lang string, fullDBInfo *db.FullDBInfo) ([]ReportCard, []*ReportTable, []ReportChart, error) {
-// 	 return processDbinfoModule(dbConn, lang, fullDBInfo)
+// func adaptDbinfoModule(ctx context.Context, dbConn *sql.DB, lang string, fullDBInfo *db.FullDBInfo) ([]ReportCard, []*ReportTable, []ReportChart, error) {
+// 	 return processDbinfoModule(ctx, dbConn, lang, fullDBInfo)
 // }
 
-// Adapter for processStorageModule (assuming original doesn't take fullDBInfo)
-func adaptStorageModule(dbConn *sql.DB, lang string, _ *db.FullDBInfo) ([]ReportCard, []*ReportTable, []ReportChart, error) {
-	return processStorageModule(dbConn, lang)
+// Adapter for processStorageModule, which only needs to know whether the database is mounted
+func adaptStorageModule(ctx context.Context, dbConn *sql.DB, lang string, fullDBInfo *db.FullDBInfo) ([]ReportCard, []*ReportTable, []ReportChart, error) {
+	return processStorageModule(ctx, dbConn, lang, fullDBInfo.Mounted())
 }
 
 // Adapter for processSessionsModule (assuming original doesn't take fullDBInfo)
-func adaptSessionsModule(dbConn *sql.DB, lang string, _ *db.FullDBInfo) ([]ReportCard, []*ReportTable, []ReportChart, error) {
-	return processSessionsModule(dbConn, lang)
+func adaptSessionsModule(ctx context.Context, dbConn *sql.DB, lang string, _ *db.FullDBInfo) ([]ReportCard, []*ReportTable, []ReportChart, error) {
+	return processSessionsModule(ctx, dbConn, lang)
 }
 
 // Adapter for processObjectsModule
-func adaptObjectsModule(dbConn *sql.DB, lang string, _ *db.FullDBInfo) ([]ReportCard, []*ReportTable, []ReportChart, error) {
-	return processObjectsModule(dbConn, lang)
+func adaptObjectsModule(ctx context.Context, dbConn *sql.DB, lang string, _ *db.FullDBInfo) ([]ReportCard, []*ReportTable, []ReportChart, error) {
+	return processObjectsModule(ctx, dbConn, lang)
 }
 
 // Adapter for processPerformanceModule (assuming original doesn't take fullDBInfo)
-func adaptPerformanceModule(dbConn *sql.DB, lang string, _ *db.FullDBInfo) ([]ReportCard, []*ReportTable, []ReportChart, error) {
-	return processPerformanceModule(dbConn, lang)
+func adaptPerformanceModule(ctx context.Context, dbConn *sql.DB, lang string, _ *db.FullDBInfo) ([]ReportCard, []*ReportTable, []ReportChart, error) {
+	return processPerformanceModule(ctx, dbConn, lang)
 }
 
 // Adapter for processSecurityModule - its signature is already compatible
-// func adaptSecurityModule(dbConn *sql.DB, lang string, fullDBInfo *db.FullDBInfo) ([]ReportCard, []*ReportTable, []ReportChart, error) {
-// 	 return processSecurityModule(dbConn, lang, fullDBInfo)
+// func adaptSecurityModule(ctx context.Context, dbConn *sql.DB, lang string, fullDBInfo *db.FullDBInfo) ([]ReportCard, []*ReportTable, []ReportChart, error) {
+// 	 return processSecurityModule(ctx, dbConn, lang, fullDBInfo)
 // }
 
-// Adapter for processBackupModule (assuming original doesn't take fullDBInfo)
-func adaptBackupModule(dbConn *sql.DB, lang string, _ *db.FullDBInfo) ([]ReportCard, []*ReportTable, []ReportChart, error) {
-	return processBackupModule(dbConn, lang)
+// Adapter for processBackupModule, which only needs to know whether the database is mounted
+func adaptBackupModule(ctx context.Context, dbConn *sql.DB, lang string, fullDBInfo *db.FullDBInfo) ([]ReportCard, []*ReportTable, []ReportChart, error) {
+	return processBackupModule(ctx, dbConn, lang, fullDBInfo.Mounted())
 }
 
 // moduleInfo holds information about a module, including its name and processing function.
@@ -62,56 +83,88 @@ type moduleInfo struct {
 	processor moduleProcessFunc
 	titleFunc func(lang string) string // Optional: for modules with specific titles
 	icon      string                   // Optional: for module icon
+	// dictionary marks modules built entirely on data dictionary (DBA_*) views; they are skipped while the database is only MOUNTED.
+	dictionary bool
+	// views are the views the module queries, checked by /api/validate and granted by the GRANT script.
+	// Without optionalViews (ASM, ASH, fallbacks) the module still runs, only the related parts are left out.
+	views         []string
+	optionalViews []string
 }
 
+// Views of the modules that share them; dbinfo also runs before every inspection.
+var (
+	parameterViews = []string{"v$parameter"}
+	dbinfoViews    = []string{"gv$instance", "v$instance", "v$database", "nls_database_parameters"}
+)
+
 // moduleProcessors maps inspection item keys to their respective moduleInfo.
 var moduleProcessors = map[string]moduleInfo{
 	"params": {
 		nameFunc:  func(lang string) string { return langText("数据库参数", "Key Database Parameters", "主要なデータベースパラメータ", lang) },
 		processor: adaptParametersModule,
+		views:     parameterViews,
 	},
 	"parameters": { // Alias for params
 		nameFunc:  func(lang string) string { return langText("数据库参数", "Key Database Parameters", "主要なデータベースパラメータ", lang) },
 		processor: adaptParametersModule,
+		views:     parameterViews,
 	},
 	"dbinfo": {
 		nameFunc:  func(lang string) string { return langText("基本信息", "Basic Info", "基本情報", lang) },
 		processor: processDbinfoModule, // Assumes processDbinfoModule is compatible or adapted
+		views:     dbinfoViews,
 	},
 	"storage": {
 		nameFunc:  func(lang string) string { return langText("存储信息", "Storage Info", "ストレージ情報", lang) },
 		processor: adaptStorageModule,
+		views: []string{"v$controlfile", "v$log", "v$logfile", "v$archived_log", "v$temp_extent_pool",
+			"dba_data_files", "dba_temp_files", "dba_tablespaces", "dba_free_space"},
+		optionalViews: []string{"v$asm_diskgroup"},
 	},
 	"sessions": {
-		nameFunc:  func(lang string) string { return langText("会话详情", "Session Details", "セッション詳細", lang) },
-		processor: adaptSessionsModule,
+		nameFunc:      func(lang string) string { return langText("会话详情", "Session Details", "セッション詳細", lang) },
+		processor:     adaptSessionsModule,
+		views:         []string{"gv$session"},
+		optionalViews: []string{"gv$active_session_history"}, // Diagnostics Pack
 	},
 	"objects": {
 		nameFunc: func(lang string) string { return langText("数据库对象", "Database Objects", "データベースオブジェクト", lang) },
 		titleFunc: func(lang string) string {
 			return langText("数据库对象统计与状态", "Database Objects Statistics & Status", "データベースオブジェクトの統計とステータス", lang)
 		},
-		icon:      "fas fa-cube",
-		processor: adaptObjectsModule,
+		icon:       "fas fa-cube",
+		processor:  adaptObjectsModule,
+		dictionary: true,
+		views:      []string{"dba_objects", "dba_segments"},
 	},
 	"performance": {
-		nameFunc:  func(lang string) string { return langText("数据库性能", "Database Performance", "データベースのパフォーマンス", lang) },
-		processor: adaptPerformanceModule,
+		nameFunc:   func(lang string) string { return langText("数据库性能", "Database Performance", "データベースのパフォーマンス", lang) },
+		processor:  adaptPerformanceModule,
+		dictionary: true, // DBA_HIST_SYSMETRIC_SUMMARY
+		views:      []string{"dba_hist_sysmetric_summary"},
 	},
 	"security": {
-		nameFunc:  func(lang string) string { return langText("安全配置", "Security Configuration", "セキュリティ構成", lang) },
-		processor: processSecurityModule, // Assumes processSecurityModule is compatible or adapted
+		nameFunc:   func(lang string) string { return langText("安全配置", "Security Configuration", "セキュリティ構成", lang) },
+		processor:  processSecurityModule, // Assumes processSecurityModule is compatible or adapted
+		dictionary: true,
+		views:      []string{"dba_users", "dba_profiles", "dba_roles", "dba_role_privs", "dba_sys_privs", "role_role_privs"},
 	},
 	"backup": {
 		nameFunc:  func(lang string) string { return langText("备份与恢复", "Backup & Recovery", "バックアップとリカバリ", lang) },
 		processor: adaptBackupModule,
+		views: []string{"v$database", "v$parameter", "v$rman_backup_job_details", "v$flashback_database_log",
+			"dba_recyclebin", "dba_datapump_jobs"},
+		optionalViews: []string{"v$backup_set"}, // Fallback when the RMAN job details are unavailable
 	},
 }
 
 // ProcessInspectionItem processes a single inspection item and returns a report module.
 // The fullDBInfo parameter contains comprehensive database information pre-fetched from the dbinfo module for reference by other modules.
 // If fullDBInfo is nil (e.g., an error occurred while fetching dbinfo itself), the function will still attempt to process, but modules dependent on fullDBInfo may be affected.
-func ProcessInspectionItem(item string, dbConn *sql.DB, lang string, fullDBInfo *db.FullDBInfo) (ReportModule, error) {
+// The thresholds are the limits the module's findings rules are evaluated against.
+// The module's queries are cancelled with ctx or after the module timeout; queries that timed out are
+// recorded as the module's error, next to the results that were collected.
+func ProcessInspectionItem(ctx context.Context, item string, dbConn *sql.DB, lang string, fullDBInfo *db.FullDBInfo, thresholds FindingThresholds) (ReportModule, error) {
 	module := ReportModule{ID: item, Cards: []ReportCard{}} // Initialize module
 
 	pInfo, ok := moduleProcessors[item]
@@ -130,12 +183,21 @@ func ProcessInspectionItem(item string, dbConn *sql.DB, lang string, fullDBInfo
 		module.Icon = pInfo.icon
 	}
 
+	if pInfo.dictionary && fullDBInfo.Mounted() {
+		logger.Infof("Skipping module %s: the database is only MOUNTED.", item)
+		module.Cards = append(module.Cards, mountedSkipCard(module.Name, lang))
+		module.HealthScore = moduleHealthScore(&module)
+		return module, nil
+	}
+
 	// Log before calling the processor, especially for modules like backup that might take time
 	if item == "backup" { // Specific logging for backup or other long-running modules
 		logger.Infof("Starting to delegate processing for module %s...", item)
 	}
 
-	cards, tables, charts, err := pInfo.processor(dbConn, lang, fullDBInfo)
+	moduleCtx, cancel := moduleContext(ctx, item)
+	defer cancel()
+	cards, tables, charts, err := pInfo.processor(moduleCtx, dbConn, lang, fullDBInfo)
 
 	module.Cards = append(module.Cards, cards...)
 	module.Tables = append(module.Tables, tables...)
@@ -144,6 +206,8 @@ func ProcessInspectionItem(item string, dbConn *sql.DB, lang string, fullDBInfo
 	if err != nil {
 		logger.Errorf("Error processing module %s: %v", item, err) // Generic error logging
 		module.Error = err.Error()                  // Store error message in module
+		evaluateFindings(&module, lang, fullDBInfo, thresholds)
+		module.HealthScore = moduleHealthScore(&module)
 		// Note: The individual processor or its adapter is responsible for adding specific error cards if needed.
 		// For critical errors that should halt further processing for this module, the processor should return the error.
 		// If the error is not nil, the caller (InspectHandler) might decide how to proceed globally.
@@ -155,9 +219,46 @@ func ProcessInspectionItem(item string, dbConn *sql.DB, lang string, fullDBInfo
 		return module, err
 	}
 
+	// Most processors show failed queries as cards only; timeouts must still mark the module as failed.
+	if timeoutErr := moduleTimeoutError(moduleCtx, lang); timeoutErr != nil {
+		logger.Warnf("Module %s did not complete: %v", item, timeoutErr)
+		module.Error = timeoutErr.Error()
+	}
+
+	evaluateFindings(&module, lang, fullDBInfo, thresholds)
+	module.HealthScore = moduleHealthScore(&module)
 	return module, nil
 }
 
+// moduleContext derives the context of one module run from ctx, limited by the module timeout,
+// recording the queries that time out and tagging the sessions with the module as their ACTION.
+func moduleContext(ctx context.Context, item string) (context.Context, context.CancelFunc) {
+	ctx = db.WithAction(db.TrackTimeouts(ctx), item)
+	moduleTimeoutLock.RLock()
+	d := moduleTimeout
+	moduleTimeoutLock.RUnlock()
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, d, fmt.Errorf("module %s timed out after %s", item, d))
+}
+
+// moduleTimeoutError describes why a module run did not complete: its context ended, or some of its queries timed out.
+func moduleTimeoutError(moduleCtx context.Context, lang string) error {
+	if moduleCtx.Err() != nil {
+		return fmt.Errorf(langText("模块未完成: %v", "Module did not complete: %v", "モジュールが完了しませんでした: %v", lang), context.Cause(moduleCtx))
+	}
+	timeouts := db.TimedOutQueries(moduleCtx)
+	if len(timeouts) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(timeouts))
+	for _, err := range timeouts {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Errorf(langText("%d 个查询超时: %s", "%d queries timed out: %s", "%d 件のクエリがタイムアウトしました: %s", lang), len(timeouts), strings.Join(msgs, "; "))
+}
+
 // langText is a helper function for selecting text based on language.
 // In a real project, this function might be located in a shared utils or i18n package.
 func langText(zhText, enText, jpText, lang string) string {
@@ -179,6 +280,14 @@ func formatNullInt64AsGB(ni sql.NullInt64) string {
 	return "N/A"
 }
 
+// mountedSkipCard is a helper function to create the card shown in place of data that needs an open database.
+func mountedSkipCard(title string, lang string) ReportCard {
+	return ReportCard{
+		Title: title,
+		Value: langText("已跳过：数据库处于 MOUNTED 状态，数据字典视图不可用", "Skipped: the database is MOUNTED and its data dictionary views are not available", "スキップ: データベースは MOUNTED 状態のため、データディクショナリビューは使用できません", lang),
+	}
+}
+
 // cardFromError is a helper function to create a standard error card from an error.
 func cardFromError(titleZh, titleEn, titleJp string, err error, lang string) ReportCard {
 	return ReportCard{
@@ -0,0 +1,351 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+)
+
+// ReportDiff is the structured comparison of two reports, typically of the same database.
+type ReportDiff struct {
+	Base         ReportSummary `json:"base"`
+	Target       ReportSummary `json:"target"`
+	SameDatabase bool          `json:"sameDatabase"`
+	Modules      []ModuleDiff  `json:"modules"`
+	ChangeCount  int           `json:"changeCount"` // Total number of added, removed and changed rows and cards
+}
+
+// ModuleDiff lists the differences found in one module.
+type ModuleDiff struct {
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	Presence    string       `json:"presence"` // "both", "added" (only in target) or "removed" (only in base)
+	Cards       []CardChange `json:"cards,omitempty"`
+	Tables      []TableDiff  `json:"tables,omitempty"`
+	ChangeCount int          `json:"changeCount"`
+}
+
+// CardChange is a card whose value differs between the two reports.
+type CardChange struct {
+	Title  string `json:"title"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// TableDiff lists the row-level differences of one table.
+type TableDiff struct {
+	Key     string      `json:"key"`
+	Name    string      `json:"name"`
+	Headers []string    `json:"headers"`
+	Added   [][]string  `json:"added,omitempty"`   // Rows only in the target report
+	Removed [][]string  `json:"removed,omitempty"` // Rows only in the base report
+	Changed []RowChange `json:"changed,omitempty"`
+}
+
+// RowChange is a row present in both reports whose compared columns differ.
+type RowChange struct {
+	Key     string   `json:"key"`     // Values of the key columns joined with " / "
+	Columns []string `json:"columns"` // Headers of the columns that changed
+	Before  []string `json:"before"`
+	After   []string `json:"after"`
+	Delta   string   `json:"delta,omitempty"` // Signed difference of the rule's numeric column, e.g. "+4.20"
+}
+
+// tableDiffRule says how rows of a table are matched and compared.
+// Tables without a rule are compared as whole rows (added/removed only).
+type tableDiffRule struct {
+	keyColumns     []int // Columns identifying a row
+	compareColumns []int // Columns whose change is reported; empty means rows are only added or removed
+	deltaColumn    int   // Numeric column whose difference is reported (-1 for none)
+	skip           bool  // Volatile tables whose rows differ on every run
+}
+
+var tableDiffRules = map[string]tableDiffRule{
+	"parameters":             {keyColumns: []int{0}, compareColumns: []int{1}, deltaColumn: -1},
+	"instances":              {keyColumns: []int{0, 1}, compareColumns: []int{2, 3, 4, 5}, deltaColumn: -1},
+	"control_files":          {keyColumns: []int{0}, compareColumns: []int{1}, deltaColumn: -1},
+	"redo_logs":              {keyColumns: []int{0, 1}, compareColumns: []int{2, 3}, deltaColumn: -1},
+	"tablespaces":            {keyColumns: []int{1}, compareColumns: []int{0, 5, 6, 7}, deltaColumn: 7},
+	"datafiles":              {keyColumns: []int{1}, compareColumns: []int{3, 4, 5}, deltaColumn: 3},
+	"asm_diskgroups":         {keyColumns: []int{0}, compareColumns: []int{1, 3, 4}, deltaColumn: 3},
+	"object_type_counts":     {keyColumns: []int{0, 1}, compareColumns: []int{2}, deltaColumn: 2},
+	"top_segments":           {keyColumns: []int{0, 1, 2}, compareColumns: []int{3}, deltaColumn: 3},
+	"invalid_objects":        {keyColumns: []int{0, 1, 2}, deltaColumn: -1},
+	"users":                  {keyColumns: []int{0}, compareColumns: []int{1, 6}, deltaColumn: -1},
+	"profiles":               {keyColumns: []int{0, 1}, compareColumns: []int{2}, deltaColumn: -1},
+	"roles":                  {keyColumns: []int{0}, compareColumns: []int{1}, deltaColumn: -1},
+	"privileged_role_grants": {keyColumns: []int{0, 1}, compareColumns: []int{2}, deltaColumn: -1},
+	"recyclebin":             {keyColumns: []int{0, 1}, deltaColumn: -1},
+	"archived_logs":          {skip: true},
+	"rman_jobs":              {skip: true},
+	"datapump_jobs":          {skip: true},
+	"session_overview":       {skip: true},
+	"session_waits":          {skip: true},
+}
+
+// DiffReports compares every module of base and target.
+// Tables are matched by Key (by Name for reports stored before keys existed), cards by title
+// when both reports use the same language.
+func DiffReports(baseID string, base ReportData, targetID string, target ReportData) ReportDiff {
+	diff := ReportDiff{
+		Base:         summarizeReport(baseID, base),
+		Target:       summarizeReport(targetID, target),
+		SameDatabase: base.DBConnection == target.DBConnection && base.DBName == target.DBName,
+	}
+
+	baseModules := make(map[string]ReportModule, len(base.Modules))
+	for _, m := range base.Modules {
+		baseModules[m.ID] = m
+	}
+	seen := make(map[string]bool)
+	for _, t := range target.Modules {
+		seen[t.ID] = true
+		b, ok := baseModules[t.ID]
+		var md ModuleDiff
+		if ok {
+			md = diffModule(b, t, base.Lang == target.Lang)
+		} else {
+			md = ModuleDiff{ID: t.ID, Name: t.Name, Presence: "added"}
+		}
+		diff.Modules = append(diff.Modules, md)
+		diff.ChangeCount += md.ChangeCount
+	}
+	for _, b := range base.Modules {
+		if !seen[b.ID] {
+			diff.Modules = append(diff.Modules, ModuleDiff{ID: b.ID, Name: b.Name, Presence: "removed"})
+		}
+	}
+	return diff
+}
+
+func diffModule(base, target ReportModule, compareCards bool) ModuleDiff {
+	md := ModuleDiff{ID: target.ID, Name: target.Name, Presence: "both"}
+
+	if compareCards {
+		before := make(map[string]string, len(base.Cards))
+		for _, c := range base.Cards {
+			before[c.Title] = c.Value
+		}
+		for _, c := range target.Cards {
+			if old, ok := before[c.Title]; ok && old != c.Value {
+				md.Cards = append(md.Cards, CardChange{Title: c.Title, Before: old, After: c.Value})
+			}
+		}
+	}
+
+	baseTables := make(map[string]*ReportTable, len(base.Tables))
+	for _, t := range base.Tables {
+		baseTables[tableIdentity(t)] = t
+	}
+	seen := make(map[string]bool)
+	for _, t := range target.Tables {
+		id := tableIdentity(t)
+		seen[id] = true
+		td, ok := diffTable(baseTables[id], t)
+		if ok {
+			md.Tables = append(md.Tables, td)
+		}
+	}
+	// Tables that disappeared entirely, e.g. the last invalid object was fixed
+	for _, t := range base.Tables {
+		if !seen[tableIdentity(t)] {
+			if td, ok := diffTable(t, nil); ok {
+				md.Tables = append(md.Tables, td)
+			}
+		}
+	}
+
+	md.ChangeCount = len(md.Cards)
+	for _, td := range md.Tables {
+		md.ChangeCount += len(td.Added) + len(td.Removed) + len(td.Changed)
+	}
+	return md
+}
+
+func tableIdentity(t *ReportTable) string {
+	if t.Key != "" {
+		return t.Key
+	}
+	return "name:" + t.Name
+}
+
+// diffTable compares two versions of a table; either may be nil when the table exists on one side only.
+// It returns false when there is nothing to report.
+func diffTable(base, target *ReportTable) (TableDiff, bool) {
+	ref := target
+	if ref == nil {
+		ref = base
+	}
+	rule, hasRule := tableDiffRules[ref.Key]
+	if rule.skip {
+		return TableDiff{}, false
+	}
+	if !hasRule {
+		rule = tableDiffRule{deltaColumn: -1} // whole-row comparison
+	}
+
+	td := TableDiff{Key: ref.Key, Name: ref.Name, Headers: ref.Headers}
+	var baseRows, targetRows [][]string
+	if base != nil {
+		baseRows = base.Rows
+	}
+	if target != nil {
+		targetRows = target.Rows
+	}
+
+	before := make(map[string][]string, len(baseRows))
+	for _, row := range baseRows {
+		before[rowKey(row, rule.keyColumns)] = row
+	}
+	matched := make(map[string]bool, len(targetRows))
+	for _, row := range targetRows {
+		key := rowKey(row, rule.keyColumns)
+		matched[key] = true
+		old, ok := before[key]
+		if !ok {
+			td.Added = append(td.Added, row)
+			continue
+		}
+		var changed []string
+		for _, col := range rule.compareColumns {
+			if cell(old, col) != cell(row, col) {
+				changed = append(changed, headerAt(ref.Headers, col))
+			}
+		}
+		if len(changed) > 0 {
+			td.Changed = append(td.Changed, RowChange{
+				Key:     key,
+				Columns: changed,
+				Before:  old,
+				After:   row,
+				Delta:   numericDelta(cell(old, rule.deltaColumn), cell(row, rule.deltaColumn)),
+			})
+		}
+	}
+	for _, row := range baseRows {
+		if !matched[rowKey(row, rule.keyColumns)] {
+			td.Removed = append(td.Removed, row)
+		}
+	}
+
+	sort.SliceStable(td.Changed, func(i, j int) bool { return td.Changed[i].Key < td.Changed[j].Key })
+	return td, len(td.Added)+len(td.Removed)+len(td.Changed) > 0
+}
+
+// rowKey joins the key columns of row; all columns are used when keyColumns is empty.
+func rowKey(row []string, keyColumns []int) string {
+	if len(keyColumns) == 0 {
+		return strings.Join(row, " / ")
+	}
+	parts := make([]string, 0, len(keyColumns))
+	for _, col := range keyColumns {
+		parts = append(parts, cell(row, col))
+	}
+	return strings.Join(parts, " / ")
+}
+
+func cell(row []string, col int) string {
+	if col < 0 || col >= len(row) {
+		return ""
+	}
+	return row[col]
+}
+
+func headerAt(headers []string, col int) string {
+	if col >= 0 && col < len(headers) {
+		return headers[col]
+	}
+	return fmt.Sprintf("#%d", col+1)
+}
+
+// numericDelta returns after-before formatted with a sign, or "" when either value is not numeric.
+func numericDelta(before, after string) string {
+	b, errB := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(before, "%")), 64)
+	a, errA := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(after, "%")), 64)
+	if errB != nil || errA != nil {
+		return ""
+	}
+	return fmt.Sprintf("%+.2f", a-b)
+}
+
+// loadReportPair reads the base and target query parameters and fetches both reports.
+func loadReportPair(w http.ResponseWriter, r *http.Request) (ReportDiff, bool) {
+	baseID, targetID := r.URL.Query().Get("base"), r.URL.Query().Get("target")
+	if baseID == "" || targetID == "" {
+		http.Error(w, "Both base and target report IDs are required", http.StatusBadRequest)
+		return ReportDiff{}, false
+	}
+	base, ok := loadReport(baseID)
+	if !ok {
+		http.Error(w, fmt.Sprintf("Report %s not found", baseID), http.StatusNotFound)
+		return ReportDiff{}, false
+	}
+	target, ok := loadReport(targetID)
+	if !ok {
+		http.Error(w, fmt.Sprintf("Report %s not found", targetID), http.StatusNotFound)
+		return ReportDiff{}, false
+	}
+	return DiffReports(baseID, base, targetID, target), true
+}
+
+// ReportDiffHandler handles GET /api/report/diff?base=<id>&target=<id> and returns the diff as JSON.
+func ReportDiffHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		diff, ok := loadReportPair(w, r)
+		if !ok {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "diff": diff}); err != nil {
+			logger.Errorf("API Error: failed to encode report diff: %v", err)
+		}
+	}
+}
+
+// DiffPageHandler renders /diff.html?base=<id>&target=<id> in the language of the target report.
+func DiffPageHandler(content fs.FS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		diff, ok := loadReportPair(w, r)
+		if !ok {
+			return
+		}
+		tmpl, err := template.ParseFS(content, "templates/diff.html")
+		if err != nil {
+			http.Error(w, "Template parsing error: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		lang := diff.Target.Lang
+		data := map[string]interface{}{
+			"Diff": diff,
+			"Lang": lang,
+			"Text": map[string]string{
+				"Title":     langText("巡检报告对比", "Inspection Report Comparison", "検査レポート比較", lang),
+				"Base":      langText("基准报告", "Base Report", "比較元レポート", lang),
+				"Target":    langText("目标报告", "Target Report", "比較先レポート", lang),
+				"Changes":   langText("变更数", "Changes", "変更数", lang),
+				"NoChanges": langText("未发现差异", "No differences found", "差分はありません", lang),
+				"Different": langText("注意：两份报告来自不同的数据库", "Note: the two reports are from different databases", "注意：2つのレポートは異なるデータベースのものです", lang),
+				"Added":     langText("新增", "Added", "追加", lang),
+				"Removed":   langText("移除", "Removed", "削除", lang),
+				"Before":    langText("变更前", "Before", "変更前", lang),
+				"After":     langText("变更后", "After", "変更後", lang),
+				"Delta":     langText("差值", "Delta", "差分", lang),
+				"OnlyBase":  langText("仅存在于基准报告", "Only in the base report", "比較元レポートのみ", lang),
+				"OnlyTgt":   langText("仅存在于目标报告", "Only in the target report", "比較先レポートのみ", lang),
+				"Open":      langText("打开", "Open", "開く", lang),
+			},
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := tmpl.Execute(w, data); err != nil {
+			logger.Errorf("Diff page template execution error: %v", err)
+		}
+	}
+}
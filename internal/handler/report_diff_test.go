@@ -0,0 +1,314 @@
+package handler
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// moduleTableHeaders reads the English headers of every keyed ReportTable literal in module_logic*.go,
+// so that the diff fixtures and rules are checked against the columns the modules really produce.
+func moduleTableHeaders(t *testing.T) map[string][]string {
+	t.Helper()
+	files, err := filepath.Glob("module_logic*.go")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no module_logic*.go files: %v", err)
+	}
+	tables := map[string][]string{}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			lit, ok := n.(*ast.CompositeLit)
+			if !ok {
+				return true
+			}
+			if ident, ok := lit.Type.(*ast.Ident); !ok || ident.Name != "ReportTable" {
+				return true
+			}
+			var key string
+			var headers []string
+			for _, elt := range lit.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				switch kv.Key.(*ast.Ident).Name {
+				case "Key":
+					if s, ok := stringLiteral(kv.Value); ok {
+						key = s
+					}
+				case "Headers":
+					list, ok := kv.Value.(*ast.CompositeLit)
+					if !ok {
+						continue
+					}
+					for _, h := range list.Elts {
+						// langText(zh, en, jp, lang) or a plain string
+						if call, ok := h.(*ast.CallExpr); ok && len(call.Args) == 4 {
+							h = call.Args[1]
+						}
+						s, ok := stringLiteral(h)
+						if !ok {
+							t.Fatalf("%s: table %q has a header that is not a literal", fset.Position(h.Pos()), key)
+						}
+						headers = append(headers, s)
+					}
+				}
+			}
+			if key != "" && headers != nil {
+				tables[key] = headers
+			}
+			return true
+		})
+	}
+	return tables
+}
+
+func stringLiteral(e ast.Expr) (string, bool) {
+	lit, ok := e.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+func TestTableDiffRulesMatchModuleHeaders(t *testing.T) {
+	headers := moduleTableHeaders(t)
+	type columns struct {
+		key, compare []string
+		delta        string
+	}
+	want := map[string]columns{
+		"parameters":             {key: []string{"Parameter Name"}, compare: []string{"Value"}},
+		"instances":              {key: []string{"Inst ID", "Instance Name"}, compare: []string{"Host Name", "Version", "Startup Time", "Status"}},
+		"control_files":          {key: []string{"File Path"}, compare: []string{"Size(MB)"}},
+		"redo_logs":              {key: []string{"Group#", "Thread#"}, compare: []string{"Members", "Size(MB)"}},
+		"tablespaces":            {key: []string{"Tablespace Name"}, compare: []string{"Status", "Used(MB)", "Total(MB)", "Used %"}, delta: "Used %"},
+		"datafiles":              {key: []string{"File Name"}, compare: []string{"Size(MB)", "Status", "Autoextend"}, delta: "Size(MB)"},
+		"asm_diskgroups":         {key: []string{"Diskgroup Name"}, compare: []string{"Total Size(MB)", "Used %", "State"}, delta: "Used %"},
+		"object_type_counts":     {key: []string{"Owner", "Object Type"}, compare: []string{"Count"}, delta: "Count"},
+		"top_segments":           {key: []string{"Owner", "Segment Name", "Segment Type"}, compare: []string{"Size (GB)"}, delta: "Size (GB)"},
+		"invalid_objects":        {key: []string{"Owner", "Object Name", "Object Type"}},
+		"users":                  {key: []string{"Username"}, compare: []string{"Status", "Profile"}},
+		"profiles":               {key: []string{"Profile Name", "Resource Name"}, compare: []string{"Limit"}},
+		"roles":                  {key: []string{"Role Name"}, compare: []string{"Authentication Type"}},
+		"privileged_role_grants": {key: []string{"Username", "Granted Role"}, compare: []string{"Admin Option"}},
+		"recyclebin":             {key: []string{"Owner", "Object Name"}},
+	}
+	names := func(h []string, cols []int) []string {
+		var out []string
+		for _, col := range cols {
+			out = append(out, headerAt(h, col))
+		}
+		return out
+	}
+	for key, rule := range tableDiffRules {
+		h, ok := headers[key]
+		if !ok {
+			t.Errorf("diff rule %q has no table in module_logic*.go", key)
+			continue
+		}
+		if rule.skip {
+			continue
+		}
+		w, ok := want[key]
+		if !ok {
+			t.Errorf("diff rule %q is not covered by this test", key)
+			continue
+		}
+		got := columns{key: names(h, rule.keyColumns), compare: names(h, rule.compareColumns)}
+		if rule.deltaColumn >= 0 {
+			got.delta = headerAt(h, rule.deltaColumn)
+		}
+		if !reflect.DeepEqual(got, w) {
+			t.Errorf("diff rule %q uses columns %+v, want %+v (headers %q)", key, got, w, h)
+		}
+	}
+}
+
+// diffFixture builds a table with the headers its module produces; every row must have one value per header.
+func diffFixture(t *testing.T, headers map[string][]string, key string, rows ...[]string) *ReportTable {
+	t.Helper()
+	h, ok := headers[key]
+	if !ok {
+		t.Fatalf("no table %q in module_logic*.go", key)
+	}
+	for _, row := range rows {
+		if len(row) != len(h) {
+			t.Fatalf("%s fixture row %q has %d values for %d headers %q", key, row, len(row), len(h), h)
+		}
+	}
+	return &ReportTable{Key: key, Name: key, Headers: h, Rows: rows}
+}
+
+func TestDiffReports(t *testing.T) {
+	h := moduleTableHeaders(t)
+	user := func(name, status, profile string) []string {
+		return []string{name, status, "N/A", "N/A", "USERS", "TEMP", profile, "2024-01-01 00:00:00", "2025-06-01 08:00:00"}
+	}
+	tablespace := func(status, name, used, total, pct string) []string {
+		return []string{status, name, "PERMANENT", "LOCAL", "AUTO", used, total, pct, "32768.00"}
+	}
+	base := ReportData{
+		Lang: "en", DBName: "ORCL", DBConnection: "db1:1521/ORCL",
+		Modules: []ReportModule{
+			{ID: "parameters", Name: "Parameters", Tables: []*ReportTable{diffFixture(t, h, "parameters",
+				[]string{"processes", "300"},
+				[]string{"open_cursors", "300"},
+				[]string{"memory_target", "0"},
+			)}},
+			{ID: "security", Name: "Security", Tables: []*ReportTable{diffFixture(t, h, "users",
+				user("APP", "OPEN", "DEFAULT"),
+				user("SCOTT", "OPEN", "DEFAULT"),
+				user("REPORTING", "OPEN", "DEFAULT"),
+				user("LEGACY", "EXPIRED & LOCKED", "DEFAULT"),
+			)}},
+			{ID: "storage", Name: "Storage",
+				Cards: []ReportCard{{Title: "Tablespace Count", Value: "2"}, {Title: "Datafile Count", Value: "5"}},
+				Tables: []*ReportTable{
+					diffFixture(t, h, "tablespaces",
+						tablespace("ONLINE", "USERS", "8000.00", "10000.00", "80.00"),
+						tablespace("ONLINE", "TEMP", "10.00", "1000.00", "1.00"),
+					),
+					diffFixture(t, h, "archived_logs", []string{"2025-05-31", "40", "8000"}),
+				}},
+			{ID: "sessions", Name: "Sessions", Tables: []*ReportTable{
+				diffFixture(t, h, "session_overview", []string{"1", "APP", "app1", "ACTIVE", "12"}),
+				diffFixture(t, h, "session_waits", []string{"db file sequential read", "3"}),
+			}},
+			{ID: "backup", Name: "Backup"},
+		},
+	}
+	target := ReportData{
+		Lang: "en", DBName: "ORCL", DBConnection: "db1:1521/ORCL",
+		Modules: []ReportModule{
+			{ID: "parameters", Name: "Parameters", Tables: []*ReportTable{diffFixture(t, h, "parameters",
+				[]string{"processes", "500"},
+				[]string{"open_cursors", "300"},
+				[]string{"sga_target", "4G"},
+			)}},
+			{ID: "security", Name: "Security", Tables: []*ReportTable{diffFixture(t, h, "users",
+				user("APP", "LOCKED(TIMED)", "DEFAULT"),
+				user("SCOTT", "OPEN", "DEFAULT"),
+				user("REPORTING", "OPEN", "APP_PROFILE"),
+				user("NEWUSER", "OPEN", "DEFAULT"),
+			)}},
+			{ID: "storage", Name: "Storage",
+				Cards: []ReportCard{{Title: "Tablespace Count", Value: "2"}, {Title: "Datafile Count", Value: "6"}},
+				Tables: []*ReportTable{
+					diffFixture(t, h, "tablespaces",
+						tablespace("ONLINE", "USERS", "8420.00", "10000.00", "84.20"),
+						tablespace("ONLINE", "TEMP", "10.00", "1000.00", "1.00"),
+					),
+					diffFixture(t, h, "archived_logs", []string{"2025-06-01", "55", "11000"}),
+				}},
+			{ID: "sessions", Name: "Sessions", Tables: []*ReportTable{
+				diffFixture(t, h, "session_overview", []string{"1", "APP", "app1", "ACTIVE", "30"}),
+				diffFixture(t, h, "session_waits", []string{"log file sync", "8"}),
+			}},
+			{ID: "objects", Name: "Objects", Tables: []*ReportTable{diffFixture(t, h, "invalid_objects",
+				[]string{"APP", "PKG_BILLING", "PACKAGE BODY", "2024-01-01", "2025-06-01"},
+			)}},
+		},
+	}
+
+	diff := DiffReports("base", base, "target", target)
+	if !diff.SameDatabase {
+		t.Error("SameDatabase = false for the same connection and name")
+	}
+	modules := map[string]ModuleDiff{}
+	var order []string
+	for _, md := range diff.Modules {
+		modules[md.ID] = md
+		order = append(order, md.ID+":"+md.Presence)
+	}
+	if want := []string{"parameters:both", "security:both", "storage:both", "sessions:both", "objects:added", "backup:removed"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("modules = %q, want %q", order, want)
+	}
+
+	params := modules["parameters"].Tables
+	if len(params) != 1 {
+		t.Fatalf("parameter tables = %+v", params)
+	}
+	if got := params[0]; !reflect.DeepEqual(got.Added, [][]string{{"sga_target", "4G"}}) ||
+		!reflect.DeepEqual(got.Removed, [][]string{{"memory_target", "0"}}) ||
+		len(got.Changed) != 1 || got.Changed[0].Key != "processes" ||
+		!reflect.DeepEqual(got.Changed[0].Columns, []string{"Value"}) || got.Changed[0].Delta != "" {
+		t.Errorf("parameter diff = %+v", got)
+	}
+
+	users := modules["security"].Tables[0]
+	var changes []string
+	for _, c := range users.Changed {
+		changes = append(changes, c.Key+": "+c.Before[1]+" -> "+c.After[1])
+	}
+	if want := []string{"APP: OPEN -> LOCKED(TIMED)", "REPORTING: OPEN -> OPEN"}; !reflect.DeepEqual(changes, want) {
+		t.Errorf("user changes = %q, want %q", changes, want)
+	}
+	if len(users.Changed) == 2 && (!reflect.DeepEqual(users.Changed[0].Columns, []string{"Status"}) || !reflect.DeepEqual(users.Changed[1].Columns, []string{"Profile"})) {
+		t.Errorf("changed user columns = %q, %q", users.Changed[0].Columns, users.Changed[1].Columns)
+	}
+	if len(users.Added) != 1 || users.Added[0][0] != "NEWUSER" || len(users.Removed) != 1 || users.Removed[0][0] != "LEGACY" {
+		t.Errorf("added users %q, removed users %q", users.Added, users.Removed)
+	}
+
+	storage := modules["storage"]
+	if !reflect.DeepEqual(storage.Cards, []CardChange{{Title: "Datafile Count", Before: "5", After: "6"}}) {
+		t.Errorf("storage cards = %+v", storage.Cards)
+	}
+	if len(storage.Tables) != 1 || storage.Tables[0].Key != "tablespaces" {
+		t.Fatalf("storage tables = %+v, want only tablespaces with archived logs skipped", storage.Tables)
+	}
+	ts := storage.Tables[0]
+	if len(ts.Changed) != 1 || ts.Changed[0].Key != "USERS" || ts.Changed[0].Delta != "+4.20" ||
+		!reflect.DeepEqual(ts.Changed[0].Columns, []string{"Used(MB)", "Used %"}) || ts.Added != nil || ts.Removed != nil {
+		t.Errorf("tablespace diff = %+v", ts)
+	}
+
+	if tables := modules["sessions"].Tables; len(tables) != 0 || modules["sessions"].ChangeCount != 0 {
+		t.Errorf("session tables are volatile and must be skipped, got %+v", tables)
+	}
+
+	// 1 changed, 1 added and 1 removed parameter; 2 changed, 1 added and 1 removed user; 1 card and 1 tablespace
+	if diff.ChangeCount != 9 {
+		t.Errorf("ChangeCount = %d, want 9", diff.ChangeCount)
+	}
+}
+
+func TestDiffTableWithoutRule(t *testing.T) {
+	base := &ReportTable{Name: "Custom", Headers: []string{"A", "B"}, Rows: [][]string{{"1", "x"}, {"2", "y"}}}
+	target := &ReportTable{Name: "Custom", Headers: []string{"A", "B"}, Rows: [][]string{{"1", "x"}, {"2", "z"}}}
+	td, ok := diffTable(base, target)
+	if !ok || !reflect.DeepEqual(td.Added, [][]string{{"2", "z"}}) || !reflect.DeepEqual(td.Removed, [][]string{{"2", "y"}}) || td.Changed != nil {
+		t.Errorf("whole-row diff = %+v, %v", td, ok)
+	}
+
+	// A table that disappeared reports all its rows as removed, unless it is volatile
+	gone := &ReportTable{Key: "invalid_objects", Headers: moduleTableHeaders(t)["invalid_objects"], Rows: [][]string{{"APP", "V_X", "VIEW", "", ""}}}
+	if td, ok := diffTable(gone, nil); !ok || len(td.Removed) != 1 {
+		t.Errorf("removed table diff = %+v, %v", td, ok)
+	}
+	if _, ok := diffTable(&ReportTable{Key: "rman_jobs", Rows: [][]string{{"1"}}}, nil); ok {
+		t.Error("skipped table reported")
+	}
+
+	for _, tt := range []struct{ before, after, want string }{
+		{"80.00", "84.20", "+4.20"},
+		{"84.2%", "80%", "-4.20"},
+		{"10", "10", "+0.00"},
+		{"N/A", "5", ""},
+	} {
+		if got := numericDelta(tt.before, tt.after); got != tt.want {
+			t.Errorf("numericDelta(%q, %q) = %q, want %q", tt.before, tt.after, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+)
+
+// ReportExporter renders a report into a downloadable file format.
+type ReportExporter struct {
+	ContentType string                                   // MIME type sent with the download
+	Extension   string                                   // File name extension without the dot
+	Export      func(w io.Writer, data ReportData) error // Writes the complete file to w
+}
+
+// reportExporters maps the format names accepted by /api/report/export and the CLI to their exporters.
+var reportExporters = map[string]ReportExporter{
+	"json": {ContentType: "application/json; charset=utf-8", Extension: "json", Export: exportJSON},
+	"md":   {ContentType: "text/markdown; charset=utf-8", Extension: "md", Export: exportMarkdown},
+	"pdf":  {ContentType: "application/pdf", Extension: "pdf", Export: exportPDF},
+	"txt":  {ContentType: "text/plain; charset=utf-8", Extension: "txt", Export: exportText},
+	"xlsx": {ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", Extension: "xlsx", Export: exportXLSX},
+}
+
+// ExportFormats returns the names of the available export formats, sorted.
+func ExportFormats() []string {
+	formats := make([]string, 0, len(reportExporters))
+	for name := range reportExporters {
+		formats = append(formats, name)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// ExportReport writes reportData to w in the given export format.
+func ExportReport(w io.Writer, format string, reportData ReportData) error {
+	exporter, ok := reportExporters[format]
+	if !ok {
+		return fmt.Errorf("unsupported export format %q (available: %s)", format, strings.Join(ExportFormats(), ", "))
+	}
+	return exporter.Export(w, reportData)
+}
+
+// WriteReport writes reportData to w in any output format. "html" is the self-contained offline page,
+// which needs the embedded static files in content; the other formats are the export formats.
+func WriteReport(w io.Writer, content fs.FS, format string, reportData ReportData) error {
+	if format == "html" {
+		return RenderReportOfflineHTML(w, content, reportData)
+	}
+	return ExportReport(w, format, reportData)
+}
+
+// exportJSON writes the report as an indented, versioned ReportDocument (see docs/report-schema.json).
+func exportJSON(w io.Writer, data ReportData) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(NewReportDocument(data))
+}
+
+// maxImportSize limits the size of uploaded report documents.
+const maxImportSize = 64 << 20
+
+// ImportReportHandler handles POST /api/reports/import. The body is a JSON report document, either sent
+// directly or as the "file" field of a multipart form. The report is stored under a new ID.
+func ImportReportHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+		var body io.Reader = r.Body
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				sendJSONError(w, "Missing report file", http.StatusBadRequest)
+				return
+			}
+			defer file.Close()
+			body = file
+		}
+
+		reportData, err := ReadReportDocument(body)
+		if err != nil {
+			logger.Warnf("Rejected report import: %v", err)
+			sendJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		reportID := generateReportID("import", reportData.DBConnection, reportData.GeneratedAt)
+		if err := StoreReport(reportID, reportData); err != nil {
+			logger.Errorf("Failed to store imported report %s: %v", reportID, err)
+			sendJSONError(w, "Failed to store report", http.StatusInternalServerError)
+			return
+		}
+		logger.Infof("Imported report %s (%s, generated %s)", reportID, reportData.DBConnection, reportData.GeneratedAt)
+		sendJSONResponse(w, map[string]interface{}{
+			"success":  true,
+			"reportId": reportID,
+		}, http.StatusOK)
+	}
+}
+
+// unsafeFileNameChars matches characters that are not allowed in download file names.
+var unsafeFileNameChars = regexp.MustCompile(`[\\/:*?"<>|\s]+`)
+
+// ExportFileName builds the download name the same way the browser export does:
+// <db name>_<connection>_<yyyymmdd_hhmm>.<ext>.
+func ExportFileName(data ReportData, ext string) string {
+	stamp := "report"
+	if t, ok := reportTime(data.GeneratedAt); ok {
+		stamp = t.Format("20060102_1504")
+	}
+	name := fmt.Sprintf("%s_%s_%s.%s", data.DBName, data.DBConnection, stamp, ext)
+	return unsafeFileNameChars.ReplaceAllString(name, "_")
+}
+
+// ExportReportHandler handles GET /api/report/export?id=...&format=pdf and returns the report as a download.
+// format=html returns the self-contained offline page, which needs the embedded static files in content.
+func ExportReportHandler(content fs.FS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reportID := r.URL.Query().Get("id")
+		format := strings.ToLower(r.URL.Query().Get("format"))
+		if reportID == "" || format == "" {
+			http.Error(w, "Missing report ID or format", http.StatusBadRequest)
+			return
+		}
+		exporter, ok := reportExporters[format]
+		if format == "html" {
+			exporter, ok = ReportExporter{
+				ContentType: "text/html; charset=utf-8",
+				Extension:   "html",
+				Export: func(w io.Writer, data ReportData) error {
+					return RenderReportOfflineHTML(w, content, data)
+				},
+			}, true
+		}
+		if !ok {
+			http.Error(w, fmt.Sprintf("Unsupported export format %q", format), http.StatusBadRequest)
+			return
+		}
+		reportData, exists := loadReport(reportID)
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+
+		// Render into memory first so that a failure can still be reported as an HTTP error.
+		var buf bytes.Buffer
+		if err := exporter.Export(&buf, reportData); err != nil {
+			logger.Errorf("Failed to export report %s as %s: %v", reportID, format, err)
+			http.Error(w, "Failed to export report", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", exporter.ContentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", ExportFileName(reportData, exporter.Extension)))
+		if _, err := buf.WriteTo(w); err != nil {
+			logger.Errorf("Failed to send exported report %s: %v", reportID, err)
+		}
+	}
+}
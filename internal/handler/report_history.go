@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+)
+
+// ReportFilter selects stored reports. Empty fields match everything.
+type ReportFilter struct {
+	DBConnection string    // Case-insensitive substring of ip:port/service
+	BusinessName string    // Case-insensitive substring
+	DBName       string    // Case-insensitive substring
+	Module       string    // Module ID that must be present in the report, e.g. "storage"
+	From         time.Time // Reports generated at or after this time
+	To           time.Time // Reports generated at or before this time
+}
+
+// Matches reports whether the summary satisfies every condition of the filter.
+func (f ReportFilter) Matches(s ReportSummary) bool {
+	if !containsFold(s.DBConnection, f.DBConnection) ||
+		!containsFold(s.BusinessName, f.BusinessName) ||
+		!containsFold(s.DBName, f.DBName) {
+		return false
+	}
+	if f.Module != "" {
+		found := false
+		for _, m := range s.Modules {
+			if strings.EqualFold(m, f.Module) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if !f.From.IsZero() || !f.To.IsZero() {
+		t, ok := reportTime(s.GeneratedAt)
+		if !ok {
+			return false
+		}
+		if !f.From.IsZero() && t.Before(f.From) {
+			return false
+		}
+		if !f.To.IsZero() && t.After(f.To) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(s, substr string) bool {
+	return substr == "" || strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// parseFilterTime accepts "2006-01-02", "2006-01-02 15:04:05" or RFC 3339.
+// A bare date used as an upper bound covers the whole day.
+func parseFilterTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		if endOfDay {
+			t = t.Add(24*time.Hour - time.Second)
+		}
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD, YYYY-MM-DD HH:MM:SS or RFC 3339)", value)
+}
+
+// SearchReports returns the summaries of the stored reports matching filter, newest first.
+func SearchReports(filter ReportFilter) ([]ReportSummary, error) {
+	summaries, err := Reports().List()
+	if err != nil {
+		return nil, err
+	}
+	matched := make([]ReportSummary, 0, len(summaries))
+	for _, s := range summaries {
+		if filter.Matches(s) {
+			matched = append(matched, s)
+		}
+	}
+	return matched, nil
+}
+
+// ListReportsHandler handles GET /api/reports.
+// Query parameters: dbConnection, business, dbName, module, from, to, limit.
+func ListReportsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		filter := ReportFilter{
+			DBConnection: strings.TrimSpace(q.Get("dbConnection")),
+			BusinessName: strings.TrimSpace(q.Get("business")),
+			DBName:       strings.TrimSpace(q.Get("dbName")),
+			Module:       strings.TrimSpace(q.Get("module")),
+		}
+		var err error
+		if filter.From, err = parseFilterTime(q.Get("from"), false); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if filter.To, err = parseFilterTime(q.Get("to"), true); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		limit := 0
+		if v := q.Get("limit"); v != "" {
+			if limit, err = strconv.Atoi(v); err != nil || limit < 0 {
+				http.Error(w, "Invalid limit", http.StatusBadRequest)
+				return
+			}
+		}
+
+		reports, err := SearchReports(filter)
+		if err != nil {
+			logger.Errorf("API Error: failed to list reports: %v", err)
+			http.Error(w, "Failed to list reports", http.StatusInternalServerError)
+			return
+		}
+		total := len(reports)
+		if limit > 0 && len(reports) > limit {
+			reports = reports[:limit]
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"total":   total,
+			"reports": reports,
+		}); err != nil {
+			logger.Errorf("API Error: failed to encode report list: %v", err)
+		}
+	}
+}
+
+// HistoryHandler renders the report history page; the list itself is loaded from /api/reports.
+func HistoryHandler(content fs.FS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tmpl, err := template.ParseFS(content, "templates/history.html")
+		if err != nil {
+			http.Error(w, "Template parsing error: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := tmpl.Execute(w, nil); err != nil {
+			logger.Errorf("History page template execution error: %v", err)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+)
+
+// keyCardsPerModule limits how many cards of each module are quoted in notification messages.
+const keyCardsPerModule = 6
+
+// ReportNotifier delivers finished reports, e.g. by email.
+type ReportNotifier interface {
+	Name() string                                  // Short name used in log messages, e.g. "smtp"
+	Notify(reportID string, data ReportData) error // Called once per finished inspection
+}
+
+var (
+	reportNotifiers     []ReportNotifier
+	reportNotifiersLock sync.RWMutex
+)
+
+// AddReportNotifier registers n to be told about every finished inspection.
+func AddReportNotifier(n ReportNotifier) {
+	reportNotifiersLock.Lock()
+	reportNotifiers = append(reportNotifiers, n)
+	reportNotifiersLock.Unlock()
+}
+
+// NotifyReportFinished hands a finished report to every registered notifier and waits for them.
+// Delivery failures are logged; they never fail the inspection itself.
+func NotifyReportFinished(reportID string, data ReportData) {
+	reportNotifiersLock.RLock()
+	notifiers := append([]ReportNotifier(nil), reportNotifiers...)
+	reportNotifiersLock.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, n := range notifiers {
+		wg.Add(1)
+		go func(n ReportNotifier) {
+			defer wg.Done()
+			if err := n.Notify(reportID, data); err != nil {
+				logger.Errorf("Notification %s for report %s failed: %v", n.Name(), reportID, err)
+			}
+		}(n)
+	}
+	wg.Wait()
+}
+
+// InspectionFailure describes an inspection that ended without a report, e.g. because the
+// database could not be reached. It never carries credentials.
+type InspectionFailure struct {
+	BusinessName string
+	DBConnection string // host:port/service, or host:port:SID
+	Lang         string
+	Error        string
+}
+
+// NewInspectionFailure describes the failed inspection requested by req.
+func NewInspectionFailure(req *DBConnectionRequest, err error) InspectionFailure {
+	return InspectionFailure{
+		BusinessName: req.Business,
+		DBConnection: req.connectionString(),
+		Lang:         req.Lang,
+		Error:        err.Error(),
+	}
+}
+
+// FailureNotifier is implemented by report notifiers that also deliver failed inspections.
+type FailureNotifier interface {
+	NotifyFailure(failure InspectionFailure) error
+}
+
+// NotifyInspectionFailed hands failure to every registered notifier that implements FailureNotifier
+// and waits for them. Like NotifyReportFinished, it only logs delivery failures.
+func NotifyInspectionFailed(failure InspectionFailure) {
+	reportNotifiersLock.RLock()
+	var notifiers []ReportNotifier
+	for _, n := range reportNotifiers {
+		if _, ok := n.(FailureNotifier); ok {
+			notifiers = append(notifiers, n)
+		}
+	}
+	reportNotifiersLock.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, n := range notifiers {
+		wg.Add(1)
+		go func(n ReportNotifier) {
+			defer wg.Done()
+			if err := n.(FailureNotifier).NotifyFailure(failure); err != nil {
+				logger.Errorf("Notification %s for failed inspection of %s failed: %v", n.Name(), failure.DBConnection, err)
+			}
+		}(n)
+	}
+	wg.Wait()
+}
+
+// NotificationSubject returns the subject line of notification messages, e.g.
+// "[Inspect4Oracle] CRM (ORCL) - Health Score 72, Critical 1, Warning 3".
+func NotificationSubject(data ReportData) string {
+	text := findingsText(data.Lang)
+	_, counts := collectFindings(data.Modules)
+	name := data.DBName
+	if data.BusinessName != "" {
+		name = fmt.Sprintf("%s (%s)", data.BusinessName, data.DBName)
+	}
+	return fmt.Sprintf("[Inspect4Oracle] %s - %s %d, %s %d, %s %d",
+		name, langText("健康评分", "Health Score", "ヘルススコア", data.Lang), data.HealthScore,
+		text[SeverityCritical], counts[SeverityCritical], text[SeverityWarning], counts[SeverityWarning])
+}
+
+// NotificationSummary returns the plain-text body of notification messages: the report metadata,
+// module errors, critical and warning findings, and the key cards of every module.
+// reportURL is included as a link when it is not empty.
+func NotificationSummary(data ReportData, reportURL string) string {
+	lang := data.Lang
+	text := findingsText(lang)
+	var b strings.Builder
+
+	textHeading(&b, data.Title, '=')
+	metadata := reportMetadataRows(data)
+	if reportURL != "" {
+		metadata = append(metadata, []string{langText("完整报告", "Full Report", "完全なレポート", lang), reportURL})
+	}
+	textPairs(&b, metadata)
+
+	var failed [][]string
+	for _, module := range data.Modules {
+		if module.Error != "" {
+			failed = append(failed, []string{module.Name, module.Error})
+		}
+	}
+	if len(failed) > 0 {
+		textHeading(&b, langText("模块错误", "Module Errors", "モジュールエラー", lang), '-')
+		textPairs(&b, failed)
+	}
+
+	findings, counts := collectFindings(data.Modules)
+	textHeading(&b, fmt.Sprintf("%s (%s %d, %s %d, %s %d)", text["Title"],
+		text[SeverityCritical], counts[SeverityCritical], text[SeverityWarning], counts[SeverityWarning],
+		text[SeverityInfo], counts[SeverityInfo]), '-')
+	listed := 0
+	for _, f := range findings {
+		if f.Severity == SeverityInfo {
+			continue
+		}
+		fmt.Fprintf(&b, "[%s] %s: %s\n", text[f.Severity], f.ModuleName, f.Message)
+		listed++
+	}
+	if listed == 0 {
+		b.WriteString(text["None"] + "\n")
+	}
+	b.WriteString("\n")
+
+	for _, module := range data.Modules {
+		if len(module.Cards) == 0 {
+			continue
+		}
+		textHeading(&b, fmt.Sprintf("%s (%s %d)", module.Name, langText("健康评分", "Health Score", "ヘルススコア", lang), module.HealthScore), '-')
+		rows := make([][]string, 0, keyCardsPerModule)
+		for i, card := range module.Cards {
+			if i == keyCardsPerModule {
+				break
+			}
+			rows = append(rows, []string{card.Title, card.Value})
+		}
+		textPairs(&b, rows)
+	}
+	return b.String()
+}
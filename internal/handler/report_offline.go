@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+)
+
+// Tags of the rendered report page that load files from /static/.
+var (
+	offlineScriptTag = regexp.MustCompile(`<script([^>]*?)\ssrc="(/static/[^"]+)"([^>]*)></script>`)
+	offlineLinkTag   = regexp.MustCompile(`<link([^>]*?)\shref="(/static/[^"]+)"([^>]*)>`)
+	offlineImageSrc  = regexp.MustCompile(`(<img[^>]*?\ssrc=)"(/static/[^"]+)"`)
+	cssURLPattern    = regexp.MustCompile(`url\(\s*["']?([^"')]+?)["']?\s*\)`)
+	scriptEndTag     = regexp.MustCompile(`(?i)</script`)
+	styleEndTag      = regexp.MustCompile(`(?i)</style`)
+)
+
+// assetMediaTypes lists the media types of the files referenced by the stylesheets and the page.
+var assetMediaTypes = map[string]string{
+	".woff2": "font/woff2",
+	".woff":  "font/woff",
+	".ttf":   "font/ttf",
+	".svg":   "image/svg+xml",
+	".png":   "image/png",
+	".jpg":   "image/jpeg",
+	".gif":   "image/gif",
+}
+
+// RenderReportOfflineHTML renders the report as a single HTML file that opens without the server.
+// Stylesheets, scripts, fonts and images from /static/ are embedded, so the charts stay interactive.
+func RenderReportOfflineHTML(w io.Writer, content fs.FS, reportData ReportData) error {
+	templateData := buildReportTemplateData(reportData)
+	templateData["Offline"] = true
+
+	var page bytes.Buffer
+	if err := executeReportTemplate(&page, content, templateData); err != nil {
+		return err
+	}
+	html, err := inlineStaticAssets(page.String(), content)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, html)
+	return err
+}
+
+// inlineStaticAssets replaces every /static/ reference of the page with the file content.
+// Deferred scripts are moved to the end of the body, which keeps their execution order.
+func inlineStaticAssets(page string, content fs.FS) (string, error) {
+	var firstErr error
+	fail := func(err error) string {
+		if firstErr == nil {
+			firstErr = err
+		}
+		return ""
+	}
+
+	// The layout and the report page both load Chart.js under different names; embed it once.
+	inlined := make(map[[32]byte]bool)
+	var deferred []string
+	page = offlineScriptTag.ReplaceAllStringFunc(page, func(tag string) string {
+		m := offlineScriptTag.FindStringSubmatch(tag)
+		data, err := fs.ReadFile(content, strings.TrimPrefix(m[2], "/"))
+		if err != nil {
+			return fail(fmt.Errorf("failed to embed %s: %w", m[2], err))
+		}
+		sum := sha256.Sum256(data)
+		if inlined[sum] {
+			return ""
+		}
+		inlined[sum] = true
+
+		attrs := m[1] + m[3]
+		isDeferred := strings.Contains(attrs, "defer")
+		attrs = strings.TrimSpace(strings.ReplaceAll(attrs, "defer", ""))
+		if attrs != "" {
+			attrs = " " + attrs
+		}
+		script := fmt.Sprintf("<script%s>\n%s\n</script>", attrs, scriptEndTag.ReplaceAllString(string(data), `<\/script`))
+		if isDeferred {
+			deferred = append(deferred, script)
+			return ""
+		}
+		return script
+	})
+
+	page = offlineLinkTag.ReplaceAllStringFunc(page, func(tag string) string {
+		m := offlineLinkTag.FindStringSubmatch(tag)
+		assetPath := strings.TrimPrefix(m[2], "/")
+		if !strings.Contains(m[1]+m[3], `rel="stylesheet"`) {
+			uri, err := assetDataURI(content, assetPath)
+			if err != nil {
+				return fail(err)
+			}
+			return fmt.Sprintf(`<link%s href="%s"%s>`, m[1], uri, m[3])
+		}
+		css, err := inlineStylesheet(content, assetPath)
+		if err != nil {
+			return fail(err)
+		}
+		return fmt.Sprintf("<style>\n%s\n</style>", styleEndTag.ReplaceAllString(css, `<\/style`))
+	})
+
+	page = offlineImageSrc.ReplaceAllStringFunc(page, func(attr string) string {
+		m := offlineImageSrc.FindStringSubmatch(attr)
+		uri, err := assetDataURI(content, strings.TrimPrefix(m[2], "/"))
+		if err != nil {
+			return fail(err)
+		}
+		return fmt.Sprintf(`%s"%s"`, m[1], uri)
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+
+	if len(deferred) > 0 {
+		scripts := strings.Join(deferred, "\n") + "\n"
+		if i := strings.LastIndex(page, "</body>"); i >= 0 {
+			page = page[:i] + scripts + page[i:]
+		} else {
+			page += scripts
+		}
+	}
+	return page, nil
+}
+
+// inlineStylesheet reads a stylesheet and replaces the files it references (fonts, images) with data URIs.
+func inlineStylesheet(content fs.FS, cssPath string) (string, error) {
+	data, err := fs.ReadFile(content, cssPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to embed %s: %w", cssPath, err)
+	}
+	return cssURLPattern.ReplaceAllStringFunc(string(data), func(ref string) string {
+		target := cssURLPattern.FindStringSubmatch(ref)[1]
+		if strings.HasPrefix(target, "data:") || strings.HasPrefix(target, "#") || strings.Contains(target, "://") {
+			return ref
+		}
+		if i := strings.IndexAny(target, "?#"); i >= 0 {
+			target = target[:i]
+		}
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join(path.Dir(cssPath), target)
+		}
+		uri, err := assetDataURI(content, target)
+		if err != nil {
+			// A missing font only degrades the icons; keep the rest of the report usable.
+			logger.Warnf("Offline report: %v", err)
+			return ref
+		}
+		return fmt.Sprintf(`url("%s")`, uri)
+	}), nil
+}
+
+// assetDataURI returns the file at assetPath as a base64 data URI.
+func assetDataURI(content fs.FS, assetPath string) (string, error) {
+	data, err := fs.ReadFile(content, assetPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to embed %s: %w", assetPath, err)
+	}
+	mediaType, ok := assetMediaTypes[strings.ToLower(path.Ext(assetPath))]
+	if !ok {
+		mediaType = "application/octet-stream"
+	}
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
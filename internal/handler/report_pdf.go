@@ -0,0 +1,617 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/pdf"
+)
+
+// PDF layout, in points.
+const (
+	pdfMargin       = 40.0
+	pdfTop          = 50.0
+	pdfBottom       = pdf.PageHeight - 50
+	pdfContentWidth = pdf.PageWidth - 2*pdfMargin
+	pdfTableSize    = 7.0 // Font size of table cells
+	pdfCellPadding  = 3.0 // Padding inside table cells
+	pdfMaxCellLines = 12  // Longer cell values are cut off
+	pdfChartHeight  = 170.0
+)
+
+var (
+	pdfBlack        = pdf.RGB(33, 37, 41)
+	pdfMuted        = pdf.RGB(108, 117, 125)
+	pdfBlue         = pdf.RGB(13, 110, 253)
+	pdfGrid         = pdf.RGB(222, 226, 230)
+	pdfHeaderBg     = pdf.RGB(233, 236, 239)
+	pdfDanger       = pdf.RGB(220, 53, 69)
+	pdfWarning      = pdf.RGB(214, 151, 0)
+	pdfInfo         = pdf.RGB(13, 152, 186)
+	pdfSuccess      = pdf.RGB(25, 135, 84)
+	pdfChartPalette = []pdf.Color{
+		pdf.RGB(54, 162, 235), pdf.RGB(255, 99, 132), pdf.RGB(75, 192, 192),
+		pdf.RGB(255, 159, 64), pdf.RGB(153, 102, 255), pdf.RGB(201, 203, 207),
+	}
+)
+
+// pdfReport lays out one report; y is the position of the next element on the current page.
+type pdfReport struct {
+	doc  *pdf.Document
+	page *pdf.Page
+	y    float64
+	lang string
+}
+
+// exportPDF renders the report as a paginated PDF: a cover page, a table of contents built from
+// ReportSections, the findings summary and one section per module.
+func exportPDF(w io.Writer, data ReportData) error {
+	lang := data.Lang
+	cjk := pdf.SimplifiedChinese
+	if lang == "jp" {
+		cjk = pdf.Japanese
+	}
+	r := &pdfReport{doc: pdf.New(data.Title, cjk), lang: lang}
+	text := findingsText(lang)
+
+	r.cover(data)
+
+	// Reserve the table of contents pages; they are filled in once the section pages are known.
+	type tocEntry struct {
+		title string
+		page  int
+	}
+	entries := []tocEntry{{title: text["Title"]}}
+	for _, section := range data.ReportSections {
+		entries = append(entries, tocEntry{title: section.Name})
+	}
+	const tocLineHeight = 18.0
+	tocPerPage := int(math.Floor((pdfBottom - pdfTop - 40) / tocLineHeight))
+	tocFirst := r.doc.PageCount()
+	for i := 0; i < (len(entries)+tocPerPage-1)/tocPerPage; i++ {
+		r.doc.AddPage()
+	}
+
+	entries[0].page = r.newSection(text["Title"])
+	r.findingsSummary(data.Modules, text)
+
+	sectionPages := map[string]int{}
+	for _, module := range data.Modules {
+		sectionPages[module.ID] = r.newSection(module.Name)
+		r.module(module, text)
+	}
+	for i, section := range data.ReportSections {
+		entries[i+1].page = sectionPages[section.ID]
+	}
+
+	contentsTitle := langText("目录", "Contents", "目次", lang)
+	for i, entry := range entries {
+		page := r.doc.Page(tocFirst + i/tocPerPage)
+		y := pdfTop + 40 + float64(i%tocPerPage)*tocLineHeight
+		if i%tocPerPage == 0 {
+			page.Text(pdfMargin, pdfTop+14, 16, true, pdfBlack, contentsTitle)
+		}
+		number := strconv.Itoa(entry.page + 1)
+		page.Text(pdfMargin, y, 11, false, pdfBlue, entry.title)
+		page.Text(pdf.PageWidth-pdfMargin-pdf.TextWidth(number, 11, false), y, 11, false, pdfBlack, number)
+		page.Line(pdfMargin, y+4, pdf.PageWidth-pdfMargin, y+4, 0.3, pdfGrid)
+		page.Link(pdfMargin, y-11, pdfContentWidth, tocLineHeight-2, entry.page, pdfTop)
+	}
+
+	// Footers go on last, when the page count is known; the cover page has none.
+	footer := strings.TrimSpace(data.BusinessName + "  " + data.DBConnection)
+	total := r.doc.PageCount()
+	for i := 1; i < total; i++ {
+		page := r.doc.Page(i)
+		number := fmt.Sprintf("%d / %d", i+1, total)
+		page.Line(pdfMargin, pdfBottom+12, pdf.PageWidth-pdfMargin, pdfBottom+12, 0.5, pdfGrid)
+		page.Text(pdfMargin, pdfBottom+24, 8, false, pdfMuted, footer)
+		page.Text(pdf.PageWidth-pdfMargin-pdf.TextWidth(number, 8, false), pdfBottom+24, 8, false, pdfMuted, number)
+	}
+
+	_, err := r.doc.WriteTo(w)
+	return err
+}
+
+// cover draws the title page with the report metadata and overall results.
+func (r *pdfReport) cover(data ReportData) {
+	lang := r.lang
+	r.newPage()
+	r.y = 160
+	for _, line := range pdf.Wrap(data.Title, pdfContentWidth, 24, true) {
+		r.page.Text(pdfMargin, r.y, 24, true, pdfBlack, line)
+		r.y += 30
+	}
+	r.page.Line(pdfMargin, r.y, pdf.PageWidth-pdfMargin, r.y, 1.5, pdfBlue)
+	r.y += 30
+
+	rows := [][]string{
+		{langText("业务名称", "Business Name", "業務名", lang), data.BusinessName},
+		{langText("数据库", "Database", "データベース", lang), data.DBFullInfo},
+		{langText("连接", "Connection", "接続", lang), data.DBConnection},
+		{langText("巡检时间", "Inspection Time", "検査時間", lang), data.GeneratedAt},
+	}
+	if data.SessionUser != "" {
+		rows = append(rows, []string{langText("巡检用户", "Inspected As", "検査ユーザー", lang), sessionUserText(data)})
+	}
+	if data.ThresholdProfile != "" {
+		rows = append(rows, []string{langText("阈值配置", "Threshold Profile", "しきい値プロファイル", lang), data.ThresholdProfile})
+	}
+	for _, row := range rows {
+		r.page.Text(pdfMargin, r.y, 11, true, pdfMuted, row[0])
+		for _, line := range pdf.Wrap(row[1], pdfContentWidth-140, 11, false) {
+			r.page.Text(pdfMargin+140, r.y, 11, false, pdfBlack, line)
+			r.y += 16
+		}
+		r.y += 6
+	}
+
+	r.y += 20
+	r.page.Text(pdfMargin, r.y, 11, true, pdfMuted, langText("健康评分", "Health Score", "ヘルススコア", lang))
+	r.page.Text(pdfMargin+140, r.y+6, 32, true, healthColor(data.HealthScore), strconv.Itoa(data.HealthScore))
+	r.y += 40
+
+	_, counts := collectFindings(data.Modules)
+	text := findingsText(lang)
+	x := pdfMargin + 140
+	for _, severity := range []string{SeverityCritical, SeverityWarning, SeverityInfo} {
+		label := fmt.Sprintf("%s %d", text[severity], counts[severity])
+		r.page.Text(x, r.y, 11, true, severityColor(severity), label)
+		x += pdf.TextWidth(label, 11, true) + 24
+	}
+	r.page.Text(pdfMargin, r.y, 11, true, pdfMuted, text["Title"])
+}
+
+// newPage starts a new page and moves to its top.
+func (r *pdfReport) newPage() {
+	r.page = r.doc.AddPage()
+	r.y = pdfTop
+}
+
+// newSection starts a section on a new page with a title and a bookmark, and returns the page index.
+func (r *pdfReport) newSection(title string) int {
+	r.newPage()
+	index := r.doc.PageCount() - 1
+	r.doc.AddBookmark(title, index, pdfTop)
+	r.page.Text(pdfMargin, r.y+14, 16, true, pdfBlack, title)
+	r.y += 24
+	r.page.Line(pdfMargin, r.y, pdf.PageWidth-pdfMargin, r.y, 1, pdfBlue)
+	r.y += 16
+	return index
+}
+
+// ensure moves to a new page when less than height is left on the current one.
+func (r *pdfReport) ensure(height float64) {
+	if r.y+height > pdfBottom {
+		r.newPage()
+	}
+}
+
+// paragraph writes wrapped text.
+func (r *pdfReport) paragraph(s string, size float64, bold bool, c pdf.Color) {
+	lineHeight := size * 1.35
+	for _, line := range pdf.Wrap(s, pdfContentWidth, size, bold) {
+		r.ensure(lineHeight)
+		r.page.Text(pdfMargin, r.y+size, size, bold, c, line)
+		r.y += lineHeight
+	}
+}
+
+// findingsSummary lists the findings of all modules, most severe first.
+func (r *pdfReport) findingsSummary(modules []ReportModule, text map[string]string) {
+	findings, _ := collectFindings(modules)
+	if len(findings) == 0 {
+		r.paragraph(text["None"], 10, false, pdfSuccess)
+		return
+	}
+	rows := make([][]string, 0, len(findings))
+	for _, f := range findings {
+		rows = append(rows, []string{text[f.Severity], f.ModuleName, f.Message, f.Recommendation})
+	}
+	r.table([]string{text["Severity"], text["Module"], text["Message"], text["Recommendation"]}, rows)
+}
+
+// module renders one report module: health score, error, findings, cards, tables and charts.
+func (r *pdfReport) module(module ReportModule, text map[string]string) {
+	score := fmt.Sprintf("%s %d", langText("健康评分", "Health Score", "ヘルススコア", r.lang), module.HealthScore)
+	r.page.Text(pdf.PageWidth-pdfMargin-pdf.TextWidth(score, 10, true), pdfTop+14, 10, true, healthColor(module.HealthScore), score)
+
+	if module.Error != "" {
+		r.paragraph(module.Error, 9, false, pdfDanger)
+		r.y += 6
+	}
+	for _, f := range module.Findings {
+		r.paragraph(fmt.Sprintf("[%s] %s", text[f.Severity], f.Message), 9, true, severityColor(f.Severity))
+		if f.Recommendation != "" {
+			r.paragraph(f.Recommendation, 8, false, pdfMuted)
+		}
+		r.y += 4
+	}
+	if len(module.Findings) > 0 {
+		r.y += 6
+	}
+
+	if len(module.Cards) > 0 {
+		rows := make([][]string, 0, len(module.Cards))
+		for _, card := range module.Cards {
+			rows = append(rows, []string{card.Title, card.Value})
+		}
+		r.table(nil, rows)
+	}
+	for _, table := range module.Tables {
+		if table == nil {
+			continue
+		}
+		r.ensure(40)
+		r.paragraph(table.Name, 10, true, pdfBlack)
+		r.y += 2
+		if len(table.Rows) == 0 {
+			r.paragraph(langText("无数据", "No data", "データなし", r.lang), 8, false, pdfMuted)
+		} else {
+			r.table(table.Headers, table.Rows)
+		}
+		if table.Notes != "" {
+			r.paragraph(table.Notes, 8, false, pdfMuted)
+		}
+		r.y += 8
+	}
+	for _, chart := range module.Charts {
+		r.chart(chart)
+	}
+}
+
+// table draws a table with wrapped cells; the header row is repeated on every page. headers may be nil.
+func (r *pdfReport) table(headers []string, rows [][]string) {
+	columns := len(headers)
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	if columns == 0 {
+		return
+	}
+	widths := pdfColumnWidths(headers, rows, columns)
+	lineHeight := pdfTableSize * 1.3
+
+	drawRow := func(cells []string, bold bool, fill *pdf.Color) {
+		wrapped := make([][]string, columns)
+		lines := 1
+		for i := 0; i < columns; i++ {
+			wrapped[i] = pdf.Wrap(cell(cells, i), widths[i]-2*pdfCellPadding, pdfTableSize, bold)
+			if len(wrapped[i]) > pdfMaxCellLines {
+				wrapped[i] = append(wrapped[i][:pdfMaxCellLines-1], "…")
+			}
+			if len(wrapped[i]) > lines {
+				lines = len(wrapped[i])
+			}
+		}
+		height := float64(lines)*lineHeight + 2*pdfCellPadding
+		if fill != nil {
+			r.page.FillRect(pdfMargin, r.y, pdfContentWidth, height, *fill)
+		}
+		x := pdfMargin
+		for i := 0; i < columns; i++ {
+			for j, line := range wrapped[i] {
+				r.page.Text(x+pdfCellPadding, r.y+pdfCellPadding+float64(j)*lineHeight+pdfTableSize, pdfTableSize, bold, pdfBlack, line)
+			}
+			x += widths[i]
+		}
+		r.y += height
+		r.page.Line(pdfMargin, r.y, pdfMargin+pdfContentWidth, r.y, 0.5, pdfGrid)
+	}
+	rowHeight := func(cells []string, bold bool) float64 {
+		lines := 1
+		for i := 0; i < columns; i++ {
+			n := len(pdf.Wrap(cell(cells, i), widths[i]-2*pdfCellPadding, pdfTableSize, bold))
+			if n > pdfMaxCellLines {
+				n = pdfMaxCellLines
+			}
+			if n > lines {
+				lines = n
+			}
+		}
+		return float64(lines)*lineHeight + 2*pdfCellPadding
+	}
+	drawHeader := func() {
+		if headers != nil {
+			fill := pdfHeaderBg
+			drawRow(headers, true, &fill)
+		}
+	}
+
+	headerHeight := 0.0
+	if headers != nil {
+		headerHeight = rowHeight(headers, true)
+	}
+	first := 0.0
+	if len(rows) > 0 {
+		first = rowHeight(rows[0], false)
+	}
+	r.ensure(headerHeight + first)
+	drawHeader()
+	for _, row := range rows {
+		if h := rowHeight(row, false); r.y+h > pdfBottom {
+			r.newPage()
+			drawHeader()
+		}
+		drawRow(row, false, nil)
+	}
+	r.y += 8
+}
+
+// pdfColumnWidths shares the content width between the columns: narrow columns get the width
+// they need and the remaining space is split evenly between the wide ones.
+func pdfColumnWidths(headers []string, rows [][]string, columns int) []float64 {
+	const minWidth = 28.0
+	natural := make([]float64, columns)
+	measure := func(cells []string, bold bool) {
+		for i := 0; i < columns; i++ {
+			w := pdf.TextWidth(cell(cells, i), pdfTableSize, bold) + 2*pdfCellPadding
+			if w > natural[i] {
+				natural[i] = w
+			}
+		}
+	}
+	measure(headers, true)
+	for i, row := range rows {
+		if i >= 500 { // Enough to judge the column, and keeps very large tables fast
+			break
+		}
+		measure(row, false)
+	}
+
+	widths := make([]float64, columns)
+	total := 0.0
+	for i := range natural {
+		natural[i] = math.Max(natural[i], minWidth)
+		total += natural[i]
+	}
+	if total <= pdfContentWidth {
+		for i := range natural {
+			widths[i] = natural[i] * pdfContentWidth / total
+		}
+		return widths
+	}
+
+	remaining := pdfContentWidth
+	open := make([]int, columns)
+	for i := range open {
+		open[i] = i
+	}
+	for {
+		share := remaining / float64(len(open))
+		var wide []int
+		for _, i := range open {
+			if natural[i] <= share {
+				widths[i] = natural[i]
+				remaining -= natural[i]
+			} else {
+				wide = append(wide, i)
+			}
+		}
+		if len(wide) == len(open) || len(wide) == 0 {
+			for _, i := range wide {
+				widths[i] = math.Max(share, minWidth)
+			}
+			return widths
+		}
+		open = wide
+	}
+}
+
+// chart draws a line or bar chart from the Chart.js datasets of the report.
+func (r *pdfReport) chart(chart ReportChart) {
+	var data ChartJSData
+	if err := json.Unmarshal([]byte(chart.DatasetsJSON), &data); err != nil || len(data.Datasets) == 0 {
+		return
+	}
+	var options ChartJSOptions
+	_ = json.Unmarshal([]byte(chart.OptionsJSON), &options) // Options only add titles
+
+	// Collect the x labels of all datasets; time labels sort chronologically.
+	seen := map[string]bool{}
+	var labels []string
+	maxValue := 0.0
+	for _, ds := range data.Datasets {
+		for _, p := range ds.Data {
+			label := fmt.Sprint(p.X)
+			if !seen[label] {
+				seen[label] = true
+				labels = append(labels, label)
+			}
+			if v, ok := chartValue(p.Y); ok && v > maxValue {
+				maxValue = v
+			}
+		}
+	}
+	if len(labels) == 0 {
+		return
+	}
+	if chart.Type == "line" {
+		sort.Strings(labels)
+	}
+	index := make(map[string]int, len(labels))
+	for i, label := range labels {
+		index[label] = i
+	}
+	top, step := chartScale(maxValue)
+
+	r.ensure(pdfChartHeight + 60)
+	if title := options.Plugins.Title.Text; title != "" {
+		r.paragraph(title, 10, true, pdfBlack)
+	}
+	plotX, plotY := pdfMargin+45, r.y+8
+	plotW, plotH := pdfContentWidth-55, pdfChartHeight-30
+
+	// Grid lines and y-axis labels.
+	for v := 0.0; v <= top+step/2; v += step {
+		y := plotY + plotH - v/top*plotH
+		r.page.Line(plotX, y, plotX+plotW, y, 0.3, pdfGrid)
+		label := strconv.FormatFloat(v, 'f', -1, 64)
+		r.page.Text(plotX-4-pdf.TextWidth(label, 6, false), y+2, 6, false, pdfMuted, label)
+	}
+	r.page.Line(plotX, plotY, plotX, plotY+plotH, 0.5, pdfMuted)
+	r.page.Line(plotX, plotY+plotH, plotX+plotW, plotY+plotH, 0.5, pdfMuted)
+	if title := options.Scales.Y.Title.Text; title != "" {
+		r.page.Text(pdfMargin, plotY-4, 6, false, pdfMuted, title)
+	}
+
+	// X-axis labels: at most six, evenly spaced.
+	slot := plotW / float64(len(labels))
+	every := (len(labels) + 5) / 6
+	for i := 0; i < len(labels); i += every {
+		label := labels[i]
+		x := plotX + slot*(float64(i)+0.5) - pdf.TextWidth(label, 6, false)/2
+		r.page.Text(math.Max(x, plotX), plotY+plotH+9, 6, false, pdfMuted, label)
+	}
+
+	for d, ds := range data.Datasets {
+		color := pdfDatasetColor(ds, d)
+		if chart.Type == "bar" {
+			barW := slot * 0.8 / float64(len(data.Datasets))
+			for _, p := range ds.Data {
+				v, ok := chartValue(p.Y)
+				if !ok || v <= 0 {
+					continue
+				}
+				h := v / top * plotH
+				x := plotX + slot*float64(index[fmt.Sprint(p.X)]) + slot*0.1 + barW*float64(d)
+				r.page.FillRect(x, plotY+plotH-h, barW, h, color)
+			}
+			continue
+		}
+		var points []pdf.Point
+		for _, p := range ds.Data {
+			if v, ok := chartValue(p.Y); ok {
+				points = append(points, pdf.Point{
+					X: plotX + slot*(float64(index[fmt.Sprint(p.X)])+0.5),
+					Y: plotY + plotH - v/top*plotH,
+				})
+			}
+		}
+		if len(points) == 1 {
+			r.page.FillRect(points[0].X-1.5, points[0].Y-1.5, 3, 3, color)
+		}
+		r.page.Polyline(points, 1, color)
+	}
+
+	// Legend.
+	r.y = plotY + plotH + 20
+	x := plotX
+	for d, ds := range data.Datasets {
+		if ds.Label == "" {
+			continue
+		}
+		r.page.FillRect(x, r.y-5, 8, 5, pdfDatasetColor(ds, d))
+		r.page.Text(x+11, r.y, 7, false, pdfBlack, ds.Label)
+		x += 11 + pdf.TextWidth(ds.Label, 7, false) + 14
+	}
+	r.y += 16
+}
+
+// chartScale returns a rounded axis maximum above max and the step between grid lines.
+func chartScale(maxValue float64) (float64, float64) {
+	if maxValue <= 0 {
+		return 1, 0.25
+	}
+	raw := maxValue / 4
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude
+	for _, m := range []float64{1, 2, 2.5, 5, 10} {
+		if m*magnitude >= raw {
+			step = m * magnitude
+			break
+		}
+	}
+	return math.Ceil(maxValue/step) * step, step
+}
+
+// chartValue converts a Chart.js y value to a number.
+func chartValue(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// pdfDatasetColor uses the dataset's own color when it can be parsed and a palette color otherwise.
+func pdfDatasetColor(ds ChartDataset, index int) pdf.Color {
+	for _, css := range []string{ds.BorderColor, ds.BackgroundColor} {
+		if c, ok := parseCSSColor(css); ok {
+			return c
+		}
+	}
+	return pdfChartPalette[index%len(pdfChartPalette)]
+}
+
+// parseCSSColor understands the #rrggbb, rgb() and rgba() forms used by the chart datasets.
+func parseCSSColor(css string) (pdf.Color, bool) {
+	css = strings.TrimSpace(css)
+	if strings.HasPrefix(css, "#") && len(css) == 7 {
+		v, err := strconv.ParseUint(css[1:], 16, 32)
+		if err != nil {
+			return pdf.Color{}, false
+		}
+		return pdf.RGB(uint8(v>>16), uint8(v>>8), uint8(v)), true
+	}
+	start, end := strings.Index(css, "("), strings.LastIndex(css, ")")
+	if !strings.HasPrefix(css, "rgb") || start < 0 || end < start {
+		return pdf.Color{}, false
+	}
+	parts := strings.Split(css[start+1:end], ",")
+	if len(parts) < 3 {
+		return pdf.Color{}, false
+	}
+	var rgb [3]uint8
+	for i := 0; i < 3; i++ {
+		v, err := strconv.Atoi(strings.TrimSpace(parts[i]))
+		if err != nil || v < 0 || v > 255 {
+			return pdf.Color{}, false
+		}
+		rgb[i] = uint8(v)
+	}
+	return pdf.RGB(rgb[0], rgb[1], rgb[2]), true
+}
+
+// healthColor matches the colors of the health score badges on the report page.
+func healthColor(score int) pdf.Color {
+	switch healthLevel(score) {
+	case HealthGood:
+		return pdfSuccess
+	case HealthFair:
+		return pdfWarning
+	default:
+		return pdfDanger
+	}
+}
+
+// severityColor matches the colors of the findings badges on the report page.
+func severityColor(severity string) pdf.Color {
+	switch severity {
+	case SeverityCritical:
+		return pdfDanger
+	case SeverityWarning:
+		return pdfWarning
+	default:
+		return pdfInfo
+	}
+}
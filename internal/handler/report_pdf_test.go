@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// pdfFile is a written PDF split into its objects, with content streams decompressed.
+type pdfFile struct {
+	objects map[int]string
+	pages   []int       // Page object numbers in page order
+	index   map[int]int // Page object number to page index
+}
+
+var (
+	pdfObjectRef    = regexp.MustCompile(`(\d+) 0 R`)
+	pdfStartXref    = regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`)
+	pdfStreamLength = regexp.MustCompile(`^<< /Length (\d+) /Filter /FlateDecode >>\nstream\n`)
+)
+
+// readPDF locates every object through the cross-reference table, failing when an entry is off.
+func readPDF(t *testing.T, data []byte) *pdfFile {
+	t.Helper()
+	m := pdfStartXref.FindSubmatch(data)
+	if m == nil {
+		t.Fatal("no startxref at the end of the document")
+	}
+	start, _ := strconv.Atoi(string(m[1]))
+	var size int
+	if _, err := fmt.Sscanf(string(data[start:]), "xref\n0 %d\n", &size); err != nil {
+		t.Fatalf("startxref %d does not point at the xref table: %v", start, err)
+	}
+	entries := data[start+len(fmt.Sprintf("xref\n0 %d\n", size)):]
+	f := &pdfFile{objects: map[int]string{}, index: map[int]int{}}
+	for obj := 1; obj < size; obj++ {
+		offset, err := strconv.Atoi(string(entries[obj*20 : obj*20+10]))
+		if err != nil {
+			t.Fatalf("xref entry %d: %v", obj, err)
+		}
+		header := fmt.Sprintf("%d 0 obj\n", obj)
+		if !bytes.HasPrefix(data[offset:], []byte(header)) {
+			t.Fatalf("xref entry %d points at %q", obj, data[offset:offset+12])
+		}
+		body := data[offset+len(header):]
+		if m := pdfStreamLength.FindSubmatch(body); m != nil {
+			length, _ := strconv.Atoi(string(m[1]))
+			zr, err := zlib.NewReader(bytes.NewReader(body[len(m[0]) : len(m[0])+length]))
+			if err != nil {
+				t.Fatalf("object %d: %v", obj, err)
+			}
+			content, err := io.ReadAll(zr)
+			if err != nil {
+				t.Fatalf("object %d: %v", obj, err)
+			}
+			f.objects[obj] = string(content)
+			continue
+		}
+		f.objects[obj] = string(body[:bytes.Index(body, []byte("endobj\n"))])
+	}
+
+	for _, obj := range f.sortedObjects() {
+		if body := f.objects[obj]; strings.HasPrefix(body, "<< /Type /Pages ") {
+			kids := body[strings.Index(body, "/Kids"):]
+			for _, ref := range pdfObjectRef.FindAllStringSubmatch(kids, -1) {
+				page, _ := strconv.Atoi(ref[1])
+				f.index[page] = len(f.pages)
+				f.pages = append(f.pages, page)
+			}
+			if !strings.Contains(body, fmt.Sprintf("/Count %d ", len(f.pages))) {
+				t.Errorf("page tree /Count does not match its %d kids", len(f.pages))
+			}
+		}
+	}
+	return f
+}
+
+func (f *pdfFile) sortedObjects() []int {
+	objs := make([]int, 0, len(f.objects))
+	for obj := range f.objects {
+		objs = append(objs, obj)
+	}
+	sort.Ints(objs)
+	return objs
+}
+
+// content returns the decompressed content stream of a page.
+func (f *pdfFile) content(page int) string {
+	m := regexp.MustCompile(`/Contents (\d+) 0 R`).FindStringSubmatch(f.objects[f.pages[page]])
+	obj, _ := strconv.Atoi(m[1])
+	return f.objects[obj]
+}
+
+// links returns the target page of every link annotation on a page.
+func (f *pdfFile) links(page int) []int {
+	body := f.objects[f.pages[page]]
+	i := strings.Index(body, "/Annots [")
+	if i < 0 {
+		return nil
+	}
+	var targets []int
+	for _, ref := range pdfObjectRef.FindAllStringSubmatch(body[i:], -1) {
+		annot, _ := strconv.Atoi(ref[1])
+		targets = append(targets, f.destination(f.objects[annot]))
+	}
+	return targets
+}
+
+// destination returns the page index a /Dest entry points at.
+func (f *pdfFile) destination(body string) int {
+	m := regexp.MustCompile(`/Dest \[(\d+) 0 R`).FindStringSubmatch(body)
+	if m == nil {
+		return -1
+	}
+	obj, _ := strconv.Atoi(m[1])
+	if index, ok := f.index[obj]; ok {
+		return index
+	}
+	return -1
+}
+
+// outline returns the bookmark titles and their target pages in order.
+func (f *pdfFile) outline() ([]string, []int) {
+	var titles []string
+	var pages []int
+	title := regexp.MustCompile(`^<< /Title <FEFF([0-9A-F]*)> /Parent`)
+	for _, obj := range f.sortedObjects() {
+		m := title.FindStringSubmatch(f.objects[obj])
+		if m == nil {
+			continue
+		}
+		var units []uint16
+		for i := 0; i+4 <= len(m[1]); i += 4 {
+			u, _ := strconv.ParseUint(m[1][i:i+4], 16, 16)
+			units = append(units, uint16(u))
+		}
+		titles = append(titles, string(utf16.Decode(units)))
+		pages = append(pages, f.destination(f.objects[obj]))
+	}
+	return titles, pages
+}
+
+var pdfShowText = regexp.MustCompile(`\((.*?[^\\])\) Tj|<([0-9A-F]*)> Tj`)
+
+// pdfTexts returns the strings drawn by a content stream, one per Page.Text call.
+func pdfTexts(content string) []string {
+	var texts []string
+	for _, block := range strings.Split(content, "BT ")[1:] {
+		var b strings.Builder
+		for _, m := range pdfShowText.FindAllStringSubmatch(block, -1) {
+			if m[0][0] == '(' {
+				b.WriteString(strings.NewReplacer(`\(`, "(", `\)`, ")", `\\`, `\`).Replace(m[1]))
+				continue
+			}
+			for i := 0; i+4 <= len(m[2]); i += 4 {
+				r, _ := strconv.ParseUint(m[2][i:i+4], 16, 16)
+				b.WriteRune(rune(r))
+			}
+		}
+		texts = append(texts, b.String())
+	}
+	return texts
+}
+
+// hasText reports whether s is one of texts.
+func hasText(texts []string, s string) bool {
+	for _, text := range texts {
+		if text == s {
+			return true
+		}
+	}
+	return false
+}
+
+// samplePDFReport returns a report with the given number of modules; the last one has rows table rows.
+func samplePDFReport(lang string, modules, rows int) ReportData {
+	data := ReportData{
+		Lang:         lang,
+		Title:        "Oracle 数据库巡检报告",
+		BusinessName: "CRM",
+		DBConnection: "db1:1521/CRMPDB",
+		GeneratedAt:  "2025-06-01 10:30:00",
+		HealthScore:  72,
+	}
+	for i := 0; i < modules; i++ {
+		module := ReportModule{
+			ID:          fmt.Sprintf("module%d", i),
+			Name:        fmt.Sprintf("模块 %d", i+1),
+			HealthScore: 90,
+			Cards:       []ReportCard{{Title: "Status", Value: "OPEN"}},
+		}
+		if i == modules-1 {
+			table := &ReportTable{Name: "Tablespaces", Headers: []string{"Name", "Used %", "说明"}}
+			for j := 0; j < rows; j++ {
+				table.Rows = append(table.Rows, []string{fmt.Sprintf("TS_%03d", j), "42", "表空间使用正常 normal usage"})
+			}
+			module.Tables = []*ReportTable{table}
+			module.Findings = []Finding{{Severity: SeverityWarning, Message: "Tablespace TS_001 is 85% full"}}
+		}
+		data.Modules = append(data.Modules, module)
+		data.ReportSections = append(data.ReportSections, ReportSection{ID: module.ID, Name: module.Name})
+	}
+	return data
+}
+
+func TestExportPDFPagesAndOutline(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     string
+		modules  int
+		rows     int
+		tocPages int
+		overflow bool // The last module needs more than one page
+	}{
+		{name: "short modules", lang: "en", modules: 2, tocPages: 1},
+		{name: "long table", lang: "zh", modules: 2, rows: 150, tocPages: 1, overflow: true},
+		{name: "table of contents over two pages", lang: "jp", modules: 40, tocPages: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := samplePDFReport(tt.lang, tt.modules, tt.rows)
+			var buf bytes.Buffer
+			if err := exportPDF(&buf, data); err != nil {
+				t.Fatal(err)
+			}
+			f := readPDF(t, buf.Bytes())
+
+			// Cover, table of contents, findings, then one page per module
+			findingsPage := 1 + tt.tocPages
+			wantTitles := []string{findingsText(tt.lang)["Title"]}
+			wantPages := []int{findingsPage}
+			for i, module := range data.Modules {
+				wantTitles = append(wantTitles, module.Name)
+				wantPages = append(wantPages, findingsPage+1+i)
+			}
+			last := wantPages[len(wantPages)-1]
+			if total := len(f.pages); (total > last+1) != tt.overflow || total < last+1 {
+				t.Errorf("%d pages, want %d (overflow %v)", total, last+1, tt.overflow)
+			}
+
+			titles, pages := f.outline()
+			if fmt.Sprint(titles) != fmt.Sprint(wantTitles) || fmt.Sprint(pages) != fmt.Sprint(wantPages) {
+				t.Errorf("outline = %q on pages %v, want %q on pages %v", titles, pages, wantTitles, wantPages)
+			}
+
+			var tocLinks []int
+			var toc []string
+			for page := 1; page <= tt.tocPages; page++ {
+				tocLinks = append(tocLinks, f.links(page)...)
+				toc = append(toc, pdfTexts(f.content(page))...)
+			}
+			if fmt.Sprint(tocLinks) != fmt.Sprint(wantPages) {
+				t.Errorf("table of contents links to pages %v, want %v", tocLinks, wantPages)
+			}
+			for i, page := range wantPages {
+				if !hasText(toc, wantTitles[i]) || !hasText(toc, strconv.Itoa(page+1)) {
+					t.Errorf("table of contents has no entry %q on page %d", wantTitles[i], page+1)
+				}
+			}
+
+			total := len(f.pages)
+			if hasText(pdfTexts(f.content(0)), fmt.Sprintf("1 / %d", total)) {
+				t.Errorf("cover page has a footer")
+			}
+			if footer := fmt.Sprintf("%d / %d", total, total); !hasText(pdfTexts(f.content(total-1)), footer) {
+				t.Errorf("last page has no footer %q", footer)
+			}
+			if !hasText(pdfTexts(f.content(0)), data.Title) {
+				t.Errorf("cover page has no title %q", data.Title)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"sort"
+	"time"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+)
+
+// buildReportTemplateData assembles the values consumed by templates/report.html.
+func buildReportTemplateData(reportData ReportData) map[string]interface{} {
+	nonceBytes := make([]byte, 16)
+	cspNonce := ""
+	if _, err := rand.Read(nonceBytes); err != nil {
+		logger.Warnf("Failed to generate CSP Nonce: %v", err)
+	} else {
+		cspNonce = base64.RawURLEncoding.EncodeToString(nonceBytes)
+	}
+
+	findings, counts := collectFindings(reportData.Modules)
+	return map[string]interface{}{
+		"Findings":         findings,
+		"FindingCounts":    counts,
+		"FindingsText":     findingsText(reportData.Lang),
+		"DbInfo":           reportData.BusinessName, // Use business name
+		"ActualDBName":     reportData.DBName,       // Add this if you need to display the actual database name elsewhere in the template
+		"DbConnection":     reportData.DBConnection,
+		"SessionUser":      reportData.SessionUser,
+		"ProxyUser":        reportData.ProxyUser,
+		"GeneratedAt":      reportData.GeneratedAt,
+		"ThresholdProfile": reportData.ThresholdProfile,
+		"HealthScore":      reportData.HealthScore,
+		"HealthLevel":      healthLevel(reportData.HealthScore),
+		"Modules":          reportData.Modules,
+		"Title":            reportData.Title,
+		"CopyrightYear":    time.Now().Format("2006"),
+		"ReportSections":   reportData.ReportSections,
+		"CSPNonce":         cspNonce,
+		"Lang":             reportData.Lang,
+		"Offline":          false, // Set by RenderReportOfflineHTML
+	}
+}
+
+// findingsText holds the localized labels of the findings summary, keyed by label name and by severity.
+func findingsText(lang string) map[string]string {
+	return map[string]string{
+		"Title":          langText("巡检发现", "Findings", "検出事項", lang),
+		"None":           langText("未发现问题", "No issues found", "問題は検出されませんでした", lang),
+		"Module":         langText("模块", "Module", "モジュール", lang),
+		"Severity":       langText("级别", "Severity", "重要度", lang),
+		"Message":        langText("问题", "Issue", "問題", lang),
+		"Recommendation": langText("建议", "Recommendation", "推奨事項", lang),
+		SeverityCritical: langText("严重", "Critical", "重大", lang),
+		SeverityWarning:  langText("警告", "Warning", "警告", lang),
+		SeverityInfo:     langText("提示", "Info", "情報", lang),
+	}
+}
+
+// reportFinding is a finding together with the module it belongs to, for the report summary.
+type reportFinding struct {
+	ModuleID   string
+	ModuleName string
+	Finding
+}
+
+// collectFindings gathers the findings of all modules, most severe first, and counts them by severity.
+func collectFindings(modules []ReportModule) ([]reportFinding, map[string]int) {
+	counts := map[string]int{SeverityCritical: 0, SeverityWarning: 0, SeverityInfo: 0}
+	var all []reportFinding
+	for _, m := range modules {
+		for _, f := range m.Findings {
+			all = append(all, reportFinding{ModuleID: m.ID, ModuleName: m.Name, Finding: f})
+			counts[f.Severity]++
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return severityRank(all[i].Severity) < severityRank(all[j].Severity)
+	})
+	return all, counts
+}
+
+// FindingCounts counts the findings of all modules by severity.
+func FindingCounts(modules []ReportModule) map[string]int {
+	_, counts := collectFindings(modules)
+	return counts
+}
+
+// RenderReportHTML renders reportData with the layout and report templates into w.
+// It is used by the /report.html page; the headless CLI writes RenderReportOfflineHTML.
+func RenderReportHTML(w io.Writer, content fs.FS, reportData ReportData) error {
+	return executeReportTemplate(w, content, buildReportTemplateData(reportData))
+}
+
+// executeReportTemplate renders the report page from prepared template data.
+func executeReportTemplate(w io.Writer, content fs.FS, templateData map[string]interface{}) error {
+	tmpl, err := template.New("layout").Funcs(template.FuncMap{
+		"safeJS": func(s interface{}) template.JS {
+			return template.JS(fmt.Sprint(s))
+		},
+	}).ParseFS(content, "templates/layout.html", "templates/report.html")
+	if err != nil {
+		return fmt.Errorf("failed to load report templates: %w", err)
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "layout", templateData); err != nil {
+		return fmt.Errorf("failed to execute report template: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,361 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+)
+
+// ReportRepository stores generated reports by ID.
+type ReportRepository interface {
+	Save(id string, data ReportData) error
+	Get(id string) (ReportData, bool, error)
+	List() ([]ReportSummary, error) // Newest first
+	Delete(id string) error
+	Prune() (int, error) // Applies the retention policy, returns the number of reports removed
+}
+
+// ReportSummary is the lightweight description of a stored report.
+type ReportSummary struct {
+	ID           string   `json:"id"`
+	Title        string   `json:"title"`
+	BusinessName string   `json:"businessName,omitempty"`
+	DBName       string   `json:"dbName"`
+	DBConnection string   `json:"dbConnection"`
+	GeneratedAt  string   `json:"generatedAt"`
+	Lang         string   `json:"lang"`
+	Modules      []string `json:"modules"`
+	ErrorCount   int      `json:"errorCount"` // Number of modules that finished with an error
+	HealthScore  int      `json:"healthScore"`
+}
+
+// RetentionPolicy limits how many reports are kept. Zero values disable the corresponding limit.
+type RetentionPolicy struct {
+	MaxAge         time.Duration // Reports generated longer ago than this are removed
+	MaxPerDatabase int           // Only the newest N reports of each DBConnection are kept
+}
+
+// reportIDPattern restricts report IDs to characters that are safe to use as file names.
+var reportIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// reportRepo is the repository used by the HTTP handlers; in-memory unless SetReportRepository is called.
+var (
+	reportRepo      ReportRepository = NewMemoryReportRepository(RetentionPolicy{})
+	reportRepoMutex sync.RWMutex
+)
+
+// SetReportRepository replaces the repository used to store and serve reports.
+func SetReportRepository(repo ReportRepository) {
+	reportRepoMutex.Lock()
+	reportRepo = repo
+	reportRepoMutex.Unlock()
+}
+
+// Reports returns the repository currently in use.
+func Reports() ReportRepository {
+	reportRepoMutex.RLock()
+	defer reportRepoMutex.RUnlock()
+	return reportRepo
+}
+
+// StoreReport saves reportData under reportID so that /report.html and /api/report/status can serve it.
+func StoreReport(reportID string, reportData ReportData) error {
+	return Reports().Save(reportID, reportData)
+}
+
+// loadReport fetches a report for the HTTP handlers, logging repository errors.
+func loadReport(reportID string) (ReportData, bool) {
+	data, ok, err := Reports().Get(reportID)
+	if err != nil {
+		logger.Errorf("Failed to load report %s: %v", reportID, err)
+		return ReportData{}, false
+	}
+	return data, ok
+}
+
+// summarizeReport builds the summary for a stored report.
+func summarizeReport(id string, data ReportData) ReportSummary {
+	summary := ReportSummary{
+		ID:           id,
+		Title:        data.Title,
+		BusinessName: data.BusinessName,
+		DBName:       data.DBName,
+		DBConnection: data.DBConnection,
+		GeneratedAt:  data.GeneratedAt,
+		Lang:         data.Lang,
+		Modules:      make([]string, 0, len(data.Modules)),
+		HealthScore:  data.HealthScore,
+	}
+	for _, module := range data.Modules {
+		summary.Modules = append(summary.Modules, module.ID)
+		if module.Error != "" {
+			summary.ErrorCount++
+		}
+	}
+	return summary
+}
+
+// reportTime parses ReportData.GeneratedAt, which is written in local time.
+func reportTime(generatedAt string) (time.Time, bool) {
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", generatedAt, time.Local)
+	return t, err == nil
+}
+
+// sortSummaries orders summaries newest first, by ID for equal timestamps.
+func sortSummaries(summaries []ReportSummary) {
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].GeneratedAt != summaries[j].GeneratedAt {
+			return summaries[i].GeneratedAt > summaries[j].GeneratedAt
+		}
+		return summaries[i].ID < summaries[j].ID
+	})
+}
+
+// expiredReports returns the IDs that policy says should be removed. keep is never returned, but
+// still counts towards the per-database limit; it is the report being saved, or "" for none.
+func expiredReports(summaries []ReportSummary, policy RetentionPolicy, now time.Time, keep string) []string {
+	sortSummaries(summaries)
+	var expired []string
+	perDB := make(map[string]int)
+	for _, s := range summaries {
+		if s.ID == keep {
+			perDB[s.DBConnection]++
+			continue
+		}
+		if policy.MaxAge > 0 {
+			if t, ok := reportTime(s.GeneratedAt); ok && now.Sub(t) > policy.MaxAge {
+				expired = append(expired, s.ID)
+				continue
+			}
+		}
+		if policy.MaxPerDatabase > 0 {
+			perDB[s.DBConnection]++
+			if perDB[s.DBConnection] > policy.MaxPerDatabase {
+				expired = append(expired, s.ID)
+			}
+		}
+	}
+	return expired
+}
+
+// pruneRepository deletes the reports of repo that fall outside policy, except keep.
+func pruneRepository(repo ReportRepository, policy RetentionPolicy, keep string) (int, error) {
+	if policy.MaxAge <= 0 && policy.MaxPerDatabase <= 0 {
+		return 0, nil
+	}
+	summaries, err := repo.List()
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, id := range expiredReports(summaries, policy, time.Now(), keep) {
+		if err := repo.Delete(id); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	if removed > 0 {
+		logger.Infof("Report retention removed %d report(s)", removed)
+	}
+	return removed, nil
+}
+
+// pruneAfterSave applies the retention policy after id was saved. The report just saved is kept, even
+// an imported one that is already past MaxAge, so its ID can be returned; the next prune removes it.
+// Failures are only logged, since the report itself was written.
+func pruneAfterSave(repo ReportRepository, policy RetentionPolicy, id string) {
+	if _, err := pruneRepository(repo, policy, id); err != nil {
+		logger.Warnf("Failed to apply report retention after saving report %s: %v", id, err)
+	}
+}
+
+// MemoryReportRepository keeps reports in process memory. Reports are lost on restart.
+type MemoryReportRepository struct {
+	mu      sync.RWMutex
+	reports map[string]ReportData
+	policy  RetentionPolicy
+}
+
+// NewMemoryReportRepository creates an empty in-memory repository.
+func NewMemoryReportRepository(policy RetentionPolicy) *MemoryReportRepository {
+	return &MemoryReportRepository{reports: make(map[string]ReportData), policy: policy}
+}
+
+func (m *MemoryReportRepository) Save(id string, data ReportData) error {
+	m.mu.Lock()
+	m.reports[id] = data
+	m.mu.Unlock()
+	pruneAfterSave(m, m.policy, id)
+	return nil
+}
+
+func (m *MemoryReportRepository) Get(id string) (ReportData, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data, ok := m.reports[id]
+	return data, ok, nil
+}
+
+func (m *MemoryReportRepository) List() ([]ReportSummary, error) {
+	m.mu.RLock()
+	summaries := make([]ReportSummary, 0, len(m.reports))
+	for id, data := range m.reports {
+		summaries = append(summaries, summarizeReport(id, data))
+	}
+	m.mu.RUnlock()
+	sortSummaries(summaries)
+	return summaries, nil
+}
+
+func (m *MemoryReportRepository) Delete(id string) error {
+	m.mu.Lock()
+	delete(m.reports, id)
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *MemoryReportRepository) Prune() (int, error) {
+	return pruneRepository(m, m.policy, "")
+}
+
+// FileReportRepository stores each report as <dir>/<id>.json, so reports survive restarts.
+// Summaries are kept in memory and rebuilt from the directory when the repository is opened.
+type FileReportRepository struct {
+	dir       string
+	policy    RetentionPolicy
+	mu        sync.RWMutex
+	summaries map[string]ReportSummary
+}
+
+// NewFileReportRepository opens (creating if needed) a report directory, indexes the reports
+// already in it and applies the retention policy.
+func NewFileReportRepository(dir string, policy RetentionPolicy) (*FileReportRepository, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create report directory %s: %w", dir, err)
+	}
+	repo := &FileReportRepository{dir: dir, policy: policy, summaries: make(map[string]ReportSummary)}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report directory %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		id := strings.TrimSuffix(name, ".json")
+		data, ok, err := repo.Get(id)
+		if err != nil || !ok {
+			logger.Warnf("Skipping unreadable report file %s: %v", name, err)
+			continue
+		}
+		repo.summaries[id] = summarizeReport(id, data)
+	}
+	logger.Infof("Loaded %d report(s) from %s", len(repo.summaries), dir)
+
+	if _, err := repo.Prune(); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+func (f *FileReportRepository) path(id string) (string, error) {
+	if !reportIDPattern.MatchString(id) {
+		return "", fmt.Errorf("invalid report ID %q", id)
+	}
+	return filepath.Join(f.dir, id+".json"), nil
+}
+
+func (f *FileReportRepository) Save(id string, data ReportData) error {
+	path, err := f.path(id)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode report %s: %w", id, err)
+	}
+
+	// 先写临时文件再重命名，避免进程中断时留下半个文件
+	tmp, err := os.CreateTemp(f.dir, id+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save report %s: %w", id, err)
+	}
+	if _, err := tmp.Write(payload); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save report %s: %w", id, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save report %s: %w", id, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save report %s: %w", id, err)
+	}
+
+	f.mu.Lock()
+	f.summaries[id] = summarizeReport(id, data)
+	f.mu.Unlock()
+	pruneAfterSave(f, f.policy, id)
+	return nil
+}
+
+func (f *FileReportRepository) Get(id string) (ReportData, bool, error) {
+	path, err := f.path(id)
+	if err != nil {
+		return ReportData{}, false, nil
+	}
+	payload, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ReportData{}, false, nil
+	}
+	if err != nil {
+		return ReportData{}, false, err
+	}
+	var data ReportData
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return ReportData{}, false, fmt.Errorf("failed to decode report %s: %w", id, err)
+	}
+	return data, true, nil
+}
+
+func (f *FileReportRepository) List() ([]ReportSummary, error) {
+	f.mu.RLock()
+	summaries := make([]ReportSummary, 0, len(f.summaries))
+	for _, s := range f.summaries {
+		summaries = append(summaries, s)
+	}
+	f.mu.RUnlock()
+	sortSummaries(summaries)
+	return summaries, nil
+}
+
+func (f *FileReportRepository) Delete(id string) error {
+	path, err := f.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete report %s: %w", id, err)
+	}
+	f.mu.Lock()
+	delete(f.summaries, id)
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *FileReportRepository) Prune() (int, error) {
+	return pruneRepository(f, f.policy, "")
+}
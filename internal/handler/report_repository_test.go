@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init(false)
+	os.Exit(m.Run())
+}
+
+// repoReport returns a report for db generated age ago.
+func repoReport(db string, age time.Duration) ReportData {
+	return ReportData{
+		Title:        "Report",
+		DBConnection: db,
+		GeneratedAt:  time.Now().Add(-age).Format("2006-01-02 15:04:05"),
+		Modules:      []ReportModule{{ID: "dbinfo"}, {ID: "storage", Error: "ORA-00942"}},
+	}
+}
+
+func TestExpiredReports(t *testing.T) {
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.Local)
+	at := func(age time.Duration) string { return now.Add(-age).Format("2006-01-02 15:04:05") }
+	summaries := func() []ReportSummary {
+		return []ReportSummary{
+			{ID: "db1-old", DBConnection: "db1", GeneratedAt: at(72 * time.Hour)},
+			{ID: "db1-new", DBConnection: "db1", GeneratedAt: at(time.Hour)},
+			{ID: "db1-mid", DBConnection: "db1", GeneratedAt: at(2 * time.Hour)},
+			{ID: "db2-a", DBConnection: "db2", GeneratedAt: at(3 * time.Hour)},
+			{ID: "db2-b", DBConnection: "db2", GeneratedAt: at(3 * time.Hour)},
+			{ID: "db3-bad", DBConnection: "db3", GeneratedAt: "last Tuesday"},
+			{ID: "db3-empty", DBConnection: "db3"},
+		}
+	}
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+		keep   string
+		want   []string
+	}{
+		{name: "no limits", want: nil},
+		{name: "age", policy: RetentionPolicy{MaxAge: 48 * time.Hour}, want: []string{"db1-old"}},
+		{name: "age at the limit", policy: RetentionPolicy{MaxAge: 2 * time.Hour}, want: []string{"db2-a", "db2-b", "db1-old"}},
+		// Unparsable times are never too old, but sort by their text and count towards their database
+		{name: "per database", policy: RetentionPolicy{MaxPerDatabase: 1}, want: []string{"db1-mid", "db2-b", "db1-old", "db3-empty"}},
+		{name: "per database keeps equal times in ID order", policy: RetentionPolicy{MaxPerDatabase: 2}, want: []string{"db1-old"}},
+		{name: "aged reports do not count per database", policy: RetentionPolicy{MaxAge: 48 * time.Hour, MaxPerDatabase: 2}, want: []string{"db1-old"}},
+		{name: "age and count", policy: RetentionPolicy{MaxAge: 150 * time.Minute, MaxPerDatabase: 1}, want: []string{"db1-mid", "db2-a", "db2-b", "db1-old", "db3-empty"}},
+		{name: "kept report past the age limit", policy: RetentionPolicy{MaxAge: 48 * time.Hour}, keep: "db1-old", want: nil},
+		{name: "kept report past both limits", policy: RetentionPolicy{MaxAge: 48 * time.Hour, MaxPerDatabase: 1}, keep: "db1-old", want: []string{"db1-mid", "db2-b", "db3-empty"}},
+		{name: "kept report counts per database", policy: RetentionPolicy{MaxPerDatabase: 1}, keep: "db1-new", want: []string{"db1-mid", "db2-b", "db1-old", "db3-empty"}},
+	}
+	for _, tt := range tests {
+		if got := expiredReports(summaries(), tt.policy, now, tt.keep); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expired = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSaveKeepsTheSavedReport(t *testing.T) {
+	fileRepo, err := NewFileReportRepository(t.TempDir(), RetentionPolicy{MaxAge: 24 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repos := map[string]ReportRepository{
+		"memory": NewMemoryReportRepository(RetentionPolicy{MaxAge: 24 * time.Hour}),
+		"file":   fileRepo,
+	}
+	for name, repo := range repos {
+		// An imported report that is already past the age limit stays until the next save
+		if err := repo.Save("imported", repoReport("db1", 30*24*time.Hour)); err != nil {
+			t.Fatalf("%s: Save: %v", name, err)
+		}
+		if _, ok, _ := repo.Get("imported"); !ok {
+			t.Errorf("%s: imported report removed by its own save", name)
+		}
+		if err := repo.Save("fresh", repoReport("db1", time.Minute)); err != nil {
+			t.Fatalf("%s: Save: %v", name, err)
+		}
+		if _, ok, _ := repo.Get("imported"); ok {
+			t.Errorf("%s: expired report kept after the next save", name)
+		}
+		if _, ok, _ := repo.Get("fresh"); !ok {
+			t.Errorf("%s: fresh report missing", name)
+		}
+	}
+}
+
+func TestSaveIgnoresPruneFailures(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := NewFileReportRepository(dir, RetentionPolicy{MaxPerDatabase: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Save("first", repoReport("db1", time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	// A non-empty directory in place of the report file makes its deletion fail
+	path := filepath.Join(dir, "first.json")
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(path, "keep"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.Save("second", repoReport("db1", time.Minute)); err != nil {
+		t.Errorf("Save returned the prune error: %v", err)
+	}
+	if _, ok, err := repo.Get("second"); !ok || err != nil {
+		t.Errorf("saved report missing: %v", err)
+	}
+	if _, err := repo.Prune(); err == nil {
+		t.Error("Prune succeeded although the older report could not be deleted")
+	}
+}
+
+func TestFileReportRepository(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := NewFileReportRepository(dir, RetentionPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, data := range map[string]ReportData{
+		"20250601-crm": repoReport("crm:1521/CRM", 2*time.Hour),
+		"20250602-crm": repoReport("crm:1521/CRM", time.Hour),
+		"old-erp":      repoReport("erp:1521/ERP", 90*24*time.Hour),
+	} {
+		if err := repo.Save(id, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Files that are not readable reports are skipped when the directory is indexed
+	for name, content := range map[string]string{
+		"broken.json":           "{",
+		"notes.txt":             "not a report",
+		"20250603-crm.123.tmp":  "{}",
+		"invalid id.json":       "{}",
+		".hidden-report.json":   "{}",
+		"subdir.json/info.json": "{}",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reopened, err := NewFileReportRepository(dir, RetentionPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	summaries, err := reopened.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, s := range summaries {
+		ids = append(ids, s.ID)
+	}
+	if want := []string{"20250602-crm", "20250601-crm", "old-erp"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("reloaded reports = %q, want %q", ids, want)
+	}
+	if s := summaries[0]; s.DBConnection != "crm:1521/CRM" || s.ErrorCount != 1 || !reflect.DeepEqual(s.Modules, []string{"dbinfo", "storage"}) {
+		t.Errorf("reloaded summary = %+v", s)
+	}
+	if _, _, err := reopened.Get("broken"); err == nil || !strings.Contains(err.Error(), "failed to decode report broken") {
+		t.Errorf("Get(broken) error = %v", err)
+	}
+
+	// Opening with a retention policy prunes right away
+	pruned, err := NewFileReportRepository(dir, RetentionPolicy{MaxAge: 30 * 24 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old-erp.json")); !os.IsNotExist(err) {
+		t.Errorf("expired report file not removed: %v", err)
+	}
+	if summaries, _ := pruned.List(); len(summaries) != 2 {
+		t.Errorf("%d reports after pruning, want 2", len(summaries))
+	}
+
+	if err := pruned.Delete("20250601-crm"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := pruned.Get("20250601-crm"); ok || err != nil {
+		t.Errorf("deleted report still readable: %v, %v", ok, err)
+	}
+	if summaries, _ := pruned.List(); len(summaries) != 1 || summaries[0].ID != "20250602-crm" {
+		t.Errorf("reports after delete = %+v", summaries)
+	}
+	if err := pruned.Delete("20250601-crm"); err != nil {
+		t.Errorf("deleting a missing report: %v", err)
+	}
+
+	for _, id := range []string{"", "../escape", "a/b", `a\b`, ".hidden", "-flag", "with space", "invalid id"} {
+		if err := pruned.Save(id, repoReport("db1", 0)); err == nil || !strings.Contains(err.Error(), "invalid report ID") {
+			t.Errorf("Save(%q) error = %v", id, err)
+		}
+		if _, ok, err := pruned.Get(id); ok || err != nil {
+			t.Errorf("Get(%q) = %v, %v, want not found", id, ok, err)
+		}
+		if err := pruned.Delete(id); err == nil || !strings.Contains(err.Error(), "invalid report ID") {
+			t.Errorf("Delete(%q) error = %v", id, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "escape.json")); !os.IsNotExist(err) {
+		t.Errorf("report written outside the report directory")
+	}
+}
@@ -0,0 +1,379 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReportSchemaVersion is the version of the JSON report format described in docs/report-schema.json.
+// Readers accept any document with the same major version; new optional fields bump the minor version.
+const ReportSchemaVersion = "1.0"
+
+// GeneratorVersion is recorded in exported documents; main sets it to the application version.
+var GeneratorVersion = "dev"
+
+// ReportDocument is the stable, machine-readable form of a report.
+// Unlike ReportData it contains no pre-rendered HTML or Chart.js options.
+type ReportDocument struct {
+	SchemaVersion string           `json:"schemaVersion"`
+	Generator     ReportGenerator  `json:"generator"`
+	Report        ReportMetadata   `json:"report"`
+	HealthScore   int              `json:"healthScore"`
+	Modules       []DocumentModule `json:"modules"`
+}
+
+// ReportGenerator identifies the program that wrote the document.
+type ReportGenerator struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// ReportMetadata describes the inspected database and how the report was generated.
+type ReportMetadata struct {
+	Title            string `json:"title"`
+	BusinessName     string `json:"businessName,omitempty"`
+	DBName           string `json:"dbName"`
+	DBConnection     string `json:"dbConnection"`
+	DBFullInfo       string `json:"dbFullInfo,omitempty"`
+	SessionUser      string `json:"sessionUser,omitempty"`
+	ProxyUser        string `json:"proxyUser,omitempty"`
+	GeneratedAt      string `json:"generatedAt"` // RFC 3339
+	Lang             string `json:"lang"`
+	ThresholdProfile string `json:"thresholdProfile,omitempty"`
+}
+
+// DocumentModule is one inspection module of a ReportDocument.
+type DocumentModule struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name,omitempty"`
+	Title       string          `json:"title"`
+	Icon        string          `json:"icon,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	HealthScore int             `json:"healthScore"`
+	Cards       []DocumentCard  `json:"cards"`
+	Tables      []DocumentTable `json:"tables"`
+	Charts      []DocumentChart `json:"charts"`
+	Findings    []Finding       `json:"findings"`
+}
+
+// DocumentCard is a card with its display value and, when the value is numeric, the parsed number.
+type DocumentCard struct {
+	Title  string   `json:"title"`
+	Value  string   `json:"value"`
+	Number *float64 `json:"number,omitempty"`
+	Unit   string   `json:"unit,omitempty"` // e.g. "%" or "GB", only set together with Number
+}
+
+// DocumentTable is a report table; every row has one value per column.
+type DocumentTable struct {
+	Key     string     `json:"key,omitempty"`
+	Name    string     `json:"name"`
+	Columns []string   `json:"columns"`
+	Rows    [][]string `json:"rows"`
+	Notes   string     `json:"notes,omitempty"`
+}
+
+// DocumentChart is a chart reduced to its numeric series.
+type DocumentChart struct {
+	ID     string           `json:"id"`
+	Type   string           `json:"type"` // line or bar
+	Title  string           `json:"title,omitempty"`
+	XAxis  DocumentAxis     `json:"xAxis"`
+	YAxis  DocumentAxis     `json:"yAxis"`
+	Series []DocumentSeries `json:"series"`
+}
+
+// DocumentAxis describes a chart axis. Type is "time" or "category" for the x axis and "linear" for the y axis.
+type DocumentAxis struct {
+	Type  string `json:"type"`
+	Title string `json:"title,omitempty"`
+}
+
+// DocumentSeries is one named series of (x, y) points.
+type DocumentSeries struct {
+	Label           string          `json:"label"`
+	Color           string          `json:"color,omitempty"`
+	BackgroundColor string          `json:"backgroundColor,omitempty"`
+	Fill            bool            `json:"fill,omitempty"`
+	Points          []DocumentPoint `json:"points"`
+}
+
+// DocumentPoint is a single chart value. X is a timestamp for time axes, otherwise a category label.
+type DocumentPoint struct {
+	X string  `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// cardNumberPattern matches card values such as "42", "85.3%" or "12.5 GB".
+var cardNumberPattern = regexp.MustCompile(`^\s*(-?\d+(?:\.\d+)?)\s*(%|[A-Za-z]{1,5})?\s*$`)
+
+// NewReportDocument converts reportData into the versioned JSON report format.
+func NewReportDocument(data ReportData) ReportDocument {
+	doc := ReportDocument{
+		SchemaVersion: ReportSchemaVersion,
+		Generator:     ReportGenerator{Name: "inspect4oracle", Version: GeneratorVersion},
+		Report: ReportMetadata{
+			Title:            data.Title,
+			BusinessName:     data.BusinessName,
+			DBName:           data.DBName,
+			DBConnection:     data.DBConnection,
+			DBFullInfo:       data.DBFullInfo,
+			SessionUser:      data.SessionUser,
+			ProxyUser:        data.ProxyUser,
+			GeneratedAt:      data.GeneratedAt,
+			Lang:             data.Lang,
+			ThresholdProfile: data.ThresholdProfile,
+		},
+		HealthScore: data.HealthScore,
+		Modules:     make([]DocumentModule, 0, len(data.Modules)),
+	}
+	if t, ok := reportTime(data.GeneratedAt); ok {
+		doc.Report.GeneratedAt = t.Format(time.RFC3339)
+	}
+
+	for _, module := range data.Modules {
+		dm := DocumentModule{
+			ID:          module.ID,
+			Name:        module.Name,
+			Title:       module.Title,
+			Icon:        module.Icon,
+			Description: module.Description,
+			Error:       module.Error,
+			HealthScore: module.HealthScore,
+			Cards:       make([]DocumentCard, 0, len(module.Cards)),
+			Tables:      make([]DocumentTable, 0, len(module.Tables)),
+			Charts:      make([]DocumentChart, 0, len(module.Charts)),
+			Findings:    module.Findings,
+		}
+		if dm.Findings == nil {
+			dm.Findings = []Finding{}
+		}
+		for _, card := range module.Cards {
+			dc := DocumentCard{Title: card.Title, Value: card.Value}
+			if m := cardNumberPattern.FindStringSubmatch(card.Value); m != nil {
+				if v, err := strconv.ParseFloat(m[1], 64); err == nil {
+					dc.Number, dc.Unit = &v, m[2]
+				}
+			}
+			dm.Cards = append(dm.Cards, dc)
+		}
+		for _, table := range module.Tables {
+			if table == nil {
+				continue
+			}
+			dt := DocumentTable{Key: table.Key, Name: table.Name, Columns: table.Headers, Rows: make([][]string, 0, len(table.Rows)), Notes: table.Notes}
+			if dt.Columns == nil {
+				dt.Columns = []string{}
+			}
+			for _, row := range table.Rows {
+				// Pad or cut rows so that consumers can rely on one value per column.
+				fixed := make([]string, len(dt.Columns))
+				copy(fixed, row)
+				dt.Rows = append(dt.Rows, fixed)
+			}
+			dm.Tables = append(dm.Tables, dt)
+		}
+		for _, chart := range module.Charts {
+			if dc, ok := documentChart(chart); ok {
+				dm.Charts = append(dm.Charts, dc)
+			}
+		}
+		doc.Modules = append(doc.Modules, dm)
+	}
+	return doc
+}
+
+// documentChart extracts the series of a Chart.js chart. Every chart this tool builds stores {"datasets":[...]};
+// anything else cannot be read back into series, so the chart is dropped from the document rather than failing the export.
+func documentChart(chart ReportChart) (DocumentChart, bool) {
+	var data ChartJSData
+	if err := json.Unmarshal([]byte(chart.DatasetsJSON), &data); err != nil {
+		return DocumentChart{}, false
+	}
+	var options ChartJSOptions
+	if chart.OptionsJSON != "" {
+		_ = json.Unmarshal([]byte(chart.OptionsJSON), &options)
+	}
+
+	dc := DocumentChart{
+		ID:     chart.ChartID,
+		Type:   chart.Type,
+		Title:  options.Plugins.Title.Text,
+		XAxis:  DocumentAxis{Type: "category", Title: options.Scales.X.Title.Text},
+		YAxis:  DocumentAxis{Type: "linear", Title: options.Scales.Y.Title.Text},
+		Series: make([]DocumentSeries, 0, len(data.Datasets)),
+	}
+	if options.Scales.X.Type == "time" {
+		dc.XAxis.Type = "time"
+	}
+	for _, ds := range data.Datasets {
+		series := DocumentSeries{Label: ds.Label, Color: ds.BorderColor, BackgroundColor: ds.BackgroundColor, Fill: ds.Fill, Points: make([]DocumentPoint, 0, len(ds.Data))}
+		for _, p := range ds.Data {
+			if y, ok := chartValue(p.Y); ok {
+				series.Points = append(series.Points, DocumentPoint{X: fmt.Sprint(p.X), Y: y})
+			}
+		}
+		dc.Series = append(dc.Series, series)
+	}
+	return dc, true
+}
+
+// ReadReportDocument decodes a JSON report document and converts it back into ReportData,
+// so that it can be stored and rendered like a report produced by an inspection.
+func ReadReportDocument(r io.Reader) (ReportData, error) {
+	var doc ReportDocument
+	dec := json.NewDecoder(r)
+	if err := dec.Decode(&doc); err != nil {
+		return ReportData{}, fmt.Errorf("invalid report document: %w", err)
+	}
+	return doc.ReportData()
+}
+
+// ReportData validates the document and converts it into the structure used by the report template.
+func (doc ReportDocument) ReportData() (ReportData, error) {
+	if doc.SchemaVersion == "" {
+		return ReportData{}, errors.New("invalid report document: schemaVersion is missing")
+	}
+	major := strings.SplitN(ReportSchemaVersion, ".", 2)[0]
+	if strings.SplitN(doc.SchemaVersion, ".", 2)[0] != major {
+		return ReportData{}, fmt.Errorf("unsupported report schema version %q (supported: %s.x)", doc.SchemaVersion, major)
+	}
+	meta := doc.Report
+	switch meta.Lang {
+	case "zh", "en", "jp":
+	case "":
+		meta.Lang = "en"
+	default:
+		return ReportData{}, fmt.Errorf("invalid report document: unsupported language %q", meta.Lang)
+	}
+	if meta.Title == "" {
+		meta.Title = langText("Oracle 数据库巡检报告", "Oracle Database Inspection Report", "Oracleデータベース検査レポート", meta.Lang)
+	}
+
+	data := ReportData{
+		Lang:             meta.Lang,
+		Title:            meta.Title,
+		BusinessName:     meta.BusinessName,
+		DBName:           meta.DBName,
+		DBConnection:     meta.DBConnection,
+		DBFullInfo:       meta.DBFullInfo,
+		SessionUser:      meta.SessionUser,
+		ProxyUser:        meta.ProxyUser,
+		GeneratedAt:      meta.GeneratedAt,
+		ThresholdProfile: meta.ThresholdProfile,
+		HealthScore:      doc.HealthScore,
+		Modules:          make([]ReportModule, 0, len(doc.Modules)),
+		ReportSections:   make([]ReportSection, 0, len(doc.Modules)),
+	}
+	if t, err := time.Parse(time.RFC3339, meta.GeneratedAt); err == nil {
+		data.GeneratedAt = t.Local().Format("2006-01-02 15:04:05")
+	} else if _, ok := reportTime(meta.GeneratedAt); !ok {
+		return ReportData{}, fmt.Errorf("invalid report document: generatedAt %q is not an RFC 3339 timestamp", meta.GeneratedAt)
+	}
+
+	seen := make(map[string]bool, len(doc.Modules))
+	for i, dm := range doc.Modules {
+		if dm.ID == "" {
+			return ReportData{}, fmt.Errorf("invalid report document: module %d has no id", i+1)
+		}
+		if seen[dm.ID] {
+			return ReportData{}, fmt.Errorf("invalid report document: duplicate module id %q", dm.ID)
+		}
+		seen[dm.ID] = true
+
+		module := ReportModule{
+			ID:          dm.ID,
+			Name:        dm.Name,
+			Title:       dm.Title,
+			Icon:        dm.Icon,
+			Description: dm.Description,
+			Error:       dm.Error,
+			HealthScore: dm.HealthScore,
+			Findings:    dm.Findings,
+		}
+		if module.Name == "" {
+			module.Name = dm.Title
+		}
+		for _, card := range dm.Cards {
+			module.Cards = append(module.Cards, ReportCard{Title: card.Title, Value: card.Value})
+		}
+		for _, table := range dm.Tables {
+			module.Tables = append(module.Tables, &ReportTable{Key: table.Key, Name: table.Name, Headers: table.Columns, Rows: table.Rows, Notes: table.Notes})
+		}
+		for j, chart := range dm.Charts {
+			rc, err := chart.reportChart()
+			if err != nil {
+				return ReportData{}, fmt.Errorf("invalid report document: module %q chart %d: %w", dm.ID, j+1, err)
+			}
+			if rc.ChartID == "" {
+				rc.ChartID = fmt.Sprintf("%s-%d", dm.ID, j+1)
+			}
+			module.Charts = append(module.Charts, rc)
+		}
+		data.Modules = append(data.Modules, module)
+		data.ReportSections = append(data.ReportSections, ReportSection{ID: module.ID, Name: module.Name})
+	}
+	return data, nil
+}
+
+// reportChart rebuilds the Chart.js datasets and options of a document chart.
+func (c DocumentChart) reportChart() (ReportChart, error) {
+	if c.Type != "line" && c.Type != "bar" {
+		return ReportChart{}, fmt.Errorf("unsupported chart type %q", c.Type)
+	}
+	data := ChartJSData{Datasets: make([]ChartDataset, 0, len(c.Series))}
+	for _, s := range c.Series {
+		ds := ChartDataset{Label: s.Label, BorderColor: s.Color, BackgroundColor: s.BackgroundColor, Fill: s.Fill, Data: make([]ChartDataPoint, 0, len(s.Points))}
+		for _, p := range s.Points {
+			ds.Data = append(ds.Data, ChartDataPoint{X: p.X, Y: p.Y})
+		}
+		data.Datasets = append(data.Datasets, ds)
+	}
+
+	options := ChartJSOptions{
+		Responsive:          true,
+		MaintainAspectRatio: false,
+		Plugins: ChartPluginsOptions{
+			Title:  ChartPluginTitleOptions{Display: c.Title != "", Text: c.Title},
+			Legend: ChartPluginLegendOptions{Display: len(c.Series) > 1, Position: "top"},
+		},
+		Scales: ChartScalesOptions{
+			X: ChartScaleOptions{Title: ChartScaleTitleOptions{Display: c.XAxis.Title != "", Text: c.XAxis.Title}},
+			Y: ChartScaleOptions{BeginAtZero: true, Title: ChartScaleTitleOptions{Display: c.YAxis.Title != "", Text: c.YAxis.Title}},
+		},
+	}
+	if c.XAxis.Type == "time" {
+		options.Scales.X.Type = "time"
+		options.Scales.X.Time = &ChartTimeScaleOptions{
+			TooltipFormat: "yyyy-MM-dd HH:mm",
+			DisplayFormats: &ChartTimeDisplayFormats{
+				Minute: "HH:mm",
+				Hour:   "MM-dd HH:mm",
+				Day:    "yyyy-MM-dd",
+			},
+		}
+	}
+
+	datasetsJSON, err := json.Marshal(data)
+	if err != nil {
+		return ReportChart{}, err
+	}
+	optionsJSON, err := json.Marshal(options)
+	if err != nil {
+		return ReportChart{}, err
+	}
+	return ReportChart{
+		ChartID:      c.ID,
+		Type:         c.Type,
+		DatasetsJSON: template.HTML(string(datasetsJSON)),
+		OptionsJSON:  template.HTML(string(optionsJSON)),
+	}, nil
+}
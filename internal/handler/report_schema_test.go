@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// chartJSON marshals v for the DatasetsJSON and OptionsJSON fields of a ReportChart.
+func chartJSON(t *testing.T, v interface{}) template.HTML {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return template.HTML(b)
+}
+
+// sampleSchemaReport covers every part of a module that the JSON report format carries.
+func sampleSchemaReport(t *testing.T) ReportData {
+	datasets := ChartJSData{Datasets: []ChartDataset{
+		{Label: "DB CPU", BorderColor: "#0d6efd", Data: []ChartDataPoint{
+			{X: "2025-06-01T10:00:00", Y: 1.5},
+			{X: "2025-06-01T11:00:00", Y: "2.25"}, // Numeric strings are kept
+			{X: "2025-06-01T12:00:00", Y: "n/a"},  // Other values are dropped
+		}},
+		{Label: "Wait", BackgroundColor: "rgba(255,0,0,0.3)", Fill: true, Data: []ChartDataPoint{{X: "2025-06-01T10:00:00", Y: 0.5}}},
+	}}
+	options := ChartJSOptions{
+		Plugins: ChartPluginsOptions{Title: ChartPluginTitleOptions{Display: true, Text: "Load"}},
+		Scales: ChartScalesOptions{
+			X: ChartScaleOptions{Type: "time", Title: ChartScaleTitleOptions{Text: "Time"}},
+			Y: ChartScaleOptions{Title: ChartScaleTitleOptions{Text: "Sessions"}},
+		},
+	}
+	return ReportData{
+		Lang:         "en",
+		Title:        "Oracle Database Inspection Report",
+		BusinessName: "CRM",
+		DBName:       "ORCL",
+		DBConnection: "10.0.0.7:1521/ORCLPDB",
+		GeneratedAt:  "2025-06-01 10:30:00",
+		HealthScore:  81,
+		Modules: []ReportModule{
+			{
+				ID: "storage", Name: "Storage", Title: "Storage", HealthScore: 62,
+				Cards: []ReportCard{{Title: "Used", Value: "85.3%"}, {Title: "Size", Value: "12.5 GB"}, {Title: "Status", Value: "ONLINE"}},
+				Tables: []*ReportTable{{
+					Key: "tablespaces", Name: "Tablespaces", Headers: []string{"Name", "Used"},
+					Rows:  [][]string{{"USERS", "95%", "extra"}, {"TEMP"}},
+					Notes: "Sizes in MB",
+				}},
+				Findings: []Finding{{Severity: SeverityCritical, Message: "Tablespace USERS is 95% full", Recommendation: "Add a datafile"}},
+			},
+			{
+				ID: "performance", Name: "Performance", HealthScore: 100,
+				Charts: []ReportChart{
+					{ChartID: "load", Type: "line", DatasetsJSON: chartJSON(t, datasets), OptionsJSON: chartJSON(t, options)},
+					{ChartID: "broken", Type: "bar", DatasetsJSON: "not json"},
+				},
+			},
+		},
+	}
+}
+
+func TestReportDocumentRoundTrip(t *testing.T) {
+	doc := NewReportDocument(sampleSchemaReport(t))
+
+	storage := doc.Modules[0]
+	if c := storage.Cards[0]; c.Number == nil || *c.Number != 85.3 || c.Unit != "%" {
+		t.Errorf("card %+v, want number 85.3 with unit %%", c)
+	}
+	if c := storage.Cards[1]; c.Number == nil || *c.Number != 12.5 || c.Unit != "GB" {
+		t.Errorf("card %+v, want number 12.5 with unit GB", c)
+	}
+	if c := storage.Cards[2]; c.Number != nil || c.Unit != "" {
+		t.Errorf("card %+v, want no number", c)
+	}
+	if want := [][]string{{"USERS", "95%"}, {"TEMP", ""}}; !reflect.DeepEqual(storage.Tables[0].Rows, want) {
+		t.Errorf("rows = %q, want cut and padded %q", storage.Tables[0].Rows, want)
+	}
+
+	charts := doc.Modules[1].Charts
+	if len(charts) != 1 {
+		t.Fatalf("got %d charts, want the unreadable one dropped", len(charts))
+	}
+	want := DocumentChart{
+		ID: "load", Type: "line", Title: "Load",
+		XAxis: DocumentAxis{Type: "time", Title: "Time"},
+		YAxis: DocumentAxis{Type: "linear", Title: "Sessions"},
+		Series: []DocumentSeries{
+			{Label: "DB CPU", Color: "#0d6efd", Points: []DocumentPoint{{"2025-06-01T10:00:00", 1.5}, {"2025-06-01T11:00:00", 2.25}}},
+			{Label: "Wait", BackgroundColor: "rgba(255,0,0,0.3)", Fill: true, Points: []DocumentPoint{{"2025-06-01T10:00:00", 0.5}}},
+		},
+	}
+	if !reflect.DeepEqual(charts[0], want) {
+		t.Errorf("chart = %+v\nwant %+v", charts[0], want)
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(doc); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ReadReportDocument(&buf)
+	if err != nil {
+		t.Fatalf("ReadReportDocument: %v", err)
+	}
+	if data.GeneratedAt != "2025-06-01 10:30:00" || data.HealthScore != 81 || data.BusinessName != "CRM" {
+		t.Errorf("metadata = %q, %d, %q", data.GeneratedAt, data.HealthScore, data.BusinessName)
+	}
+	if want := []ReportSection{{"storage", "Storage"}, {"performance", "Performance"}}; !reflect.DeepEqual(data.ReportSections, want) {
+		t.Errorf("sections = %+v", data.ReportSections)
+	}
+	if got := data.Modules[0].Findings; len(got) != 1 || got[0].Recommendation != "Add a datafile" {
+		t.Errorf("findings = %+v", got)
+	}
+	// Reading the document back and writing it again must not change it.
+	if again := NewReportDocument(data); !reflect.DeepEqual(again, doc) {
+		t.Errorf("document changed after a round trip:\n%+v\nwant\n%+v", again, doc)
+	}
+}
+
+func TestDocumentChartDropsUnreadableDatasets(t *testing.T) {
+	tests := []struct {
+		name     string
+		datasets string
+		kept     bool
+		series   int
+	}{
+		{"datasets object", `{"datasets":[{"label":"a","data":[{"x":"1","y":2}]}]}`, true, 1},
+		{"object without datasets", `{"labels":["a"]}`, true, 0},
+		{"bare array", `[{"label":"a","data":[]}]`, false, 0},
+		{"datasets not a list", `{"datasets":{"label":"a"}}`, false, 0},
+		{"not JSON", `<table>`, false, 0},
+		{"empty", ``, false, 0},
+	}
+	for _, tt := range tests {
+		dc, ok := documentChart(ReportChart{ChartID: "c", Type: "line", DatasetsJSON: template.HTML(tt.datasets)})
+		if ok != tt.kept || len(dc.Series) != tt.series {
+			t.Errorf("%s: kept = %v with %d series, want %v with %d", tt.name, ok, len(dc.Series), tt.kept, tt.series)
+		}
+	}
+}
+
+// schemaDocument returns a minimal valid document in JSON, changed by edit.
+func schemaDocument(t *testing.T, edit func(doc map[string]interface{})) string {
+	t.Helper()
+	doc := map[string]interface{}{
+		"schemaVersion": "1.0",
+		"report":        map[string]interface{}{"dbName": "ORCL", "dbConnection": "db1:1521/ORCL", "generatedAt": "2025-06-01T10:30:00Z", "lang": "en"},
+		"modules": []interface{}{
+			map[string]interface{}{"id": "dbinfo", "title": "Database"},
+			map[string]interface{}{"id": "performance", "title": "Performance", "charts": []interface{}{
+				map[string]interface{}{"id": "load", "type": "line", "series": []interface{}{
+					map[string]interface{}{"label": "CPU", "points": []interface{}{map[string]interface{}{"x": "a", "y": 1}}},
+				}},
+			}},
+		},
+	}
+	edit(doc)
+	b, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestReadReportDocument(t *testing.T) {
+	report := func(doc map[string]interface{}) map[string]interface{} { return doc["report"].(map[string]interface{}) }
+	chart := func(doc map[string]interface{}) map[string]interface{} {
+		module := doc["modules"].([]interface{})[1].(map[string]interface{})
+		return module["charts"].([]interface{})[0].(map[string]interface{})
+	}
+	tests := []struct {
+		name string
+		edit func(doc map[string]interface{})
+		err  string // Empty when the document is valid
+	}{
+		{"valid", func(doc map[string]interface{}) {}, ""},
+		{"newer minor version", func(doc map[string]interface{}) { doc["schemaVersion"] = "1.7" }, ""},
+		{"missing schemaVersion", func(doc map[string]interface{}) { delete(doc, "schemaVersion") }, "schemaVersion is missing"},
+		{"other major version", func(doc map[string]interface{}) { doc["schemaVersion"] = "2.0" }, `unsupported report schema version "2.0"`},
+		{"schemaVersion not a string", func(doc map[string]interface{}) { doc["schemaVersion"] = 1 }, "invalid report document"},
+		{"missing lang", func(doc map[string]interface{}) { delete(report(doc), "lang") }, ""},
+		{"unknown lang", func(doc map[string]interface{}) { report(doc)["lang"] = "fr" }, `unsupported language "fr"`},
+		{"legacy generatedAt", func(doc map[string]interface{}) { report(doc)["generatedAt"] = "2025-06-01 10:30:00" }, ""},
+		{"unparsable generatedAt", func(doc map[string]interface{}) { report(doc)["generatedAt"] = "yesterday" }, `generatedAt "yesterday" is not an RFC 3339 timestamp`},
+		{"missing generatedAt", func(doc map[string]interface{}) { delete(report(doc), "generatedAt") }, "is not an RFC 3339 timestamp"},
+		{"module without id", func(doc map[string]interface{}) {
+			doc["modules"].([]interface{})[0].(map[string]interface{})["id"] = ""
+		}, "module 1 has no id"},
+		{"duplicate module id", func(doc map[string]interface{}) {
+			doc["modules"].([]interface{})[1].(map[string]interface{})["id"] = "dbinfo"
+		}, `duplicate module id "dbinfo"`},
+		{"invalid chart type", func(doc map[string]interface{}) { chart(doc)["type"] = "pie" }, `module "performance" chart 1: unsupported chart type "pie"`},
+		{"missing chart type", func(doc map[string]interface{}) { delete(chart(doc), "type") }, `unsupported chart type ""`},
+		{"non-numeric point", func(doc map[string]interface{}) {
+			series := chart(doc)["series"].([]interface{})[0].(map[string]interface{})
+			series["points"] = []interface{}{map[string]interface{}{"x": "a", "y": "high"}}
+		}, "invalid report document"},
+		{"null point value", func(doc map[string]interface{}) {
+			series := chart(doc)["series"].([]interface{})[0].(map[string]interface{})
+			series["points"] = []interface{}{map[string]interface{}{"x": "a", "y": nil}}
+		}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ReadReportDocument(strings.NewReader(schemaDocument(t, tt.edit)))
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("ReadReportDocument: %v", err)
+				}
+				if data.Lang == "" || len(data.Modules) != 2 || data.Modules[1].Charts[0].ChartID != "load" {
+					t.Errorf("data = %+v", data)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+
+	if _, err := ReadReportDocument(strings.NewReader("{")); err == nil || !strings.Contains(err.Error(), "invalid report document") {
+		t.Errorf("truncated document error = %v", err)
+	}
+}
@@ -0,0 +1,335 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxTextCellWidth caps the width of a column in the plain-text renderer; longer values are cut.
+const maxTextCellWidth = 40
+
+// sessionUserText describes the database user the inspection ran as, with the proxy user if any.
+func sessionUserText(data ReportData) string {
+	if data.ProxyUser == "" {
+		return data.SessionUser
+	}
+	return fmt.Sprintf(langText("%s（代理用户: %s）", "%s (proxy user: %s)", "%s（プロキシユーザー: %s）", data.Lang), data.SessionUser, data.ProxyUser)
+}
+
+// reportMetadataRows returns the non-empty label/value pairs describing the report.
+func reportMetadataRows(data ReportData) [][]string {
+	lang := data.Lang
+	rows := [][]string{
+		{langText("业务名称", "Business Name", "業務名", lang), data.BusinessName},
+		{langText("数据库", "Database", "データベース", lang), data.DBFullInfo},
+		{langText("连接", "Connection", "接続", lang), data.DBConnection},
+		{langText("巡检用户", "Inspected As", "検査ユーザー", lang), sessionUserText(data)},
+		{langText("巡检时间", "Inspection Time", "検査時間", lang), data.GeneratedAt},
+		{langText("阈值配置", "Threshold Profile", "しきい値プロファイル", lang), data.ThresholdProfile},
+		{langText("健康评分", "Health Score", "ヘルススコア", lang), strconv.Itoa(data.HealthScore)},
+	}
+	kept := rows[:0]
+	for _, row := range rows {
+		if row[1] != "" {
+			kept = append(kept, row)
+		}
+	}
+	return kept
+}
+
+// chartSummary describes a chart as one row per series: label, point count, min, avg and max.
+func chartSummary(chart ReportChart, lang string) (string, []string, [][]string, bool) {
+	dc, ok := documentChart(chart)
+	if !ok || len(dc.Series) == 0 {
+		return "", nil, nil, false
+	}
+	title := dc.Title
+	if title == "" {
+		title = dc.ID
+	}
+	headers := []string{
+		langText("序列", "Series", "系列", lang),
+		langText("点数", "Points", "点数", lang),
+		langText("最小值", "Min", "最小", lang),
+		langText("平均值", "Avg", "平均", lang),
+		langText("最大值", "Max", "最大", lang),
+	}
+	var rows [][]string
+	for _, s := range dc.Series {
+		if len(s.Points) == 0 {
+			rows = append(rows, []string{s.Label, "0", "-", "-", "-"})
+			continue
+		}
+		low, high, sum := math.Inf(1), math.Inf(-1), 0.0
+		for _, p := range s.Points {
+			low, high, sum = math.Min(low, p.Y), math.Max(high, p.Y), sum+p.Y
+		}
+		rows = append(rows, []string{s.Label, strconv.Itoa(len(s.Points)), formatStat(low), formatStat(sum / float64(len(s.Points))), formatStat(high)})
+	}
+	return title, headers, rows, true
+}
+
+// formatStat prints a value with at most two decimals.
+func formatStat(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// exportMarkdown writes the report as GitHub-flavoured Markdown, e.g. for wiki pages and change tickets.
+// Cards become definition lists, tables become pipe tables and charts are summarized per series.
+func exportMarkdown(w io.Writer, data ReportData) error {
+	lang := data.Lang
+	text := findingsText(lang)
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", mdEscape(data.Title))
+	mdTable(&b, []string{langText("项目", "Item", "項目", lang), langText("值", "Value", "値", lang)}, reportMetadataRows(data))
+
+	fmt.Fprintf(&b, "## %s\n\n", mdEscape(text["Title"]))
+	findings, _ := collectFindings(data.Modules)
+	if len(findings) == 0 {
+		fmt.Fprintf(&b, "%s\n\n", mdEscape(text["None"]))
+	} else {
+		rows := make([][]string, 0, len(findings))
+		for _, f := range findings {
+			rows = append(rows, []string{text[f.Severity], f.ModuleName, f.Message, f.Recommendation})
+		}
+		mdTable(&b, []string{text["Severity"], text["Module"], text["Message"], text["Recommendation"]}, rows)
+	}
+
+	for _, module := range data.Modules {
+		fmt.Fprintf(&b, "## %s\n\n", mdEscape(module.Name))
+		fmt.Fprintf(&b, "**%s:** %d\n\n", mdEscape(langText("健康评分", "Health Score", "ヘルススコア", lang)), module.HealthScore)
+		if module.Error != "" {
+			fmt.Fprintf(&b, "> **%s:** %s\n\n", mdEscape(langText("错误", "Error", "エラー", lang)), mdEscape(module.Error))
+		}
+		if len(module.Cards) > 0 {
+			b.WriteString("<dl>\n")
+			for _, card := range module.Cards {
+				fmt.Fprintf(&b, "<dt>%s</dt><dd>%s</dd>\n", html.EscapeString(card.Title), html.EscapeString(card.Value))
+			}
+			b.WriteString("</dl>\n\n")
+		}
+		for _, table := range module.Tables {
+			if table == nil {
+				continue
+			}
+			fmt.Fprintf(&b, "### %s\n\n", mdEscape(table.Name))
+			mdTable(&b, table.Headers, table.Rows)
+			if table.Notes != "" {
+				fmt.Fprintf(&b, "_%s_\n\n", mdEscape(table.Notes))
+			}
+		}
+		for _, chart := range module.Charts {
+			if title, headers, rows, ok := chartSummary(chart, lang); ok {
+				fmt.Fprintf(&b, "### %s\n\n", mdEscape(title))
+				mdTable(&b, headers, rows)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mdEscaper escapes the characters that would otherwise start Markdown or HTML markup inside a line.
+var mdEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, `*`, `\*`, `_`, `\_`, "`", "\\`", `&`, `&amp;`, `<`, `&lt;`, `>`, `&gt;`, "\r\n", "<br>", "\n", "<br>")
+
+func mdEscape(s string) string {
+	return mdEscaper.Replace(s)
+}
+
+// mdTable writes a pipe table. Rows are padded or cut to the number of headers.
+func mdTable(b *strings.Builder, headers []string, rows [][]string) {
+	if len(headers) == 0 {
+		return
+	}
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for i := range headers {
+			value := ""
+			if i < len(cells) {
+				value = mdEscape(cells[i])
+			}
+			b.WriteString(" " + value + " |")
+		}
+		b.WriteString("\n")
+	}
+	writeRow(headers)
+	b.WriteString("|" + strings.Repeat(" --- |", len(headers)) + "\n")
+	for _, row := range rows {
+		writeRow(row)
+	}
+	b.WriteString("\n")
+}
+
+// exportText writes the report as fixed-width plain text, suitable for email bodies.
+func exportText(w io.Writer, data ReportData) error {
+	lang := data.Lang
+	text := findingsText(lang)
+	var b strings.Builder
+
+	textHeading(&b, data.Title, '=')
+	textPairs(&b, reportMetadataRows(data))
+
+	textHeading(&b, text["Title"], '-')
+	findings, _ := collectFindings(data.Modules)
+	if len(findings) == 0 {
+		b.WriteString(text["None"] + "\n\n")
+	} else {
+		for _, f := range findings {
+			fmt.Fprintf(&b, "[%s] %s: %s\n", text[f.Severity], f.ModuleName, f.Message)
+			if f.Recommendation != "" {
+				fmt.Fprintf(&b, "    %s: %s\n", text["Recommendation"], f.Recommendation)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	for _, module := range data.Modules {
+		textHeading(&b, fmt.Sprintf("%s (%s %d)", module.Name, langText("健康评分", "Health Score", "ヘルススコア", lang), module.HealthScore), '-')
+		if module.Error != "" {
+			fmt.Fprintf(&b, "%s: %s\n\n", langText("错误", "Error", "エラー", lang), module.Error)
+		}
+		if len(module.Cards) > 0 {
+			rows := make([][]string, 0, len(module.Cards))
+			for _, card := range module.Cards {
+				rows = append(rows, []string{card.Title, card.Value})
+			}
+			textPairs(&b, rows)
+		}
+		for _, table := range module.Tables {
+			if table == nil {
+				continue
+			}
+			b.WriteString(table.Name + "\n\n")
+			textTable(&b, table.Headers, table.Rows)
+			if table.Notes != "" {
+				b.WriteString(table.Notes + "\n\n")
+			}
+		}
+		for _, chart := range module.Charts {
+			if title, headers, rows, ok := chartSummary(chart, lang); ok {
+				b.WriteString(title + "\n\n")
+				textTable(&b, headers, rows)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// textHeading writes title underlined with the given character.
+func textHeading(b *strings.Builder, title string, underline rune) {
+	b.WriteString(title + "\n")
+	b.WriteString(strings.Repeat(string(underline), displayWidth(title)) + "\n\n")
+}
+
+// textPairs writes label/value pairs with the values aligned. Values are never cut.
+func textPairs(b *strings.Builder, rows [][]string) {
+	labelWidth := 0
+	for _, row := range rows {
+		labelWidth = max(labelWidth, displayWidth(row[0]))
+	}
+	for _, row := range rows {
+		fmt.Fprintf(b, "%s  %s\n", padRight(row[0], labelWidth), row[1])
+	}
+	b.WriteString("\n")
+}
+
+// textTable writes rows as aligned columns, with an underlined header row when headers is not nil.
+func textTable(b *strings.Builder, headers []string, rows [][]string) {
+	columns := len(headers)
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	if columns == 0 {
+		return
+	}
+	cell := func(row []string, i int) string {
+		if i >= len(row) {
+			return ""
+		}
+		return truncateDisplay(strings.Join(strings.Fields(row[i]), " "), maxTextCellWidth)
+	}
+	widths := make([]int, columns)
+	for _, row := range append([][]string{headers}, rows...) {
+		for i := range widths {
+			if width := displayWidth(cell(row, i)); width > widths[i] {
+				widths[i] = width
+			}
+		}
+	}
+	writeRow := func(row []string) {
+		var line strings.Builder
+		for i, width := range widths {
+			if i > 0 {
+				line.WriteString("  ")
+			}
+			line.WriteString(padRight(cell(row, i), width))
+		}
+		b.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+	}
+	if headers != nil {
+		writeRow(headers)
+		rule := make([]string, columns)
+		for i, width := range widths {
+			rule[i] = strings.Repeat("-", width)
+		}
+		writeRow(rule)
+	}
+	for _, row := range rows {
+		writeRow(row)
+	}
+	b.WriteString("\n")
+}
+
+// runeDisplayWidth returns 2 for East Asian wide characters, which take two columns in a fixed-width font.
+// Half-width katakana and Hangul (U+FF61 to U+FFDC) are narrow although they belong to those scripts.
+func runeDisplayWidth(r rune) int {
+	if r >= 0xff61 && r <= 0xffdc {
+		return 1
+	}
+	if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		(r >= 0x3000 && r <= 0x303f) || (r >= 0xff01 && r <= 0xff60) || (r >= 0xffe0 && r <= 0xffe6) {
+		return 2
+	}
+	return 1
+}
+
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeDisplayWidth(r)
+	}
+	return width
+}
+
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(0, width-displayWidth(s)))
+}
+
+// truncateDisplay cuts s to at most width columns, marking the cut with "...".
+func truncateDisplay(s string, width int) string {
+	if displayWidth(s) <= width {
+		return s
+	}
+	var out strings.Builder
+	used := 0
+	for _, r := range s {
+		rw := runeDisplayWidth(r)
+		if used+rw > width-3 {
+			break
+		}
+		out.WriteRune(r)
+		used += rw
+	}
+	return out.String() + "..."
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMdEscape(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"USERS", "USERS"},
+		{"a|b", `a\|b`},
+		{"`rm -rf`", "\\`rm -rf\\`"},
+		{"*bold* _em_", `\*bold\* \_em\_`},
+		{`C:\oracle\admin`, `C:\\oracle\\admin`},
+		{"<script>alert(1)</script>", "&lt;script&gt;alert(1)&lt;/script&gt;"},
+		{"a &lt; b & c", "a &amp;lt; b &amp; c"},
+		{"line1\nline2\r\nline3", "line1<br>line2<br>line3"},
+		{"表空间 USERS|已满", `表空间 USERS\|已满`},
+	}
+	for _, tt := range tests {
+		if got := mdEscape(tt.in); got != tt.want {
+			t.Errorf("mdEscape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMdTable(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		rows    [][]string
+		want    string
+	}{
+		{name: "no headers", rows: [][]string{{"a"}}, want: ""},
+		{
+			name:    "no rows",
+			headers: []string{"Name", "Value"},
+			want:    "| Name | Value |\n| --- | --- |\n\n",
+		},
+		{
+			name:    "short rows are padded and long rows cut",
+			headers: []string{"Name", "Value"},
+			rows:    [][]string{{"processes"}, {"sga_target", "4G", "extra"}, {}},
+			want:    "| Name | Value |\n| --- | --- |\n| processes |  |\n| sga\\_target | 4G |\n|  |  |\n\n",
+		},
+		{
+			name:    "headers and cells are escaped",
+			headers: []string{"Used|%"},
+			rows:    [][]string{{"a|b\nc"}},
+			want:    "| Used\\|% |\n| --- |\n| a\\|b<br>c |\n\n",
+		},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		mdTable(&b, tt.headers, tt.rows)
+		if got := b.String(); got != tt.want {
+			t.Errorf("%s: table =\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTextTable(t *testing.T) {
+	long := strings.Repeat("x", 50)
+	tests := []struct {
+		name    string
+		headers []string
+		rows    [][]string
+		want    []string
+	}{
+		{name: "nothing", want: nil},
+		{
+			name:    "aligned columns",
+			headers: []string{"Name", "Used %"},
+			rows:    [][]string{{"USERS", "85"}, {"SYSAUX", "7"}},
+			want:    []string{"Name    Used %", "------  ------", "USERS   85", "SYSAUX  7"},
+		},
+		{
+			name:    "double-width characters",
+			headers: []string{"表空间", "状态"},
+			rows:    [][]string{{"USERS", "ONLINE"}, {"数据", "OFFLINE"}},
+			want:    []string{"表空间  状态", "------  -------", "USERS   ONLINE", "数据    OFFLINE"},
+		},
+		{
+			name: "rows without headers may be longer or shorter",
+			rows: [][]string{{"a", "b", "c"}, {"dd"}},
+			want: []string{"a   b  c", "dd"},
+		},
+		{
+			name:    "whitespace is collapsed and long values cut",
+			headers: []string{"SQL"},
+			rows:    [][]string{{"SELECT *\n  FROM\tdual"}, {long}},
+			want:    []string{"SQL", strings.Repeat("-", 40), "SELECT * FROM dual", strings.Repeat("x", 37) + "..."},
+		},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		textTable(&b, tt.headers, tt.rows)
+		want := ""
+		if tt.want != nil {
+			want = strings.Join(tt.want, "\n") + "\n\n"
+		}
+		if got := b.String(); got != want {
+			t.Errorf("%s: table =\n%q\nwant\n%q", tt.name, got, want)
+		}
+	}
+}
+
+func TestTruncateDisplay(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"USERS", 10, "USERS"},
+		{"0123456789", 10, "0123456789"},
+		{"0123456789A", 10, "0123456..."},
+		{"表空间", 6, "表空间"},
+		{"表空间使用率过高", 10, "表空间..."},      // 3 wide characters fill 6 of the 7 columns left for text
+		{"表空间使用率过高", 11, "表空间使..."},     // 8 columns of text
+		{"USERS表空间已满", 10, "USERS表..."}, // A wide character that would cross the limit is left out
+		{"ｶﾀｶﾅ and ＡＢＣ", 8, "ｶﾀｶﾅ ..."}, // Half-width katakana is narrow, full-width Latin is wide
+	}
+	for _, tt := range tests {
+		got := truncateDisplay(tt.in, tt.width)
+		if got != tt.want {
+			t.Errorf("truncateDisplay(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+		}
+		if displayWidth(got) > tt.width {
+			t.Errorf("truncateDisplay(%q, %d) is %d columns wide", tt.in, tt.width, displayWidth(got))
+		}
+	}
+}
@@ -12,6 +12,7 @@ type ReportCard struct {
 
 // ReportTable defines the structure for a table in a report.
 type ReportTable struct {
+	Key     string     `json:"key,omitempty"`   // Stable, language-independent identifier (e.g. "tablespaces"), used when comparing reports
 	Name    string     `json:"name"`            // Table name
 	Headers []string   `json:"headers"`         // 表头
 	Rows    [][]string `json:"rows"`            // 表格数据行
@@ -132,4 +133,6 @@ type ReportModule struct {
 	Charts      []ReportChart  `json:"charts,omitempty"`      // 图表列表 (New field)
 	Error       string         `json:"error,omitempty"`       // Module-level error message
 	Description string         `json:"description,omitempty"` // Module description or summary information
+	Findings    []Finding      `json:"findings,omitempty"`    // Problems detected by the findings rules, most severe first
+	HealthScore int            `json:"healthScore"`           // 0-100, derived from the findings and the module error
 }
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/xlsx"
+)
+
+// exportXLSX writes the report as an Excel workbook: a summary sheet with the connection metadata
+// and the card values of every module, followed by one sheet per report table.
+func exportXLSX(w io.Writer, data ReportData) error {
+	lang := data.Lang
+	wb := xlsx.New()
+
+	summary := [][]string{
+		{langText("项目", "Item", "項目", lang), langText("值", "Value", "値", lang)},
+		{langText("报告标题", "Report Title", "レポートタイトル", lang), data.Title},
+		{langText("业务名称", "Business Name", "業務名", lang), data.BusinessName},
+		{langText("数据库", "Database", "データベース", lang), data.DBFullInfo},
+		{langText("连接", "Connection", "接続", lang), data.DBConnection},
+		{langText("巡检用户", "Inspected As", "検査ユーザー", lang), sessionUserText(data)},
+		{langText("巡检时间", "Inspection Time", "検査時間", lang), data.GeneratedAt},
+		{langText("阈值配置", "Threshold Profile", "しきい値プロファイル", lang), data.ThresholdProfile},
+		{langText("健康评分", "Health Score", "ヘルススコア", lang), strconv.Itoa(data.HealthScore)},
+		{},
+		{langText("模块", "Module", "モジュール", lang), langText("项目", "Item", "項目", lang), langText("值", "Value", "値", lang)},
+	}
+	for _, module := range data.Modules {
+		summary = append(summary, []string{module.Name, langText("健康评分", "Health Score", "ヘルススコア", lang), strconv.Itoa(module.HealthScore)})
+		if module.Error != "" {
+			summary = append(summary, []string{module.Name, langText("错误", "Error", "エラー", lang), module.Error})
+		}
+		for _, card := range module.Cards {
+			summary = append(summary, []string{module.Name, card.Title, card.Value})
+		}
+	}
+	wb.AddSheet(langText("概要", "Summary", "概要", lang), true, summary)
+
+	for _, module := range data.Modules {
+		for _, table := range module.Tables {
+			if table == nil {
+				continue
+			}
+			rows := make([][]string, 0, len(table.Rows)+1)
+			rows = append(rows, table.Headers)
+			rows = append(rows, table.Rows...)
+			wb.AddSheet(fmt.Sprintf("%s - %s", module.Name, table.Name), true, rows)
+		}
+	}
+
+	_, err := wb.WriteTo(w)
+	return err
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+)
+
+// FindingThresholds are the limits the findings rules compare the collected data against.
+type FindingThresholds struct {
+	TablespaceWarnPct      float64 `json:"tablespaceWarnPct"`      // Tablespace usage (% of maximum size) that raises a warning
+	TablespaceCritPct      float64 `json:"tablespaceCritPct"`      // Tablespace usage that raises a critical finding
+	ASMWarnPct             float64 `json:"asmWarnPct"`             // ASM disk group usage that raises a warning
+	ASMCritPct             float64 `json:"asmCritPct"`             // ASM disk group usage that raises a critical finding
+	MaxBackupAgeDays       float64 `json:"maxBackupAgeDays"`       // Days since the last successful RMAN backup before it is critical (0 = no limit)
+	MaxInvalidObjects      int     `json:"maxInvalidObjects"`      // Invalid objects tolerated before a warning (fewer are reported as info)
+	PasswordExpiryWarnDays int     `json:"passwordExpiryWarnDays"` // Warn about open accounts whose password expires within this many days (0 = off)
+	RequireArchivelog      bool    `json:"requireArchivelog"`      // NOARCHIVELOG is critical when true and ignored otherwise
+}
+
+// defaultFindingThresholds are used when no thresholds file is configured, and are the base
+// every profile in a thresholds file starts from.
+var defaultFindingThresholds = FindingThresholds{
+	TablespaceWarnPct:      85,
+	TablespaceCritPct:      95,
+	ASMWarnPct:             90,
+	ASMCritPct:             95,
+	MaxBackupAgeDays:       2,
+	MaxInvalidObjects:      10,
+	PasswordExpiryWarnDays: 14,
+	RequireArchivelog:      true,
+}
+
+// defaultProfileName is the profile used when no thresholds file is loaded.
+const defaultProfileName = "default"
+
+// ThresholdProfiles is the content of a thresholds file.
+//
+// Example:
+//
+//	{
+//	  "default": "prod",
+//	  "profiles": {
+//	    "prod": {"tablespaceWarnPct": 80, "tablespaceCritPct": 90, "maxBackupAgeDays": 1},
+//	    "test": {"tablespaceWarnPct": 90, "tablespaceCritPct": 98, "maxBackupAgeDays": 0, "requireArchivelog": false}
+//	  }
+//	}
+//
+// Fields left out of a profile keep their built-in default value.
+type ThresholdProfiles struct {
+	Default  string                       `json:"default"`
+	Profiles map[string]FindingThresholds `json:"profiles"`
+}
+
+var (
+	thresholdProfiles = ThresholdProfiles{
+		Default:  defaultProfileName,
+		Profiles: map[string]FindingThresholds{defaultProfileName: defaultFindingThresholds},
+	}
+	thresholdProfilesMutex sync.RWMutex
+)
+
+// LoadThresholdProfiles reads a thresholds file and makes its profiles available to inspections.
+func LoadThresholdProfiles(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read thresholds file %s: %w", path, err)
+	}
+	var file struct {
+		Default  string                     `json:"default"`
+		Profiles map[string]json.RawMessage `json:"profiles"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse thresholds file %s: %w", path, err)
+	}
+	if len(file.Profiles) == 0 {
+		return fmt.Errorf("thresholds file %s does not define any profiles", path)
+	}
+
+	loaded := ThresholdProfiles{Default: file.Default, Profiles: make(map[string]FindingThresholds, len(file.Profiles))}
+	for name, raw := range file.Profiles {
+		t := defaultFindingThresholds
+		if err := json.Unmarshal(raw, &t); err != nil {
+			return fmt.Errorf("invalid threshold profile %q: %w", name, err)
+		}
+		if t.TablespaceWarnPct > t.TablespaceCritPct || t.ASMWarnPct > t.ASMCritPct {
+			return fmt.Errorf("threshold profile %q: warning percentages must not exceed critical ones", name)
+		}
+		loaded.Profiles[name] = t
+	}
+	if loaded.Default == "" {
+		if len(loaded.Profiles) != 1 {
+			return fmt.Errorf("thresholds file %s must name a default profile", path)
+		}
+		for name := range loaded.Profiles {
+			loaded.Default = name
+		}
+	}
+	if _, ok := loaded.Profiles[loaded.Default]; !ok {
+		return fmt.Errorf("default threshold profile %q is not defined in %s", loaded.Default, path)
+	}
+
+	thresholdProfilesMutex.Lock()
+	thresholdProfiles = loaded
+	thresholdProfilesMutex.Unlock()
+	return nil
+}
+
+// ThresholdProfileNames returns the available profile names, sorted, and the default one.
+func ThresholdProfileNames() ([]string, string) {
+	thresholdProfilesMutex.RLock()
+	defer thresholdProfilesMutex.RUnlock()
+	names := make([]string, 0, len(thresholdProfiles.Profiles))
+	for name := range thresholdProfiles.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, thresholdProfiles.Default
+}
+
+// resolveThresholdProfile returns the profile to judge a report against; an empty name selects the default.
+func resolveThresholdProfile(name string) (string, FindingThresholds, error) {
+	thresholdProfilesMutex.RLock()
+	defer thresholdProfilesMutex.RUnlock()
+	if name == "" {
+		name = thresholdProfiles.Default
+	}
+	t, ok := thresholdProfiles.Profiles[name]
+	if !ok {
+		return "", FindingThresholds{}, fmt.Errorf("unknown threshold profile %q", name)
+	}
+	return name, t, nil
+}
+
+// ThresholdProfilesHandler handles GET /api/thresholds and lists the profiles with their limits.
+func ThresholdProfilesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		thresholdProfilesMutex.RLock()
+		payload := map[string]interface{}{
+			"success":  true,
+			"default":  thresholdProfiles.Default,
+			"profiles": thresholdProfiles.Profiles,
+		}
+		thresholdProfilesMutex.RUnlock()
+		sendJSONResponse(w, payload, http.StatusOK)
+	}
+}
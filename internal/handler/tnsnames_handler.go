@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/db"
+	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+)
+
+// TNSAliasesHandler lists the aliases of the tnsnames.ora in use, for the connection form.
+// A missing or unconfigured file is not an error; the list is simply empty.
+func TNSAliasesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		aliases := []string{}
+		path := db.TNSNamesPath()
+		if path != "" {
+			entries, err := db.ReadTNSNames(path)
+			if err != nil {
+				logger.Warnf("Cannot list TNS aliases: %v", err)
+			}
+			for name := range entries {
+				aliases = append(aliases, name)
+			}
+			sort.Strings(aliases)
+		}
+		sendJSONResponse(w, map[string]interface{}{
+			"success": true,
+			"file":    path,
+			"aliases": aliases,
+		}, http.StatusOK)
+	}
+}
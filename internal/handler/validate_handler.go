@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strconv"
+	"strings"
 
 	"github.com/goodwaysIT/inspect4oracle/internal/db"
 	"github.com/goodwaysIT/inspect4oracle/internal/logger"
@@ -12,11 +12,21 @@ import (
 
 // ValidateRequest defines the validation request structure
 type ValidateRequest struct {
-	Host     string `json:"host"`
-	Port     string `json:"port"`
-	Service  string `json:"service"`
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Host           string   `json:"host"`
+	Port           string   `json:"port"`
+	Service        string   `json:"service"`
+	ConnectionType string   `json:"connectionType,omitempty"` // "SERVICE_NAME" (default) or "SID"
+	ConnectString  string   `json:"connectString,omitempty"`  // EZConnect, (DESCRIPTION=...) or TNS alias; replaces host, port and service
+	Protocol       string   `json:"protocol,omitempty"`       // "TCP" (default) or "TCPS"
+	WalletDir      string   `json:"walletDir,omitempty"`      // TCPS wallet directory on the server
+	ServerCertDN   string   `json:"serverCertDn,omitempty"`   // Expected server certificate DN for TCPS
+	Privilege      string   `json:"privilege,omitempty"`      // "SYSDBA", "SYSDG", "SYSBACKUP" or empty for a normal session
+	ProxyClient    string   `json:"proxyClient,omitempty"`    // User the proxy session runs as; also accepted as username "proxy[client]"
+	ProfileID      string   `json:"profileId,omitempty"`      // Saved connection profile; replaces the connection fields and password
+	Username       string   `json:"username"`
+	Password       string   `json:"password"`
+	Items          []string `json:"items,omitempty"` // Inspection items whose privileges are checked; all modules when empty
+	Lang           string   `json:"lang,omitempty"`
 }
 
 // ValidateResponse 定义验证响应结构体
@@ -24,6 +34,7 @@ type ValidateResponse struct {
 	Success        bool                      `json:"success"`
 	Message        string                    `json:"message"`
 	PrivilegeCheck []db.PrivilegeCheckResult `json:"privilege_check,omitempty"`
+	Modules        []ModuleReadiness         `json:"modules,omitempty"` // Readiness of each selected module, dbinfo first
 }
 
 // ValidateConnection 验证数据库连接
@@ -42,34 +53,44 @@ func ValidateConnection(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
-	// 验证必填字段
-	if reqData.Host == "" || reqData.Username == "" || reqData.Password == "" || reqData.Service == "" {
-		sendJSONError(w, "Missing required fields", http.StatusBadRequest)
-		return
-	}
-
 	// Set default port if not provided
-	port := reqData.Port
-	if port == "" {
-		port = "1521"
+	if reqData.Port == "" {
+		reqData.Port = "1521"
 	}
 
-	// 验证端口号
-	portInt, err := strconv.Atoi(port)
-	if err != nil || portInt <= 0 || portInt > 65535 {
-		sendJSONError(w, "Invalid port number", http.StatusBadRequest)
+	// 与 /api/inspect 使用相同的连接参数校验
+	req := &DBConnectionRequest{
+		Host:           reqData.Host,
+		Port:           reqData.Port,
+		Service:        reqData.Service,
+		ConnectionType: reqData.ConnectionType,
+		ConnectString:  reqData.ConnectString,
+		Protocol:       reqData.Protocol,
+		WalletDir:      reqData.WalletDir,
+		ServerCertDN:   reqData.ServerCertDN,
+		Privilege:      reqData.Privilege,
+		ProxyClient:    reqData.ProxyClient,
+		ProfileID:      reqData.ProfileID,
+		Username:       reqData.Username,
+		Password:       reqData.Password,
+		Lang:           reqData.Lang,
+	}
+	if err := ValidateConnectionParameters(req); err != nil {
+		sendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	modules, err := selectedModules(reqData.Items)
+	if err != nil {
+		sendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Password == "" {
+		sendJSONError(w, "Missing required fields", http.StatusBadRequest)
 		return
 	}
 
 	// 尝试连接数据库
-	dbConn, err := db.Connect(db.ConnectionDetails{
-		User:           reqData.Username,
-		Password:       reqData.Password,
-		Host:           reqData.Host,
-		Port:           portInt,
-		DBName:         reqData.Service,
-		ConnectionType: "SERVICE_NAME",
-	})
+	dbConn, err := db.Connect(db.WithAction(r.Context(), "validate"), req.connectionDetails())
 
 	if err != nil {
 		sendJSONError(w, fmt.Sprintf("Failed to connect to database: %v", err), http.StatusOK)
@@ -77,24 +98,32 @@ func ValidateConnection(w http.ResponseWriter, r *http.Request) {
 	}
 	defer dbConn.Close()
 
-	// 验证数据库连接和权限
-	allAccessGranted, privilegeResults, err := db.CheckDatabaseConnection(dbConn)
+	// 验证数据库连接和所选模块需要的视图权限
+	required, optional := moduleViews(modules)
+	_, privilegeResults, err := db.CheckDatabaseConnection(db.WithAction(r.Context(), "validate"), dbConn, append(required, optional...))
 	if err != nil {
-		// If the connection is successful but permission check fails, still return a partially successful result.
 		sendJSONResponse(w, ValidateResponse{
-			Success:        !allAccessGranted,
+			Success:        false,
 			Message:        "Failed to check database permissions",
 			PrivilegeCheck: privilegeResults,
 		}, http.StatusOK)
 		return
 	}
 
-	// Check for sufficient privileges.
-	if !allAccessGranted {
+	// Optional views only leave out parts of a module; the connection is usable when every module is ready.
+	readiness := moduleReadiness(modules, privilegeResults, reqData.Lang)
+	var notReady []string
+	for _, module := range readiness {
+		if !module.Ready {
+			notReady = append(notReady, module.ID)
+		}
+	}
+	if len(notReady) > 0 {
 		sendJSONResponse(w, ValidateResponse{
 			Success:        false,
-			Message:        "Failed to check database permissions",
+			Message:        fmt.Sprintf("Missing privileges for modules: %s", strings.Join(notReady, ", ")),
 			PrivilegeCheck: privilegeResults,
+			Modules:        readiness,
 		}, http.StatusOK)
 		return
 	}
@@ -104,6 +133,7 @@ func ValidateConnection(w http.ResponseWriter, r *http.Request) {
 		Success:        true,
 		Message:        "Database connection and permission validation successful",
 		PrivilegeCheck: privilegeResults,
+		Modules:        readiness,
 	}, http.StatusOK)
 }
 
@@ -0,0 +1,246 @@
+// Package notify delivers finished inspection reports to people and systems.
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/mail"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/fleet"
+	"github.com/goodwaysIT/inspect4oracle/internal/handler"
+)
+
+// Config is the notifications file given with -notify-config.
+//
+// Example:
+//
+//	{
+//	  "reportBaseUrl": "http://inspect.example.com:8080",
+//	  "smtp": {
+//	    "host": "smtp.example.com", "port": 587, "startTLS": true,
+//	    "username": "inspect4oracle", "credentials": "env:SMTP_PASSWORD",
+//	    "from": "Inspect4Oracle <inspect4oracle@example.com>",
+//	    "attach": ["html", "pdf"],
+//	    "recipients": [
+//	      {"business": "CRM", "to": ["crm-dba@example.com"]},
+//	      {"dbConnection": "10.0.0.7:1521/ERPPDB", "to": ["erp-dba@example.com"], "cc": ["ops@example.com"]}
+//	    ]
+//	  },
+//	  "webhooks": [
+//	    {"name": "chat", "url": "https://chat.example.com/hooks/dba", "secret": "env:WEBHOOK_SECRET",
+//	     "events": ["inspection.failed", "inspection.module_errors"]}
+//	  ]
+//	}
+type Config struct {
+	ReportBaseURL string          `json:"reportBaseUrl,omitempty"` // Server address used to link to stored reports
+	SMTP          *SMTPConfig     `json:"smtp,omitempty"`
+	Webhooks      []WebhookConfig `json:"webhooks,omitempty"`
+}
+
+// SMTPConfig describes the mail server and who receives which reports.
+type SMTPConfig struct {
+	Host               string          `json:"host"`
+	Port               int             `json:"port"`                         // Defaults to 587 with STARTTLS, 465 with TLS, otherwise 25
+	StartTLS           bool            `json:"startTLS"`                     // Upgrade the connection with STARTTLS; required by the server when set
+	TLS                bool            `json:"tls"`                          // Connect with implicit TLS (SMTPS)
+	InsecureSkipVerify bool            `json:"insecureSkipVerify,omitempty"` // Accept any server certificate, for test servers only
+	Username           string          `json:"username,omitempty"`           // Enables AUTH PLAIN, which needs TLS unless the server is localhost
+	Credentials        string          `json:"credentials,omitempty"`        // Password reference: "env:VAR" or "file:/path/to/secret"
+	From               string          `json:"from"`
+	Attach             []string        `json:"attach,omitempty"`         // Report formats to attach, e.g. html, pdf; defaults to html
+	TimeoutSeconds     int             `json:"timeoutSeconds,omitempty"` // Connection and delivery timeout, default 60
+	Recipients         []RecipientRule `json:"recipients"`
+
+	password string // Resolved from Credentials by LoadConfig
+}
+
+// WebhookConfig describes an endpoint that receives inspection outcomes as signed JSON posts.
+type WebhookConfig struct {
+	Name               string            `json:"name,omitempty"`   // Used in log messages; defaults to the URL host
+	URL                string            `json:"url"`              // http or https endpoint
+	Secret             string            `json:"secret,omitempty"` // HMAC key reference: "env:VAR" or "file:/path/to/secret"
+	Events             []string          `json:"events,omitempty"` // Events to post; defaults to all
+	Headers            map[string]string `json:"headers,omitempty"`
+	MaxAttempts        int               `json:"maxAttempts,omitempty"`        // Including the first attempt, default 5
+	TimeoutSeconds     int               `json:"timeoutSeconds,omitempty"`     // Per attempt, default 10
+	InsecureSkipVerify bool              `json:"insecureSkipVerify,omitempty"` // Accept any server certificate, for test endpoints only
+
+	secret []byte // Resolved from Secret by LoadConfig
+}
+
+// subscribes reports whether the webhook wants event.
+func (w WebhookConfig) subscribes(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// RecipientRule selects the reports sent to its addresses. Empty match fields match every report;
+// a report goes to the union of the addresses of all matching rules.
+type RecipientRule struct {
+	DBConnection string   `json:"dbConnection,omitempty"` // host:port/service (host:port:SID for SID connections), case-insensitive
+	DBName       string   `json:"dbName,omitempty"`       // Database name, case-insensitive
+	Business     string   `json:"business,omitempty"`     // Business system name, case-insensitive
+	To           []string `json:"to"`
+	Cc           []string `json:"cc,omitempty"`
+}
+
+// Matches reports whether the rule applies to data.
+func (r RecipientRule) Matches(data handler.ReportData) bool {
+	return matchFold(r.DBConnection, data.DBConnection) &&
+		matchFold(r.DBName, data.DBName) &&
+		matchFold(r.Business, data.BusinessName)
+}
+
+func matchFold(pattern, value string) bool {
+	return pattern == "" || strings.EqualFold(pattern, value)
+}
+
+// LoadConfig reads and validates a notifications file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notifications file: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse notifications file %s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("notifications file %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// validate checks the configuration and fills in defaults.
+func (c *Config) validate() error {
+	c.ReportBaseURL = strings.TrimRight(c.ReportBaseURL, "/")
+	for i := range c.Webhooks {
+		if err := c.Webhooks[i].validate(); err != nil {
+			return fmt.Errorf("webhooks[%d]: %w", i, err)
+		}
+	}
+	if c.SMTP == nil {
+		return nil
+	}
+	s := c.SMTP
+	if s.Host == "" {
+		return fmt.Errorf("smtp: host is required")
+	}
+	if s.StartTLS && s.TLS {
+		return fmt.Errorf("smtp: startTLS and tls are mutually exclusive")
+	}
+	if s.Port == 0 {
+		switch {
+		case s.TLS:
+			s.Port = 465
+		case s.StartTLS:
+			s.Port = 587
+		default:
+			s.Port = 25
+		}
+	}
+	if s.Username != "" {
+		if s.Credentials == "" {
+			return fmt.Errorf("smtp: credentials are required with username")
+		}
+		password, err := fleet.ResolveCredentials(s.Credentials)
+		if err != nil {
+			return fmt.Errorf("smtp: %w", err)
+		}
+		s.password = password
+	}
+	if _, err := mail.ParseAddress(s.From); err != nil {
+		return fmt.Errorf("smtp: invalid from address %q: %w", s.From, err)
+	}
+	if len(s.Attach) == 0 {
+		s.Attach = []string{"html"}
+	}
+	for _, format := range s.Attach {
+		if !isReportFormat(format) {
+			return fmt.Errorf("smtp: unsupported attachment format %q (use html or %s)", format, strings.Join(handler.ExportFormats(), ", "))
+		}
+	}
+	if s.TimeoutSeconds <= 0 {
+		s.TimeoutSeconds = 60
+	}
+	if len(s.Recipients) == 0 {
+		return fmt.Errorf("smtp: at least one recipients rule is required")
+	}
+	for i, rule := range s.Recipients {
+		if len(rule.To) == 0 && len(rule.Cc) == 0 {
+			return fmt.Errorf("smtp: recipients rule %d has no addresses", i+1)
+		}
+		for _, addr := range append(append([]string(nil), rule.To...), rule.Cc...) {
+			if _, err := mail.ParseAddress(addr); err != nil {
+				return fmt.Errorf("smtp: recipients rule %d: invalid address %q: %w", i+1, addr, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validate checks a webhook and fills in defaults.
+func (w *WebhookConfig) validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url %q", w.URL)
+	}
+	if w.Name == "" {
+		w.Name = u.Host
+	}
+	for _, event := range w.Events {
+		if event != EventCompleted && event != EventModuleErrors && event != EventFailed {
+			return fmt.Errorf("unknown event %q (use %s, %s or %s)", event, EventCompleted, EventModuleErrors, EventFailed)
+		}
+	}
+	if w.Secret != "" {
+		secret, err := fleet.ResolveCredentials(w.Secret)
+		if err != nil {
+			return err
+		}
+		w.secret = []byte(secret)
+	}
+	if w.MaxAttempts <= 0 {
+		w.MaxAttempts = 5
+	}
+	if w.TimeoutSeconds <= 0 {
+		w.TimeoutSeconds = 10
+	}
+	return nil
+}
+
+func isReportFormat(format string) bool {
+	if format == "html" {
+		return true
+	}
+	for _, supported := range handler.ExportFormats() {
+		if format == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// Notifiers returns the notifiers enabled by the configuration. content holds the embedded
+// static files needed to render HTML attachments.
+func (c *Config) Notifiers(content fs.FS) []handler.ReportNotifier {
+	var notifiers []handler.ReportNotifier
+	if c.SMTP != nil {
+		notifiers = append(notifiers, &SMTPNotifier{Config: *c.SMTP, ReportBaseURL: c.ReportBaseURL, Content: content})
+	}
+	for _, w := range c.Webhooks {
+		notifiers = append(notifiers, NewWebhookNotifier(w, c.ReportBaseURL))
+	}
+	return notifiers
+}
@@ -0,0 +1,268 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/handler"
+	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+)
+
+// attachmentTypes are the media types of the attachment formats; text types are sent as UTF-8.
+var attachmentTypes = map[string]string{
+	"html": "text/html",
+	"json": "application/json",
+	"md":   "text/markdown",
+	"pdf":  "application/pdf",
+	"txt":  "text/plain",
+	"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// SMTPNotifier emails a summary of every finished report to the recipients configured for its database.
+type SMTPNotifier struct {
+	Config        SMTPConfig
+	ReportBaseURL string // Optional; when set, the message links to the stored report
+	Content       fs.FS  // Embedded static files, needed for HTML attachments
+}
+
+// Name implements handler.ReportNotifier.
+func (n *SMTPNotifier) Name() string { return "smtp" }
+
+// Notify implements handler.ReportNotifier. Reports without matching recipients are skipped.
+func (n *SMTPNotifier) Notify(reportID string, data handler.ReportData) error {
+	to, cc := n.recipients(data)
+	if len(to) == 0 && len(cc) == 0 {
+		logger.Debugf("No email recipients configured for report %s (%s)", reportID, data.DBConnection)
+		return nil
+	}
+	msg, err := n.message(reportID, data, to, cc)
+	if err != nil {
+		return err
+	}
+	if err := n.send(append(append([]string(nil), to...), cc...), msg); err != nil {
+		return err
+	}
+	logger.Infof("Report %s emailed to %s", reportID, strings.Join(append(append([]string(nil), to...), cc...), ", "))
+	return nil
+}
+
+// recipients collects the addresses of every rule matching data, without duplicates. Addresses are
+// compared without their display names, e.g. "CRM DBA <dba@example.com>" equals "dba@example.com".
+func (n *SMTPNotifier) recipients(data handler.ReportData) (to, cc []string) {
+	seen := make(map[string]bool)
+	add := func(list []string, addrs []string) []string {
+		for _, addr := range addrs {
+			key := strings.ToLower(addr)
+			if a, err := mail.ParseAddress(addr); err == nil {
+				key = strings.ToLower(a.Address)
+			}
+			if !seen[key] {
+				seen[key] = true
+				list = append(list, addr)
+			}
+		}
+		return list
+	}
+	for _, rule := range n.Config.Recipients {
+		if rule.Matches(data) {
+			to = add(to, rule.To)
+		}
+	}
+	for _, rule := range n.Config.Recipients {
+		if rule.Matches(data) {
+			cc = add(cc, rule.Cc)
+		}
+	}
+	return to, cc
+}
+
+// reportURL returns the link to the stored report, or "" when no base URL is configured.
+func (n *SMTPNotifier) reportURL(reportID string) string {
+	if n.ReportBaseURL == "" || reportID == "" {
+		return ""
+	}
+	return n.ReportBaseURL + "/report.html?id=" + url.QueryEscape(reportID)
+}
+
+// message builds the MIME message: a plain-text summary followed by the report attachments.
+func (n *SMTPNotifier) message(reportID string, data handler.ReportData, to, cc []string) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	textPart, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	qp := quotedprintable.NewWriter(textPart)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(handler.NotificationSummary(data, n.reportURL(reportID)), "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+
+	for _, format := range n.Config.Attach {
+		var file bytes.Buffer
+		if err := handler.WriteReport(&file, n.Content, format, data); err != nil {
+			return nil, fmt.Errorf("failed to render %s attachment: %w", format, err)
+		}
+		name := handler.ExportFileName(data, format)
+		mediaType, params := attachmentTypes[format], map[string]string{"name": name}
+		if strings.HasPrefix(mediaType, "text/") {
+			params["charset"] = "utf-8"
+		}
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(mediaType, params)},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": name})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64Lines(part, file.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	from, _ := mail.ParseAddress(n.Config.From) // Validated by LoadConfig
+	var msg bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&msg, "%s: %s\r\n", name, value)
+	}
+	header("From", from.String())
+	header("To", formatAddresses(to))
+	if len(cc) > 0 {
+		header("Cc", formatAddresses(cc))
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", handler.NotificationSubject(data)))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	messageID := reportID
+	if messageID == "" {
+		messageID = "report"
+	}
+	header("Message-ID", fmt.Sprintf("<%s.%d@inspect4oracle>", messageID, time.Now().UnixNano()))
+	header("MIME-Version", "1.0")
+	header("Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": mw.Boundary()}))
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// formatAddresses renders addresses for a To or Cc header.
+func formatAddresses(addrs []string) string {
+	formatted := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		if a, err := mail.ParseAddress(addr); err == nil {
+			formatted = append(formatted, a.String())
+		}
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// writeBase64Lines writes data base64-encoded in lines of 76 characters, as required by RFC 2045.
+func writeBase64Lines(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 0 {
+		line := encoded
+		if len(line) > 76 {
+			line = line[:76]
+		}
+		if _, err := w.Write([]byte(line + "\r\n")); err != nil {
+			return err
+		}
+		encoded = encoded[len(line):]
+	}
+	return nil
+}
+
+// send delivers msg to the recipients through the configured server.
+func (n *SMTPNotifier) send(recipients []string, msg []byte) error {
+	cfg := n.Config
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	tlsConfig := &tls.Config{ServerName: cfg.Host, InsecureSkipVerify: cfg.InsecureSkipVerify}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	var err error
+	if cfg.TLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server %s: %w", addr, err)
+	}
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("SMTP handshake with %s failed: %w", addr, err)
+	}
+	defer client.Close()
+
+	if cfg.StartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("SMTP server %s does not support STARTTLS", addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS with %s failed: %w", addr, err)
+		}
+	}
+	if cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.password, cfg.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication as %s failed: %w", cfg.Username, err)
+		}
+	}
+
+	from, _ := mail.ParseAddress(cfg.From)
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("SMTP server rejected sender %s: %w", from.Address, err)
+	}
+	for _, rcpt := range recipients {
+		a, err := mail.ParseAddress(rcpt)
+		if err != nil {
+			return err
+		}
+		if err := client.Rcpt(a.Address); err != nil {
+			return fmt.Errorf("SMTP server rejected recipient %s: %w", a.Address, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP server did not accept the message: %w", err)
+	}
+	return client.Quit()
+}
@@ -0,0 +1,185 @@
+package notify
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/handler"
+	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init(false)
+	os.Exit(m.Run())
+}
+
+// smtpSession is what the stub server received in one session.
+type smtpSession struct {
+	from string
+	rcpt []string
+	data string
+}
+
+// serveSMTP accepts one connection on ln and answers it like a minimal SMTP server.
+func serveSMTP(t *testing.T, ln net.Listener, done chan<- smtpSession) {
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Errorf("accept: %v", err)
+		close(done)
+		return
+	}
+	defer conn.Close()
+
+	var s smtpSession
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+	reply("220 localhost ESMTP stub")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Errorf("read command: %v", err)
+			break
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch {
+		case verb == "EHLO" || verb == "HELO":
+			reply("250 localhost")
+		case strings.HasPrefix(strings.ToUpper(line), "MAIL FROM:"):
+			s.from = line[len("MAIL FROM:"):]
+			reply("250 OK")
+		case strings.HasPrefix(strings.ToUpper(line), "RCPT TO:"):
+			s.rcpt = append(s.rcpt, line[len("RCPT TO:"):])
+			reply("250 OK")
+		case verb == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					t.Errorf("read data: %v", err)
+					break
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			s.data = data.String()
+			reply("250 OK")
+		case verb == "QUIT":
+			reply("221 Bye")
+			done <- s
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+	done <- s
+}
+
+func TestSMTPNotifierNotify(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	done := make(chan smtpSession, 1)
+	go serveSMTP(t, ln, done)
+
+	cfg := &Config{
+		ReportBaseURL: "http://inspect.example.com:8080/",
+		SMTP: &SMTPConfig{
+			Host:   "127.0.0.1",
+			Port:   ln.Addr().(*net.TCPAddr).Port,
+			From:   "Inspect4Oracle <inspect4oracle@example.com>",
+			Attach: []string{"txt"},
+			Recipients: []RecipientRule{
+				{Business: "crm", To: []string{"CRM DBA <crm-dba@example.com>"}},
+				{DBName: "ORCL", To: []string{"crm-dba@example.com"}, Cc: []string{"ops@example.com"}},
+				{Business: "ERP", To: []string{"erp-dba@example.com"}},
+			},
+		},
+	}
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	data := handler.ReportData{
+		Lang:         "en",
+		Title:        "Oracle Inspection Report",
+		BusinessName: "CRM",
+		DBName:       "ORCL",
+		DBConnection: "10.0.0.7:1521/ORCLPDB",
+		HealthScore:  72,
+		Modules: []handler.ReportModule{
+			{ID: "dbinfo", Name: "Database Info", HealthScore: 100, Cards: []handler.ReportCard{{Title: "Version", Value: "19.3.0.0.0"}}},
+			{ID: "storage", Name: "Storage", Error: "ORA-00942: table or view does not exist"},
+		},
+	}
+	n := &SMTPNotifier{Config: *cfg.SMTP, ReportBaseURL: cfg.ReportBaseURL}
+	if err := n.Notify("r1", data); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	s := <-done
+
+	if s.from != "<inspect4oracle@example.com>" {
+		t.Errorf("MAIL FROM = %q", s.from)
+	}
+	if got, want := strings.Join(s.rcpt, " "), "<crm-dba@example.com> <ops@example.com>"; got != want {
+		t.Errorf("RCPT TO = %q, want %q", got, want)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(s.data))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[Inspect4Oracle] CRM (ORCL) - Health Score 72, Critical 0, Warning 0"; subject != want {
+		t.Errorf("Subject = %q, want %q", subject, want)
+	}
+	if got := msg.Header.Get("Cc"); got != "<ops@example.com>" {
+		t.Errorf("Cc = %q", got)
+	}
+
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	part, err := mr.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(part) // NextPart decodes quoted-printable
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"http://inspect.example.com:8080/report.html?id=r1",
+		"Storage  ORA-00942: table or view does not exist",
+		"Database Info (Health Score 100)",
+		"Version  19.3.0.0.0",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("body does not contain %q:\n%s", want, body)
+		}
+	}
+
+	part, err = mr.NextPart()
+	if err != nil {
+		t.Fatalf("attachment: %v", err)
+	}
+	if got, want := part.FileName(), handler.ExportFileName(data, "txt"); got != want {
+		t.Errorf("attachment name = %q, want %q", got, want)
+	}
+}
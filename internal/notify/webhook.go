@@ -0,0 +1,220 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/handler"
+	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+)
+
+// Webhook events. Every inspection fires exactly one of them.
+const (
+	EventCompleted    = "inspection.completed"     // The report was produced and every module succeeded
+	EventModuleErrors = "inspection.module_errors" // The report was produced, but some modules failed
+	EventFailed       = "inspection.failed"        // No report, e.g. the database could not be reached
+)
+
+// Webhook request headers.
+const (
+	headerEvent     = "X-Inspect4Oracle-Event"
+	headerDelivery  = "X-Inspect4Oracle-Delivery"
+	headerTimestamp = "X-Inspect4Oracle-Timestamp"
+	headerSignature = "X-Inspect4Oracle-Signature"
+)
+
+const (
+	webhookBackoff    = 2 * time.Second // Wait before the first retry; doubled after every attempt
+	webhookMaxBackoff = time.Minute
+)
+
+// webhookSleep waits between delivery attempts; tests replace it to record the backoff.
+var webhookSleep = time.Sleep
+
+// WebhookPayload is the JSON body posted to webhooks.
+type WebhookPayload struct {
+	Event        string          `json:"event"`
+	DeliveryID   string          `json:"deliveryId"` // Unchanged across retries, for deduplication
+	SentAt       string          `json:"sentAt"`     // RFC 3339
+	ReportID     string          `json:"reportId,omitempty"`
+	ReportURL    string          `json:"reportUrl,omitempty"` // Set when reportBaseUrl is configured
+	DBConnection string          `json:"dbConnection"`
+	DBName       string          `json:"dbName,omitempty"`
+	BusinessName string          `json:"businessName,omitempty"`
+	GeneratedAt  string          `json:"generatedAt,omitempty"`
+	HealthScore  *int            `json:"healthScore,omitempty"`
+	Findings     map[string]int  `json:"findings,omitempty"` // Number of findings by severity
+	Modules      []WebhookModule `json:"modules,omitempty"`
+	Error        string          `json:"error,omitempty"` // Why the inspection failed
+}
+
+// WebhookModule is the outcome of one inspection module.
+type WebhookModule struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Status      string `json:"status"` // "ok" or "error"
+	Error       string `json:"error,omitempty"`
+	HealthScore int    `json:"healthScore"`
+}
+
+// WebhookNotifier posts inspection outcomes to an HTTP endpoint, e.g. a chat or ticketing integration.
+type WebhookNotifier struct {
+	Config        WebhookConfig
+	ReportBaseURL string // Optional; when set, the payload links to the stored report
+	client        *http.Client
+}
+
+// NewWebhookNotifier returns a notifier for a validated webhook configuration.
+func NewWebhookNotifier(cfg WebhookConfig, reportBaseURL string) *WebhookNotifier {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.InsecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &WebhookNotifier{
+		Config:        cfg,
+		ReportBaseURL: reportBaseURL,
+		client:        &http.Client{Transport: transport, Timeout: time.Duration(cfg.TimeoutSeconds) * time.Second},
+	}
+}
+
+// Name implements handler.ReportNotifier.
+func (n *WebhookNotifier) Name() string { return "webhook " + n.Config.Name }
+
+// Notify implements handler.ReportNotifier.
+func (n *WebhookNotifier) Notify(reportID string, data handler.ReportData) error {
+	payload := WebhookPayload{
+		Event:        EventCompleted,
+		ReportID:     reportID,
+		DBConnection: data.DBConnection,
+		DBName:       data.DBName,
+		BusinessName: data.BusinessName,
+		GeneratedAt:  data.GeneratedAt,
+		HealthScore:  &data.HealthScore,
+		Findings:     handler.FindingCounts(data.Modules),
+		Modules:      make([]WebhookModule, 0, len(data.Modules)),
+	}
+	if n.ReportBaseURL != "" && reportID != "" {
+		payload.ReportURL = n.ReportBaseURL + "/report.html?id=" + url.QueryEscape(reportID)
+	}
+	for _, module := range data.Modules {
+		m := WebhookModule{ID: module.ID, Title: module.Name, Status: "ok", HealthScore: module.HealthScore}
+		if module.Error != "" {
+			m.Status, m.Error = "error", module.Error
+			payload.Event = EventModuleErrors
+		}
+		payload.Modules = append(payload.Modules, m)
+	}
+	return n.deliver(payload)
+}
+
+// NotifyFailure implements handler.FailureNotifier.
+func (n *WebhookNotifier) NotifyFailure(failure handler.InspectionFailure) error {
+	return n.deliver(WebhookPayload{
+		Event:        EventFailed,
+		DBConnection: failure.DBConnection,
+		BusinessName: failure.BusinessName,
+		Error:        failure.Error,
+	})
+}
+
+// deliver posts payload when the webhook subscribes to its event, retrying with exponential backoff
+// on network errors, 408, 429 and 5xx responses.
+func (n *WebhookNotifier) deliver(payload WebhookPayload) error {
+	if !n.Config.subscribes(payload.Event) {
+		return nil
+	}
+	id, err := newDeliveryID()
+	if err != nil {
+		return err
+	}
+	payload.DeliveryID = id
+	payload.SentAt = time.Now().Format(time.RFC3339)
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	wait := webhookBackoff
+	for attempt := 1; ; attempt++ {
+		retry, retryAfter, err := n.post(payload.Event, id, body)
+		if err == nil {
+			logger.Infof("Webhook %s: %s delivered for %s", n.Config.Name, payload.Event, payload.DBConnection)
+			return nil
+		}
+		if !retry || attempt >= n.Config.MaxAttempts {
+			return fmt.Errorf("%s not delivered after %d attempt(s): %w", payload.Event, attempt, err)
+		}
+		if retryAfter > wait {
+			wait = min(retryAfter, webhookMaxBackoff)
+		}
+		logger.Warnf("Webhook %s: attempt %d failed, retrying in %s: %v", n.Config.Name, attempt, wait, err)
+		webhookSleep(wait)
+		wait = min(wait*2, webhookMaxBackoff)
+	}
+}
+
+// post sends one attempt. It reports whether a failed attempt is worth retrying, and the delay the
+// server asked for with Retry-After.
+func (n *WebhookNotifier) post(event, deliveryID string, body []byte) (bool, time.Duration, error) {
+	req, err := http.NewRequest(http.MethodPost, n.Config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, 0, err
+	}
+	for name, value := range n.Config.Headers {
+		req.Header.Set(name, value)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Inspect4Oracle/"+handler.GeneratorVersion)
+	req.Header.Set(headerEvent, event)
+	req.Header.Set(headerDelivery, deliveryID)
+	req.Header.Set(headerTimestamp, timestamp)
+	if len(n.Config.secret) > 0 {
+		req.Header.Set(headerSignature, "sha256="+Signature(n.Config.secret, timestamp, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, 0, err
+	}
+	defer resp.Body.Close()
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, 0, nil
+	}
+	err = fmt.Errorf("%s responded %s: %s", n.Config.URL, resp.Status, bytes.TrimSpace(detail))
+	retry := resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	var retryAfter time.Duration
+	if seconds, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && seconds > 0 {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+	return retry, retryAfter, err
+}
+
+// Signature returns the hex HMAC-SHA256 of "<timestamp>.<body>" under secret. Receivers recompute it
+// from the X-Inspect4Oracle-Timestamp header and the raw body, and should reject stale timestamps.
+func Signature(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newDeliveryID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,167 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/handler"
+)
+
+// webhookRequest is one attempt received by the test endpoint.
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// webhookServer answers the attempts with statuses in order and records them.
+func webhookServer(t *testing.T, statuses []int, retryAfter string) (*httptest.Server, func() []webhookRequest) {
+	var mu sync.Mutex
+	var received []webhookRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read body: %v", err)
+		}
+		mu.Lock()
+		received = append(received, webhookRequest{header: r.Header.Clone(), body: body})
+		attempt := len(received)
+		mu.Unlock()
+		status := http.StatusOK
+		if attempt <= len(statuses) {
+			status = statuses[attempt-1]
+		}
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []webhookRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]webhookRequest(nil), received...)
+	}
+}
+
+// recordSleeps replaces webhookSleep for the test and returns the waits requested so far.
+func recordSleeps(t *testing.T) func() []time.Duration {
+	var mu sync.Mutex
+	var waits []time.Duration
+	webhookSleep = func(d time.Duration) {
+		mu.Lock()
+		waits = append(waits, d)
+		mu.Unlock()
+	}
+	t.Cleanup(func() { webhookSleep = time.Sleep })
+	return func() []time.Duration {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]time.Duration(nil), waits...)
+	}
+}
+
+func newTestWebhook(t *testing.T, url string, maxAttempts int) *WebhookNotifier {
+	t.Setenv("TEST_WEBHOOK_SECRET", "s3cret")
+	cfg := WebhookConfig{URL: url, Secret: "env:TEST_WEBHOOK_SECRET", MaxAttempts: maxAttempts}
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	return NewWebhookNotifier(cfg, "http://inspect.example.com:8080")
+}
+
+func TestWebhookNotifySignsAndRetries(t *testing.T) {
+	srv, received := webhookServer(t, []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusTooManyRequests}, "30")
+	sleeps := recordSleeps(t)
+	n := newTestWebhook(t, srv.URL, 5)
+
+	data := handler.ReportData{
+		DBConnection: "10.0.0.7:1521/ORCLPDB",
+		DBName:       "ORCL",
+		HealthScore:  80,
+		Modules: []handler.ReportModule{
+			{ID: "dbinfo", Name: "Database Info", HealthScore: 100},
+			{ID: "storage", Name: "Storage", Error: "ORA-00942: table or view does not exist"},
+		},
+	}
+	if err := n.Notify("r1", data); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	requests := received()
+	if len(requests) != 4 {
+		t.Fatalf("got %d attempts, want 4", len(requests))
+	}
+	// 2s and 4s of exponential backoff, then the 30s asked for by Retry-After
+	want := []time.Duration{2 * time.Second, 4 * time.Second, 30 * time.Second}
+	if got := sleeps(); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("backoff = %v, want %v", got, want)
+	}
+
+	first := requests[0]
+	for i, req := range requests {
+		ts := req.header.Get(headerTimestamp)
+		if got, want := req.header.Get(headerSignature), "sha256="+Signature([]byte("s3cret"), ts, req.body); got != want {
+			t.Errorf("attempt %d: signature = %q, want %q", i+1, got, want)
+		}
+		if req.header.Get(headerEvent) != EventModuleErrors {
+			t.Errorf("attempt %d: event = %q", i+1, req.header.Get(headerEvent))
+		}
+		if req.header.Get(headerDelivery) != first.header.Get(headerDelivery) || string(req.body) != string(first.body) {
+			t.Errorf("attempt %d: delivery ID or body changed between retries", i+1)
+		}
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(first.body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.DeliveryID != first.header.Get(headerDelivery) || payload.ReportURL != "http://inspect.example.com:8080/report.html?id=r1" {
+		t.Errorf("payload = %+v", payload)
+	}
+	if len(payload.Modules) != 2 || payload.Modules[0].Title != "Database Info" || payload.Modules[1].Title != "Storage" || payload.Modules[1].Status != "error" {
+		t.Errorf("modules = %+v", payload.Modules)
+	}
+}
+
+func TestWebhookSignature(t *testing.T) {
+	// HMAC-SHA256 of "1700000000.{}" under "key"
+	if got, want := Signature([]byte("key"), "1700000000", []byte("{}")), "9d713ed406bb7076d4123f0dc2c39d2df5c654ed4b0cd56b52c8b4c940bd63ae"; got != want {
+		t.Errorf("Signature = %q, want %q", got, want)
+	}
+}
+
+func TestWebhookDeliveryGivesUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		attempts int
+		sleeps   int
+	}{
+		{"client error is not retried", []int{http.StatusBadRequest}, 1, 0},
+		{"server errors until the last attempt", []int{500, 500, 500, 500}, 3, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, received := webhookServer(t, tt.statuses, "")
+			sleeps := recordSleeps(t)
+			n := newTestWebhook(t, srv.URL, 3)
+
+			err := n.NotifyFailure(handler.InspectionFailure{DBConnection: "10.0.0.7:1521/ORCLPDB", Error: "ORA-12541: TNS:no listener"})
+			if err == nil || !strings.Contains(err.Error(), EventFailed) {
+				t.Fatalf("NotifyFailure error = %v", err)
+			}
+			if got := len(received()); got != tt.attempts {
+				t.Errorf("got %d attempts, want %d", got, tt.attempts)
+			}
+			if got := len(sleeps()); got != tt.sleeps {
+				t.Errorf("slept %d times, want %d", got, tt.sleeps)
+			}
+		})
+	}
+}
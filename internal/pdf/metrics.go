@@ -0,0 +1,134 @@
+package pdf
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Glyph widths of the printable ASCII characters (32-126) in 1/1000 em, from the Adobe font metrics.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// runeWidth returns the advance width of r in 1/1000 em.
+func runeWidth(r rune, bold bool) int {
+	switch {
+	case r >= 0x20 && r < 0x7f:
+		if bold {
+			return helveticaBoldWidths[r-0x20]
+		}
+		return helveticaWidths[r-0x20]
+	case isLatin(r):
+		return 556 // Accented Latin-1 letters are close to the width of a digit
+	case r < 0x20 || r == 0x7f:
+		return 278 // Drawn as a space
+	default:
+		return 1000 // CJK glyphs are full-width
+	}
+}
+
+// TextWidth returns the width of s in points at the given font size.
+func TextWidth(s string, size float64, bold bool) float64 {
+	total := 0
+	for _, r := range s {
+		total += runeWidth(r, bold)
+	}
+	return float64(total) * size / 1000
+}
+
+// Wrap breaks s into lines no wider than width. Lines break at spaces, between CJK characters,
+// and inside words that are too long to fit on a line of their own. Newlines in s are kept.
+func Wrap(s string, width, size float64, bold bool) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		lines = append(lines, wrapParagraph(paragraph, width, size, bold)...)
+	}
+	return lines
+}
+
+func wrapParagraph(s string, width, size float64, bold bool) []string {
+	var lines []string
+	var line strings.Builder
+	lineWidth := 0.0
+	flush := func() {
+		lines = append(lines, strings.TrimRight(line.String(), " "))
+		line.Reset()
+		lineWidth = 0
+	}
+
+	for _, token := range tokenize(s) {
+		w := TextWidth(token, size, bold)
+		if lineWidth+w <= width {
+			line.WriteString(token)
+			lineWidth += w
+			continue
+		}
+		if token == " " {
+			flush()
+			continue
+		}
+		if line.Len() > 0 {
+			flush()
+		}
+		if w <= width {
+			line.WriteString(token)
+			lineWidth = w
+			continue
+		}
+		// The token is wider than a whole line: break it between characters.
+		for _, r := range token {
+			rw := float64(runeWidth(r, bold)) * size / 1000
+			if lineWidth+rw > width && line.Len() > 0 {
+				flush()
+			}
+			line.WriteRune(r)
+			lineWidth += rw
+		}
+	}
+	if line.Len() > 0 || len(lines) == 0 {
+		flush()
+	}
+	return lines
+}
+
+// tokenize splits s into words, single spaces and single CJK characters.
+func tokenize(s string) []string {
+	var tokens []string
+	var word strings.Builder
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r):
+			if word.Len() > 0 {
+				tokens = append(tokens, word.String())
+				word.Reset()
+			}
+			tokens = append(tokens, " ")
+		case !isLatin(r):
+			if word.Len() > 0 {
+				tokens = append(tokens, word.String())
+				word.Reset()
+			}
+			tokens = append(tokens, string(r))
+		default:
+			word.WriteRune(r)
+		}
+	}
+	if word.Len() > 0 {
+		tokens = append(tokens, word.String())
+	}
+	return tokens
+}
@@ -0,0 +1,60 @@
+package pdf
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestTextWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		bold bool
+		want float64
+	}{
+		{"", false, 0},
+		{"hello", false, 21.12},
+		{"hello", true, 23.34},
+		{"数据库", false, 30},
+		{"DB 库", false, 26.67},
+		{"é", false, 5.56},
+	}
+	for _, tt := range tests {
+		if got := TextWidth(tt.s, 10, tt.bold); math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("TextWidth(%q, bold=%v) = %v, want %v", tt.s, tt.bold, got, tt.want)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		width float64
+		want  []string
+	}{
+		{"fits", "hello world", 100, []string{"hello world"}},
+		{"breaks at spaces", "hello world", 30, []string{"hello", "world"}},
+		{"drops the space at a break", "hello   world", 30, []string{"hello", "world"}},
+		{"breaks between CJK characters", "数据库巡检", 25, []string{"数据", "库巡", "检"}},
+		{"mixed Latin and CJK", "DB 数据库 OK", 30, []string{"DB 数", "据库", "OK"}},
+		{"Latin word after CJK", "表空间USERS已满", 40, []string{"表空间", "USERS", "已满"}},
+		{"breaks long words", "abcdefghij", 20, []string{"abc", "defg", "hij"}},
+		{"keeps newlines", "a\r\nb\n\nc", 100, []string{"a", "b", "", "c"}},
+		{"empty", "", 100, []string{""}},
+		{"character wider than the line", "库", 5, []string{"库"}},
+	}
+	for _, tt := range tests {
+		if got := Wrap(tt.in, tt.width, 10, false); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Wrap(%q, %v) = %q, want %q", tt.name, tt.in, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := tokenize("CPU使用 85%\tok")
+	want := []string{"CPU", "使", "用", " ", "85%", " ", "ok"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tokenize = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,416 @@
+// Package pdf is a small PDF writer used for the server-side report export.
+//
+// It supports the standard Helvetica fonts for Latin text, the non-embedded Adobe CJK fonts for
+// Chinese and Japanese text, simple vector graphics, internal links and bookmarks. Coordinates are
+// in points with the origin at the top-left corner of an A4 page; text is positioned by its baseline.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// A4 page size in points.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// CJKFont selects the font used for characters that Helvetica cannot show.
+type CJKFont int
+
+const (
+	SimplifiedChinese CJKFont = iota // STSong-Light
+	Japanese                         // KozMinPro-Regular-Acro
+)
+
+type cjkFontInfo struct {
+	baseFont   string
+	cmap       string
+	ordering   string
+	supplement int
+}
+
+var cjkFonts = map[CJKFont]cjkFontInfo{
+	SimplifiedChinese: {baseFont: "STSong-Light", cmap: "UniGB-UCS2-H", ordering: "GB1", supplement: 4},
+	Japanese:          {baseFont: "KozMinPro-Regular-Acro", cmap: "UniJIS-UCS2-H", ordering: "Japan1", supplement: 4},
+}
+
+// Color is an RGB color with components between 0 and 1.
+type Color struct {
+	R, G, B float64
+}
+
+// RGB builds a Color from 8-bit components.
+func RGB(r, g, b uint8) Color {
+	return Color{R: float64(r) / 255, G: float64(g) / 255, B: float64(b) / 255}
+}
+
+// Point is a position on a page.
+type Point struct {
+	X, Y float64
+}
+
+// Document is a PDF document under construction. Pages can be drawn on in any order until WriteTo is called.
+type Document struct {
+	title     string
+	cjk       CJKFont
+	pages     []*Page
+	bookmarks []destination
+}
+
+// Page is a single page of a Document.
+type Page struct {
+	content bytes.Buffer
+	links   []link
+}
+
+// destination is a position in the document, used by links and bookmarks.
+type destination struct {
+	title string
+	page  int
+	top   float64
+}
+
+type link struct {
+	x, y, w, h float64
+	dest       destination
+}
+
+// New creates an empty document. title is stored in the document information dictionary.
+func New(title string, cjk CJKFont) *Document {
+	return &Document{title: title, cjk: cjk}
+}
+
+// AddPage appends a blank page and returns it.
+func (d *Document) AddPage() *Page {
+	p := &Page{}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Page returns the page with the given zero-based index.
+func (d *Document) Page(index int) *Page {
+	return d.pages[index]
+}
+
+// PageCount returns the number of pages added so far.
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// AddBookmark adds an entry to the document outline that jumps to top on the given page.
+func (d *Document) AddBookmark(title string, page int, top float64) {
+	d.bookmarks = append(d.bookmarks, destination{title: title, page: page, top: top})
+}
+
+// Text draws s with its baseline at y. Characters outside Latin-1 are drawn with the CJK font.
+func (p *Page) Text(x, y, size float64, bold bool, c Color, s string) {
+	if s == "" {
+		return
+	}
+	fmt.Fprintf(&p.content, "BT %s %s %s rg %s %s %s RG %s %s Td\n",
+		num(c.R), num(c.G), num(c.B), num(c.R), num(c.G), num(c.B), num(x), num(PageHeight-y))
+	for _, r := range splitRuns(s) {
+		if r.cjk {
+			if bold {
+				// The CJK fonts have no bold face; fill and stroke the outline instead.
+				fmt.Fprintf(&p.content, "/F3 %s Tf 2 Tr %s w <%s> Tj 0 Tr\n", num(size), num(size/30), ucs2Hex(r.text))
+			} else {
+				fmt.Fprintf(&p.content, "/F3 %s Tf <%s> Tj\n", num(size), ucs2Hex(r.text))
+			}
+			continue
+		}
+		font := "/F1"
+		if bold {
+			font = "/F2"
+		}
+		fmt.Fprintf(&p.content, "%s %s Tf (%s) Tj\n", font, num(size), latinString(r.text))
+	}
+	p.content.WriteString("ET\n")
+}
+
+// FillRect fills a rectangle whose top-left corner is (x, y).
+func (p *Page) FillRect(x, y, w, h float64, c Color) {
+	fmt.Fprintf(&p.content, "%s %s %s rg %s %s %s %s re f\n",
+		num(c.R), num(c.G), num(c.B), num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+// StrokeRect draws the outline of a rectangle whose top-left corner is (x, y).
+func (p *Page) StrokeRect(x, y, w, h, width float64, c Color) {
+	fmt.Fprintf(&p.content, "%s %s %s RG %s w %s %s %s %s re S\n",
+		num(c.R), num(c.G), num(c.B), num(width), num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+// Line draws a straight line.
+func (p *Page) Line(x1, y1, x2, y2, width float64, c Color) {
+	p.Polyline([]Point{{x1, y1}, {x2, y2}}, width, c)
+}
+
+// Polyline draws connected line segments through points.
+func (p *Page) Polyline(points []Point, width float64, c Color) {
+	if len(points) < 2 {
+		return
+	}
+	fmt.Fprintf(&p.content, "%s %s %s RG %s w 1 j\n", num(c.R), num(c.G), num(c.B), num(width))
+	for i, pt := range points {
+		op := "l"
+		if i == 0 {
+			op = "m"
+		}
+		fmt.Fprintf(&p.content, "%s %s %s\n", num(pt.X), num(PageHeight-pt.Y), op)
+	}
+	p.content.WriteString("S\n")
+}
+
+// Link makes the rectangle at (x, y) a link to top on the given page.
+func (p *Page) Link(x, y, w, h float64, page int, top float64) {
+	p.links = append(p.links, link{x: x, y: y, w: w, h: h, dest: destination{page: page, top: top}})
+}
+
+// WriteTo writes the finished document to w.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var buf bytes.Buffer
+	offsets := map[int]int{}
+	next := 1
+	alloc := func() int {
+		next++
+		return next - 1
+	}
+	begin := func(obj int) {
+		offsets[obj] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n", obj)
+	}
+	end := func() { buf.WriteString("endobj\n") }
+
+	catalogObj, pagesObj, infoObj := alloc(), alloc(), alloc()
+	regularObj, boldObj, cjkObj, cjkDescendantObj, cjkDescriptorObj := alloc(), alloc(), alloc(), alloc(), alloc()
+	pageObjs := make([]int, len(d.pages))
+	contentObjs := make([]int, len(d.pages))
+	for i := range d.pages {
+		pageObjs[i], contentObjs[i] = alloc(), alloc()
+	}
+	outlinesObj := 0
+	bookmarkObjs := make([]int, len(d.bookmarks))
+	if len(d.bookmarks) > 0 {
+		outlinesObj = alloc()
+		for i := range d.bookmarks {
+			bookmarkObjs[i] = alloc()
+		}
+	}
+	destArray := func(dest destination) string {
+		page := dest.page
+		if page < 0 || page >= len(pageObjs) {
+			page = 0
+		}
+		return fmt.Sprintf("[%d 0 R /XYZ 0 %s 0]", pageObjs[page], num(PageHeight-dest.top))
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	begin(catalogObj)
+	fmt.Fprintf(&buf, "<< /Type /Catalog /Pages %d 0 R", pagesObj)
+	if outlinesObj != 0 {
+		fmt.Fprintf(&buf, " /Outlines %d 0 R /PageMode /UseOutlines", outlinesObj)
+	}
+	buf.WriteString(" >>\n")
+	end()
+
+	begin(pagesObj)
+	fmt.Fprintf(&buf, "<< /Type /Pages /Count %d /MediaBox [0 0 %s %s] /Kids [", len(pageObjs), num(PageWidth), num(PageHeight))
+	for _, obj := range pageObjs {
+		fmt.Fprintf(&buf, "%d 0 R ", obj)
+	}
+	buf.WriteString("] >>\n")
+	end()
+
+	begin(infoObj)
+	fmt.Fprintf(&buf, "<< /Title <%s> /Producer (Inspect4Oracle) /CreationDate (D:%s) >>\n",
+		utf16Hex(d.title), time.Now().Format("20060102150405"))
+	end()
+
+	begin(regularObj)
+	buf.WriteString("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>\n")
+	end()
+	begin(boldObj)
+	buf.WriteString("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>\n")
+	end()
+
+	cjk := cjkFonts[d.cjk]
+	begin(cjkObj)
+	fmt.Fprintf(&buf, "<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /%s /DescendantFonts [%d 0 R] >>\n",
+		cjk.baseFont, cjk.cmap, cjkDescendantObj)
+	end()
+	begin(cjkDescendantObj)
+	fmt.Fprintf(&buf, "<< /Type /Font /Subtype /CIDFontType0 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (%s) /Supplement %d >> /FontDescriptor %d 0 R /DW 1000 >>\n",
+		cjk.baseFont, cjk.ordering, cjk.supplement, cjkDescriptorObj)
+	end()
+	begin(cjkDescriptorObj)
+	fmt.Fprintf(&buf, "<< /Type /FontDescriptor /FontName /%s /Flags 6 /FontBBox [-25 -254 1000 880] /ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>\n",
+		cjk.baseFont)
+	end()
+
+	for i, page := range d.pages {
+		annotObjs := make([]int, len(page.links))
+		for j := range page.links {
+			annotObjs[j] = alloc()
+		}
+
+		begin(pageObjs[i])
+		fmt.Fprintf(&buf, "<< /Type /Page /Parent %d 0 R /Resources << /Font << /F1 %d 0 R /F2 %d 0 R /F3 %d 0 R >> >> /Contents %d 0 R",
+			pagesObj, regularObj, boldObj, cjkObj, contentObjs[i])
+		if len(annotObjs) > 0 {
+			buf.WriteString(" /Annots [")
+			for _, obj := range annotObjs {
+				fmt.Fprintf(&buf, "%d 0 R ", obj)
+			}
+			buf.WriteString("]")
+		}
+		buf.WriteString(" >>\n")
+		end()
+
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		if _, err := zw.Write(page.content.Bytes()); err != nil {
+			return 0, err
+		}
+		if err := zw.Close(); err != nil {
+			return 0, err
+		}
+		begin(contentObjs[i])
+		fmt.Fprintf(&buf, "<< /Length %d /Filter /FlateDecode >>\nstream\n", compressed.Len())
+		buf.Write(compressed.Bytes())
+		buf.WriteString("\nendstream\n")
+		end()
+
+		for j, l := range page.links {
+			begin(annotObjs[j])
+			fmt.Fprintf(&buf, "<< /Type /Annot /Subtype /Link /Rect [%s %s %s %s] /Border [0 0 0] /Dest %s >>\n",
+				num(l.x), num(PageHeight-l.y-l.h), num(l.x+l.w), num(PageHeight-l.y), destArray(l.dest))
+			end()
+		}
+	}
+
+	if outlinesObj != 0 {
+		begin(outlinesObj)
+		fmt.Fprintf(&buf, "<< /Type /Outlines /First %d 0 R /Last %d 0 R /Count %d >>\n",
+			bookmarkObjs[0], bookmarkObjs[len(bookmarkObjs)-1], len(bookmarkObjs))
+		end()
+		for i, b := range d.bookmarks {
+			begin(bookmarkObjs[i])
+			fmt.Fprintf(&buf, "<< /Title <%s> /Parent %d 0 R /Dest %s", utf16Hex(b.title), outlinesObj, destArray(b))
+			if i > 0 {
+				fmt.Fprintf(&buf, " /Prev %d 0 R", bookmarkObjs[i-1])
+			}
+			if i < len(bookmarkObjs)-1 {
+				fmt.Fprintf(&buf, " /Next %d 0 R", bookmarkObjs[i+1])
+			}
+			buf.WriteString(" >>\n")
+			end()
+		}
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", next)
+	for obj := 1; obj < next; obj++ {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offsets[obj])
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", next, catalogObj, infoObj, xref)
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// num formats a coordinate or color component compactly.
+func num(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "" || s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// textRun is a piece of text drawn with a single font.
+type textRun struct {
+	text string
+	cjk  bool
+}
+
+// isLatin reports whether Helvetica with WinAnsiEncoding can show r.
+func isLatin(r rune) bool {
+	return (r >= 0x20 && r < 0x7f) || (r >= 0xa0 && r <= 0xff)
+}
+
+// splitRuns splits s into runs of Latin and CJK text. Control characters become spaces
+// and characters outside the Basic Multilingual Plane become question marks.
+func splitRuns(s string) []textRun {
+	var runs []textRun
+	var cur strings.Builder
+	curCJK := false
+	for _, r := range s {
+		switch {
+		case r < 0x20 || r == 0x7f:
+			r = ' '
+		case r > 0xffff:
+			r = '?'
+		}
+		cjk := !isLatin(r)
+		if cur.Len() > 0 && cjk != curCJK {
+			runs = append(runs, textRun{text: cur.String(), cjk: curCJK})
+			cur.Reset()
+		}
+		curCJK = cjk
+		cur.WriteRune(r)
+	}
+	if cur.Len() > 0 {
+		runs = append(runs, textRun{text: cur.String(), cjk: curCJK})
+	}
+	return runs
+}
+
+// latinString encodes Latin-1 text as the body of a PDF literal string.
+func latinString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x80:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// ucs2Hex encodes BMP text as UCS-2 big-endian hex for the CJK CMaps.
+func ucs2Hex(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		fmt.Fprintf(&b, "%04X", r)
+	}
+	return b.String()
+}
+
+// utf16Hex encodes s as a UTF-16BE hex string with byte order mark, for document metadata.
+func utf16Hex(s string) string {
+	var b strings.Builder
+	b.WriteString("FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	return b.String()
+}
@@ -0,0 +1,172 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// checkXref verifies that the trailer and every cross-reference entry of a written document point at the right place.
+func checkXref(t *testing.T, data []byte) {
+	t.Helper()
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(data)
+	if m == nil {
+		t.Fatalf("no startxref at the end of the document")
+	}
+	start, _ := strconv.Atoi(string(m[1]))
+	if start >= len(data) || !bytes.HasPrefix(data[start:], []byte("xref\n0 ")) {
+		t.Fatalf("startxref %d does not point at the xref table", start)
+	}
+	var size int
+	if _, err := fmt.Sscanf(string(data[start:]), "xref\n0 %d\n", &size); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte(fmt.Sprintf("trailer\n<< /Size %d ", size))) {
+		t.Errorf("trailer /Size does not match the %d xref entries", size)
+	}
+	entries := data[start+len(fmt.Sprintf("xref\n0 %d\n", size)):]
+	for obj := 0; obj < size; obj++ {
+		entry := string(entries[obj*20 : (obj+1)*20])
+		if obj == 0 {
+			if entry != "0000000000 65535 f \n" {
+				t.Errorf("xref entry 0 = %q", entry)
+			}
+			continue
+		}
+		offset, err := strconv.Atoi(entry[:10])
+		if err != nil || entry[10:] != " 00000 n \n" {
+			t.Fatalf("xref entry %d = %q", obj, entry)
+		}
+		if want := fmt.Sprintf("%d 0 obj\n", obj); !bytes.HasPrefix(data[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q, want %q", obj, data[offset:offset+10], want)
+		}
+	}
+}
+
+func TestWriteToCrossReferences(t *testing.T) {
+	tests := []struct {
+		name      string
+		pages     int
+		links     int
+		bookmarks []string
+	}{
+		{name: "empty document"},
+		{name: "one page", pages: 1},
+		{name: "links and bookmarks", pages: 3, links: 2, bookmarks: []string{"Summary", "存储", "Performance"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New("巡检报告 Report", SimplifiedChinese)
+			for i := 0; i < tt.pages; i++ {
+				p := d.AddPage()
+				p.Text(40, 60, 12, i%2 == 0, RGB(0, 0, 0), fmt.Sprintf("Page %d 第%d页 (a\\b)", i+1, i+1))
+				p.FillRect(40, 80, 100, 20, RGB(233, 236, 239))
+				for j := 0; j < tt.links; j++ {
+					p.Link(40, 100+float64(j)*20, 200, 18, (i+1)%tt.pages, 50)
+				}
+			}
+			for i, title := range tt.bookmarks {
+				d.AddBookmark(title, i, 50)
+			}
+			var buf bytes.Buffer
+			n, err := d.WriteTo(&buf)
+			if err != nil || n != int64(buf.Len()) {
+				t.Fatalf("WriteTo = %d, %v; wrote %d bytes", n, err, buf.Len())
+			}
+			data := buf.Bytes()
+			if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) {
+				t.Errorf("missing PDF header")
+			}
+			checkXref(t, data)
+
+			pages := tt.pages
+			if pages == 0 {
+				pages = 1 // WriteTo adds a blank page
+			}
+			if got := bytes.Count(data, []byte("<< /Type /Page /Parent")); got != pages {
+				t.Errorf("%d page objects, want %d", got, pages)
+			}
+			if !bytes.Contains(data, []byte(fmt.Sprintf("/Type /Pages /Count %d ", pages))) {
+				t.Errorf("page tree /Count is not %d", pages)
+			}
+			if got, want := bytes.Count(data, []byte("/Subtype /Link")), tt.pages*tt.links; got != want {
+				t.Errorf("%d link annotations, want %d", got, want)
+			}
+			if len(tt.bookmarks) == 0 {
+				if bytes.Contains(data, []byte("/Outlines")) {
+					t.Errorf("outline written without bookmarks")
+				}
+				return
+			}
+			// Objects 1-8 are the catalog, page tree, info and fonts, followed by a page and a content stream per page
+			outline := 9 + 2*pages
+			if !bytes.Contains(data, []byte(fmt.Sprintf("%d 0 obj\n<< /Type /Outlines /First %d 0 R /Last %d 0 R /Count %d", outline, outline+1, outline+len(tt.bookmarks), len(tt.bookmarks)))) {
+				t.Errorf("outline root does not list the %d bookmarks", len(tt.bookmarks))
+			}
+			for _, title := range tt.bookmarks {
+				if !bytes.Contains(data, []byte("/Title <"+utf16Hex(title)+">")) {
+					t.Errorf("bookmark %q missing", title)
+				}
+			}
+		})
+	}
+}
+
+func TestTextRuns(t *testing.T) {
+	d := New("", SimplifiedChinese)
+	p := d.AddPage()
+	p.Text(40, 100, 10, false, RGB(0, 0, 0), "CPU 使用率 (85%)")
+	p.Text(40, 120, 10, true, RGB(0, 0, 0), "表空间")
+	content := p.content.String()
+	for _, want := range []string{
+		"/F1 10 Tf (CPU ) Tj\n/F3 10 Tf <4F7F75287387> Tj\n/F1 10 Tf ( \\(85%\\)) Tj\n",
+		"/F3 10 Tf 2 Tr 0.33 w <88687A7A95F4> Tj 0 Tr\n",
+		" 40 741.89 Td\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("content stream %q does not contain %q", content, want)
+		}
+	}
+}
+
+func TestSplitRuns(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []textRun
+	}{
+		{"", nil},
+		{"ORCL", []textRun{{"ORCL", false}}},
+		{"CPU 使用率 85%", []textRun{{"CPU ", false}, {"使用率", true}, {" 85%", false}}},
+		{"データベース", []textRun{{"データベース", true}}},
+		{"Café ñ", []textRun{{"Café ñ", false}}},
+		{"a\tb\nc", []textRun{{"a b c", false}}},
+		{"ok 😀", []textRun{{"ok ?", false}}},
+		{"a—b", []textRun{{"a", false}, {"—", true}, {"b", false}}},
+	}
+	for _, tt := range tests {
+		if got := splitRuns(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitRuns(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestStringEncoding(t *testing.T) {
+	if got, want := latinString(`(a)\é`), `\(a\)\\\351`; got != want {
+		t.Errorf("latinString = %q, want %q", got, want)
+	}
+	if got, want := ucs2Hex("库A"), "5E930041"; got != want {
+		t.Errorf("ucs2Hex = %q, want %q", got, want)
+	}
+	if got, want := utf16Hex("a😀"), "FEFF0061D83DDE00"; got != want {
+		t.Errorf("utf16Hex = %q, want %q", got, want)
+	}
+	for v, want := range map[float64]string{0: "0", 1.5: "1.5", 2.004: "2", -0.001: "0", 841.89: "841.89", 10: "10"} {
+		if got := num(v); got != want {
+			t.Errorf("num(%v) = %q, want %q", v, got, want)
+		}
+	}
+}
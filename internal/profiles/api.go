@@ -0,0 +1,137 @@
+package profiles
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+	"github.com/gorilla/mux"
+)
+
+// RegisterRoutes adds the profile endpoints to an API router. A nil store answers that profiles
+// are disabled, so the index page can hide its picker.
+//
+//	GET    /profiles       list every profile (without passwords)
+//	POST   /profiles       create a profile; the body is a Profile including its password
+//	GET    /profiles/{id}  a single profile
+//	PUT    /profiles/{id}  replace a profile; an empty password keeps the saved one if the connection is unchanged
+//	DELETE /profiles/{id}  delete a profile
+func (s *Store) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/profiles", s.listHandler).Methods("GET")
+	r.HandleFunc("/profiles", s.createHandler).Methods("POST")
+	r.HandleFunc("/profiles/{id}", s.getHandler).Methods("GET")
+	r.HandleFunc("/profiles/{id}", s.updateHandler).Methods("PUT")
+	r.HandleFunc("/profiles/{id}", s.deleteHandler).Methods("DELETE")
+}
+
+func (s *Store) listHandler(w http.ResponseWriter, r *http.Request) {
+	if s == nil {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "enabled": false, "profiles": []Profile{}})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "enabled": true, "profiles": s.List()})
+}
+
+func (s *Store) getHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.enabled(w) {
+		return
+	}
+	p, err := s.Get(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "profile": p})
+}
+
+func (s *Store) createHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.enabled(w) {
+		return
+	}
+	var p Profile
+	if !decodeProfile(w, r, &p) {
+		return
+	}
+	created, err := s.Create(p)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	logger.Infof("Connection profile %s (%s) created", created.ID, created.Name)
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"success": true, "profile": created})
+}
+
+func (s *Store) updateHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.enabled(w) {
+		return
+	}
+	var p Profile
+	if !decodeProfile(w, r, &p) {
+		return
+	}
+	updated, err := s.Update(mux.Vars(r)["id"], p)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	logger.Infof("Connection profile %s (%s) updated", updated.ID, updated.Name)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "profile": updated})
+}
+
+func (s *Store) deleteHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.enabled(w) {
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if err := s.Delete(id); err != nil {
+		writeError(w, err)
+		return
+	}
+	logger.Infof("Connection profile %s deleted", id)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "message": fmt.Sprintf("Profile %s deleted", id)})
+}
+
+// enabled answers 404 when no profile file is configured.
+func (s *Store) enabled(w http.ResponseWriter) bool {
+	if s == nil {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"message": "Connection profiles are not enabled (start with -profiles)",
+		})
+		return false
+	}
+	return true
+}
+
+func decodeProfile(w http.ResponseWriter, r *http.Request, p *Profile) bool {
+	defer r.Body.Close()
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(p); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"success": false, "message": "Invalid request body: " + err.Error()})
+		return false
+	}
+	return true
+}
+
+// writeError maps store errors to status codes: unknown IDs are 404, file errors 500 and invalid profiles 400.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, errSave):
+		status = http.StatusInternalServerError
+		logger.Errorf("%v", err)
+	}
+	writeJSON(w, status, map[string]interface{}{"success": false, "message": err.Error()})
+}
+
+// writeJSON encodes payload as the JSON response body.
+func writeJSON(w http.ResponseWriter, statusCode int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(payload); err != nil {
+		logger.Errorf("Failed to encode profile response: %v", err)
+	}
+}
@@ -0,0 +1,363 @@
+// Package profiles keeps saved database connection profiles, encrypted at rest with a master key.
+package profiles
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/fleet"
+	"github.com/goodwaysIT/inspect4oracle/internal/handler"
+)
+
+// DefaultKeyRef is where the master key is read from when none is configured.
+const DefaultKeyRef = "env:INSPECT4ORACLE_PROFILE_KEY"
+
+// minKeyLength guards against trivially guessable master keys; the key is not stretched.
+const minKeyLength = 16
+
+// fileFormat identifies profile files.
+const fileFormat = "inspect4oracle-profiles"
+
+var (
+	// ErrNotFound is returned for unknown profile IDs.
+	ErrNotFound = errors.New("profile not found")
+	// errSave wraps failures to write the profile file, as opposed to invalid profiles.
+	errSave = errors.New("failed to save profiles")
+)
+
+// Profile is a saved set of connection settings. The password never leaves the store through the API.
+type Profile struct {
+	ID               string    `json:"id"`
+	Name             string    `json:"name"`
+	Business         string    `json:"business,omitempty"`
+	Host             string    `json:"host,omitempty"`
+	Port             string    `json:"port,omitempty"`
+	Service          string    `json:"service,omitempty"`
+	ConnectionType   string    `json:"connectionType,omitempty"`
+	ConnectString    string    `json:"connectString,omitempty"`
+	Protocol         string    `json:"protocol,omitempty"`
+	WalletDir        string    `json:"walletDir,omitempty"`
+	ServerCertDN     string    `json:"serverCertDn,omitempty"`
+	Privilege        string    `json:"privilege,omitempty"`
+	ProxyClient      string    `json:"proxyClient,omitempty"`
+	Username         string    `json:"username"`
+	Password         string    `json:"password,omitempty"` // Only in requests and in the encrypted file
+	ThresholdProfile string    `json:"thresholdProfile,omitempty"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// envelope is the on-disk format: the JSON list of profiles, sealed with AES-256-GCM.
+type envelope struct {
+	Format     string `json:"format"`
+	Version    int    `json:"version"`
+	Nonce      string `json:"nonce"`      // hex
+	Ciphertext string `json:"ciphertext"` // hex
+}
+
+// Store holds the profiles of one file. All methods are safe for concurrent use.
+type Store struct {
+	path string
+	aead cipher.AEAD
+
+	mu       sync.RWMutex
+	profiles map[string]Profile
+}
+
+// Open loads the profile file at path, decrypting it with the master key that keyRef ("env:VAR" or
+// "file:PATH") points to. A missing file starts an empty store that is created on the first save.
+func Open(path, keyRef string) (*Store, error) {
+	if keyRef == "" {
+		keyRef = DefaultKeyRef
+	}
+	key, err := fleet.ResolveCredentials(keyRef)
+	if err != nil {
+		return nil, fmt.Errorf("profile master key: %w", err)
+	}
+	if len(key) < minKeyLength {
+		return nil, fmt.Errorf("profile master key must be at least %d characters, e.g. from 'openssl rand -base64 32'", minKeyLength)
+	}
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{path: path, aead: aead, profiles: make(map[string]Profile)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile file: %w", err)
+	}
+	list, err := s.decrypt(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, p := range list {
+		s.profiles[p.ID] = p
+	}
+	return s, nil
+}
+
+// List returns every profile without passwords, sorted by name.
+func (s *Store) List() []Profile {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := make([]Profile, 0, len(s.profiles))
+	for _, p := range s.profiles {
+		p.Password = ""
+		list = append(list, p)
+	}
+	sort.Slice(list, func(a, b int) bool {
+		return strings.ToLower(list[a].Name) < strings.ToLower(list[b].Name)
+	})
+	return list
+}
+
+// Get returns a profile without its password.
+func (s *Store) Get(id string) (Profile, error) {
+	s.mu.RLock()
+	p, ok := s.profiles[id]
+	s.mu.RUnlock()
+	if !ok {
+		return Profile{}, ErrNotFound
+	}
+	p.Password = ""
+	return p, nil
+}
+
+// Create validates and saves a new profile, and returns it without its password.
+func (s *Store) Create(p Profile) (Profile, error) {
+	if err := normalize(&p); err != nil {
+		return Profile{}, err
+	}
+	if p.Password == "" {
+		return Profile{}, errors.New("password is required")
+	}
+	id, err := newID()
+	if err != nil {
+		return Profile{}, err
+	}
+	p.ID = id
+	p.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	p.UpdatedAt = p.CreatedAt
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.profiles[p.ID] = p
+	if err := s.save(); err != nil {
+		delete(s.profiles, p.ID)
+		return Profile{}, err
+	}
+	p.Password = ""
+	return p, nil
+}
+
+// Update replaces a profile. An empty password keeps the saved one, but only while the connection
+// settings stay the same: a saved password is never sent to another database or user.
+func (s *Store) Update(id string, p Profile) (Profile, error) {
+	if err := normalize(&p); err != nil {
+		return Profile{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.profiles[id]
+	if !ok {
+		return Profile{}, ErrNotFound
+	}
+	if p.Password == "" {
+		if !sameConnection(old, p) {
+			return Profile{}, errors.New("password is required when the connection settings change")
+		}
+		p.Password = old.Password
+	}
+	p.ID, p.CreatedAt = id, old.CreatedAt
+	p.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+	s.profiles[id] = p
+	if err := s.save(); err != nil {
+		s.profiles[id] = old
+		return Profile{}, err
+	}
+	p.Password = ""
+	return p, nil
+}
+
+// Delete removes a profile.
+func (s *Store) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.profiles[id]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(s.profiles, id)
+	if err := s.save(); err != nil {
+		s.profiles[id] = old
+		return err
+	}
+	return nil
+}
+
+// ConnectionProfile implements handler.ConnectionProfileStore.
+func (s *Store) ConnectionProfile(id string) (*handler.DBConnectionRequest, error) {
+	s.mu.RLock()
+	p, ok := s.profiles[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return p.request(), nil
+}
+
+func (p Profile) request() *handler.DBConnectionRequest {
+	return &handler.DBConnectionRequest{
+		Business:         p.Business,
+		Host:             p.Host,
+		Port:             p.Port,
+		Service:          p.Service,
+		ConnectionType:   p.ConnectionType,
+		ConnectString:    p.ConnectString,
+		Protocol:         p.Protocol,
+		WalletDir:        p.WalletDir,
+		ServerCertDN:     p.ServerCertDN,
+		Privilege:        p.Privilege,
+		ProxyClient:      p.ProxyClient,
+		Username:         p.Username,
+		Password:         p.Password,
+		ThresholdProfile: p.ThresholdProfile,
+	}
+}
+
+// sameConnection reports whether two normalized profiles connect to the same database as the same user,
+// with the same administrative privilege and proxy client.
+func sameConnection(a, b Profile) bool {
+	return a.Host == b.Host && a.Port == b.Port && a.Service == b.Service && a.ConnectionType == b.ConnectionType &&
+		a.ConnectString == b.ConnectString && a.Protocol == b.Protocol && a.WalletDir == b.WalletDir &&
+		a.ServerCertDN == b.ServerCertDN && a.Username == b.Username && a.Privilege == b.Privilege &&
+		a.ProxyClient == b.ProxyClient
+}
+
+// normalize checks the connection settings the same way /api/validate does, without connecting.
+func normalize(p *Profile) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return errors.New("name is required")
+	}
+	if p.Port == "" {
+		p.Port = "1521"
+	}
+	req := p.request()
+	if err := handler.ValidateConnectionParameters(req); err != nil {
+		return err
+	}
+	// Keep what the user entered for connect strings; the resolved host and service are only for display
+	if p.ConnectString != "" {
+		p.Host, p.Port, p.Service, p.ConnectionType = "", "", "", ""
+	} else {
+		p.ConnectionType = req.ConnectionType
+	}
+	p.Protocol, p.Privilege, p.ProxyClient, p.Username = req.Protocol, req.Privilege, req.ProxyClient, req.Username
+	return nil
+}
+
+// save writes the encrypted file atomically. It must be called with the lock held.
+func (s *Store) save() error {
+	if err := s.write(); err != nil {
+		return fmt.Errorf("%w: %v", errSave, err)
+	}
+	return nil
+}
+
+func (s *Store) write() error {
+	list := make([]Profile, 0, len(s.profiles))
+	for _, p := range s.profiles {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a].ID < list[b].ID })
+	plaintext, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(envelope{
+		Format:     fileFormat,
+		Version:    1,
+		Nonce:      hex.EncodeToString(nonce),
+		Ciphertext: hex.EncodeToString(s.aead.Seal(nil, nonce, plaintext, []byte(fileFormat))),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(s.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("failed to create profile directory: %w", err)
+		}
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write profile file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write profile file: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) decrypt(data []byte) ([]Profile, error) {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil || env.Format != fileFormat {
+		return nil, errors.New("not a profile file")
+	}
+	if env.Version != 1 {
+		return nil, fmt.Errorf("unsupported profile file version %d", env.Version)
+	}
+	nonce, err := hex.DecodeString(env.Nonce)
+	if err != nil || len(nonce) != s.aead.NonceSize() {
+		return nil, errors.New("corrupted profile file")
+	}
+	ciphertext, err := hex.DecodeString(env.Ciphertext)
+	if err != nil {
+		return nil, errors.New("corrupted profile file")
+	}
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, []byte(fileFormat))
+	if err != nil {
+		return nil, errors.New("cannot decrypt profiles: wrong master key or corrupted file")
+	}
+	var list []Profile
+	if err := json.Unmarshal(plaintext, &list); err != nil {
+		return nil, fmt.Errorf("corrupted profile file: %w", err)
+	}
+	return list, nil
+}
+
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
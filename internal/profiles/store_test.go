@@ -0,0 +1,77 @@
+package profiles
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Setenv("TEST_PROFILE_KEY", "0123456789abcdef0123")
+	s, err := Open(filepath.Join(t.TempDir(), "profiles.json"), "env:TEST_PROFILE_KEY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestUpdateKeepsPasswordOnlyForSameConnection(t *testing.T) {
+	s := openTestStore(t)
+	base := Profile{Name: "CRM", Host: "crm1", Port: "1521", Service: "CRMPDB", Username: "monitor", Password: "secret"}
+	created, err := s.Create(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	renamed := base
+	renamed.Name, renamed.Business, renamed.Password = "CRM primary", "CRM", ""
+	if _, err := s.Update(created.ID, renamed); err != nil {
+		t.Fatalf("Update without connection changes: %v", err)
+	}
+	if req, _ := s.ConnectionProfile(created.ID); req.Password != "secret" {
+		t.Errorf("password = %q, want the saved one", req.Password)
+	}
+
+	tests := []struct {
+		name   string
+		change func(p *Profile)
+	}{
+		{"host", func(p *Profile) { p.Host = "attacker.example.com" }},
+		{"port", func(p *Profile) { p.Port = "1522" }},
+		{"service", func(p *Profile) { p.Service = "HRPDB" }},
+		{"connection type", func(p *Profile) { p.ConnectionType = "SID" }},
+		{"connect string", func(p *Profile) { p.Host, p.Port, p.Service, p.ConnectString = "", "", "", "crm2:1521/CRMPDB" }},
+		{"protocol", func(p *Profile) { p.Protocol = "TCPS" }},
+		{"server DN", func(p *Profile) { p.Protocol, p.ServerCertDN = "TCPS", "CN=crm1" }},
+		{"username", func(p *Profile) { p.Username = "system" }},
+		{"privilege", func(p *Profile) { p.Privilege = "SYSDBA" }},
+		{"proxy client", func(p *Profile) { p.ProxyClient = "app_owner" }},
+		{"proxy client in username", func(p *Profile) { p.Username = "monitor[app_owner]" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := renamed
+			tt.change(&p)
+			if _, err := s.Update(created.ID, p); err == nil || !strings.Contains(err.Error(), "password is required") {
+				t.Fatalf("Update error = %v, want password is required", err)
+			}
+			if req, _ := s.ConnectionProfile(created.ID); req.Host != "crm1" || req.Password != "secret" {
+				t.Errorf("rejected update changed the profile: %+v", req)
+			}
+
+			p.Password = "new-secret"
+			if _, err := s.Update(created.ID, p); err != nil {
+				t.Fatalf("Update with password: %v", err)
+			}
+			if req, _ := s.ConnectionProfile(created.ID); req.Password != "new-secret" {
+				t.Errorf("password = %q, want the new one", req.Password)
+			}
+			// Restore the original connection for the next case
+			restored := renamed
+			restored.Password = "secret"
+			if _, err := s.Update(created.ID, restored); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+	"github.com/gorilla/mux"
+)
+
+// RegisterRoutes adds the schedule endpoints to an API router:
+//
+//	GET  /schedules           list every schedule with next run and last outcome
+//	GET  /schedules/{id}      a single schedule
+//	POST /schedules/{id}/run  start the schedule immediately
+func (s *Scheduler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/schedules", s.listHandler).Methods("GET")
+	r.HandleFunc("/schedules/{id}", s.getHandler).Methods("GET")
+	r.HandleFunc("/schedules/{id}/run", s.runHandler).Methods("POST")
+}
+
+func (s *Scheduler) listHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"schedules": s.List(),
+	})
+}
+
+func (s *Scheduler) getHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	status, ok := s.Get(id)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"message": fmt.Sprintf("Schedule %s not found", id),
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":  true,
+		"schedule": status,
+	})
+}
+
+func (s *Scheduler) runHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if _, ok := s.Get(id); !ok {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"message": fmt.Sprintf("Schedule %s not found", id),
+		})
+		return
+	}
+	if err := s.RunNow(id); err != nil {
+		writeJSON(w, http.StatusConflict, map[string]interface{}{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Schedule %s started", id),
+	})
+}
+
+// writeJSON encodes payload as the JSON response body.
+func writeJSON(w http.ResponseWriter, statusCode int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(payload); err != nil {
+		logger.Errorf("Failed to encode schedule response: %v", err)
+	}
+}
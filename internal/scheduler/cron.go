@@ -0,0 +1,189 @@
+// Package scheduler runs saved inspection definitions on cron schedules.
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed five-field cron expression (minute hour day-of-month month day-of-week).
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool // true when the field starts with "*", needed for the day-matching rule
+}
+
+// cronField describes the valid range and optional names of one cron field.
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}}
+	dowField = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}}
+)
+
+// cronMacros maps the common shorthand expressions to their five-field equivalents.
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses a standard cron expression such as "0 6 * * *" or "30 2 * * SUN".
+// Lists (1,15), ranges (1-5), steps (*/10, 0-30/5), month/weekday names and the
+// @daily style macros are supported. Day-of-week 7 is treated as Sunday.
+func ParseCron(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields, got %d", expr, len(fields))
+	}
+
+	s := &Schedule{}
+	var err error
+	if s.minute, _, err = parseCronField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+	if s.hour, _, err = parseCronField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+	if s.dom, s.domAny, err = parseCronField(fields[2], domField); err != nil {
+		return nil, err
+	}
+	if s.month, _, err = parseCronField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+	if s.dow, s.dowAny, err = parseCronField(fields[4], dowField); err != nil {
+		return nil, err
+	}
+	if s.dow&(1<<7) != 0 { // 7 is an alias for Sunday
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// parseCronField returns the bit set of values selected by a single field and whether it starts with "*".
+// Like Vixie cron, "*/2" counts as unrestricted for the day-matching rule, even though it selects fewer values.
+func parseCronField(field string, spec cronField) (uint64, bool, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step <= 0 {
+				return 0, false, fmt.Errorf("invalid step %q in %s field", stepPart, spec.name)
+			}
+		}
+
+		var lo, hi int
+		switch {
+		case rangePart == "*":
+			lo, hi = spec.min, spec.max
+			if spec.name == dowField.name {
+				hi = 6 // avoid selecting Sunday twice through the 7 alias
+			}
+		case strings.Contains(rangePart, "-"):
+			loStr, hiStr, _ := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = parseCronValue(loStr, spec); err != nil {
+				return 0, false, err
+			}
+			if hi, err = parseCronValue(hiStr, spec); err != nil {
+				return 0, false, err
+			}
+			if lo > hi {
+				return 0, false, fmt.Errorf("invalid range %q in %s field", rangePart, spec.name)
+			}
+		default:
+			v, err := parseCronValue(rangePart, spec)
+			if err != nil {
+				return 0, false, err
+			}
+			lo, hi = v, v
+			if hasStep {
+				hi = spec.max
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, strings.HasPrefix(field, "*"), nil
+}
+
+// parseCronValue parses a number or a name (JAN, MON, ...) and checks it against the field range.
+func parseCronValue(s string, spec cronField) (int, error) {
+	if v, ok := spec.names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q in %s field", s, spec.name)
+	}
+	if v < spec.min || v > spec.max {
+		return 0, fmt.Errorf("value %d out of range [%d-%d] in %s field", v, spec.min, spec.max, spec.name)
+	}
+	return v, nil
+}
+
+// Next returns the first time strictly after t that matches the schedule, in t's location.
+// It returns the zero time if no match exists within the next five years (e.g. "0 0 31 2 *").
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches applies the classic cron rule: when both day fields are restricted (neither starts with "*"),
+// a day matches if either of them matches; otherwise both must match.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
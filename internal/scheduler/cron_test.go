@@ -0,0 +1,150 @@
+package scheduler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// bits returns the bit set selecting values.
+func bits(values ...int) uint64 {
+	var b uint64
+	for _, v := range values {
+		b |= 1 << uint(v)
+	}
+	return b
+}
+
+// span returns the bit set selecting lo through hi.
+func span(lo, hi int) uint64 {
+	var b uint64
+	for v := lo; v <= hi; v++ {
+		b |= 1 << uint(v)
+	}
+	return b
+}
+
+func TestParseCron(t *testing.T) {
+	daily := &Schedule{minute: bits(0), hour: bits(0), dom: span(1, 31), month: span(1, 12), dow: span(0, 6), domAny: true, dowAny: true}
+	tests := []struct {
+		expr string
+		want *Schedule
+	}{
+		{"0 6 * * *", &Schedule{minute: bits(0), hour: bits(6), dom: span(1, 31), month: span(1, 12), dow: span(0, 6), domAny: true, dowAny: true}},
+		{"@daily", daily},
+		{"@midnight", daily},
+		{"  @DAILY ", daily},
+		{"@hourly", &Schedule{minute: bits(0), hour: span(0, 23), dom: span(1, 31), month: span(1, 12), dow: span(0, 6), domAny: true, dowAny: true}},
+		{"@weekly", &Schedule{minute: bits(0), hour: bits(0), dom: span(1, 31), month: span(1, 12), dow: bits(0), domAny: true}},
+		{"@monthly", &Schedule{minute: bits(0), hour: bits(0), dom: bits(1), month: span(1, 12), dow: span(0, 6), dowAny: true}},
+		{"@yearly", &Schedule{minute: bits(0), hour: bits(0), dom: bits(1), month: bits(1), dow: span(0, 6), dowAny: true}},
+		// Ranges, names and the Sunday alias
+		{"30 8-17 1-7 JAN-mar mon-FRI", &Schedule{minute: bits(30), hour: span(8, 17), dom: span(1, 7), month: span(1, 3), dow: span(1, 5)}},
+		{"0 0 * * 7", &Schedule{minute: bits(0), hour: bits(0), dom: span(1, 31), month: span(1, 12), dow: bits(0, 7), domAny: true}},
+		{"0 0 * * 5-7", &Schedule{minute: bits(0), hour: bits(0), dom: span(1, 31), month: span(1, 12), dow: bits(0, 5, 6, 7), domAny: true}},
+		// Steps over "*", ranges and single values
+		{"*/15 */6 */10 */3 */2", &Schedule{minute: bits(0, 15, 30, 45), hour: bits(0, 6, 12, 18), dom: bits(1, 11, 21, 31), month: bits(1, 4, 7, 10), dow: bits(0, 2, 4, 6), domAny: true, dowAny: true}},
+		{"0-30/10 9-17/4 * * *", &Schedule{minute: bits(0, 10, 20, 30), hour: bits(9, 13, 17), dom: span(1, 31), month: span(1, 12), dow: span(0, 6), domAny: true, dowAny: true}},
+		{"5/20 22/1 * * *", &Schedule{minute: bits(5, 25, 45), hour: bits(22, 23), dom: span(1, 31), month: span(1, 12), dow: span(0, 6), domAny: true, dowAny: true}},
+		// Lists, mixing values, ranges and steps
+		{"0,30 1-3,10-14/2 1,15,31 JUN,DEC SUN,3", &Schedule{minute: bits(0, 30), hour: bits(1, 2, 3, 10, 12, 14), dom: bits(1, 15, 31), month: bits(6, 12), dow: bits(0, 3)}},
+		{"0\t6   *\t* *", &Schedule{minute: bits(0), hour: bits(6), dom: span(1, 31), month: span(1, 12), dow: span(0, 6), domAny: true, dowAny: true}},
+	}
+	for _, tt := range tests {
+		got, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %v", tt.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseCron(%q) = %+v, want %+v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"", "must have 5 fields, got 0"},
+		{"0 6 * *", "must have 5 fields, got 4"},
+		{"0 6 * * * 2025", "must have 5 fields, got 6"},
+		{"@reboot", "must have 5 fields, got 1"},
+		{"60 * * * *", "value 60 out of range [0-59] in minute field"},
+		{"-1 * * * *", `invalid value "" in minute field`},
+		{"* 24 * * *", "value 24 out of range [0-23] in hour field"},
+		{"* * 0 * *", "value 0 out of range [1-31] in day of month field"},
+		{"* * 32 * *", "value 32 out of range [1-31] in day of month field"},
+		{"* * * 13 *", "value 13 out of range [1-12] in month field"},
+		{"* * * 0 *", "value 0 out of range [1-12] in month field"},
+		{"* * * * 8", "value 8 out of range [0-7] in day of week field"},
+		{"* 1-30 * * *", "value 30 out of range [0-23] in hour field"},
+		{"5-1 * * * *", `invalid range "5-1" in minute field`},
+		{"* * * DEC-JAN *", `invalid range "DEC-JAN" in month field`},
+		{"*/0 * * * *", `invalid step "0" in minute field`},
+		{"*/x * * * *", `invalid step "x" in minute field`},
+		{"*/ * * * *", `invalid step "" in minute field`},
+		{"a * * * *", `invalid value "a" in minute field`},
+		{"1- * * * *", `invalid value "" in minute field`},
+		{"1,,2 * * * *", `invalid value "" in minute field`},
+		{"* * * FOO *", `invalid value "FOO" in month field`},
+		{"* * * * MONDAY", `invalid value "MONDAY" in day of week field`},
+		{"* * * JAN-* *", `invalid value "*" in month field`},
+	}
+	for _, tt := range tests {
+		if _, err := ParseCron(tt.expr); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseCron(%q) error = %v, want %q", tt.expr, err, tt.want)
+		}
+	}
+}
+
+func TestDayMatches(t *testing.T) {
+	// June 2025: the 1st is a Sunday, the 2nd and 9th are Mondays.
+	day := func(d int) time.Time { return time.Date(2025, time.June, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		expr string
+		day  int
+		want bool
+	}{
+		// Day-of-week restricted, day-of-month "*": only the weekday counts
+		{"0 0 * * 1", 2, true},
+		{"0 0 * * 1", 3, false},
+		// Day-of-month restricted, day-of-week "*": only the day counts
+		{"0 0 15 * *", 15, true},
+		{"0 0 15 * *", 16, false},
+		// Both restricted: either matches
+		{"0 0 1,15 * 1", 15, true},
+		{"0 0 1,15 * 1", 2, true},
+		{"0 0 1,15 * 1", 3, false},
+		{"0 0 1-31/2 * 1", 3, true},
+		// A stepped "*" is unrestricted for the rule: every other day and Monday
+		{"0 0 */2 * 1", 9, true},
+		{"0 0 */2 * 1", 2, false},
+		{"0 0 */2 * 1", 3, false},
+		{"0 0 1 * */2", 1, true},
+		{"0 0 1 * */2", 3, false},
+	}
+	for _, tt := range tests {
+		s, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+		}
+		if got := s.dayMatches(day(tt.day)); got != tt.want {
+			t.Errorf("%q on June %d: dayMatches = %v, want %v", tt.expr, tt.day, got, tt.want)
+		}
+	}
+}
+
+func TestNextSteppedDayOfMonth(t *testing.T) {
+	s, err := ParseCron("0 0 */2 * 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)
+	want := time.Date(2025, time.June, 9, 0, 0, 0, 0, time.UTC)
+	if got := s.Next(from); !got.Equal(want) {
+		t.Errorf("Next(%s) = %s, want %s", from, got, want)
+	}
+}
@@ -0,0 +1,336 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goodwaysIT/inspect4oracle/internal/fleet"
+	"github.com/goodwaysIT/inspect4oracle/internal/handler"
+	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+)
+
+// Definition is a saved inspection that runs on a cron schedule.
+//
+// Example schedules file:
+//
+//	{
+//	  "schedules": [
+//	    {"id": "crm-storage-daily", "name": "CRM daily storage check", "cron": "0 6 * * *",
+//	     "database": {"name": "crm-prod", "business": "CRM", "host": "10.0.0.5", "port": 1521,
+//	                  "service": "CRMPDB", "username": "monitor", "credentials": "env:CRM_PROD_PWD",
+//	                  "items": ["dbinfo", "storage"], "lang": "en"}}
+//	  ]
+//	}
+type Definition struct {
+	ID       string      `json:"id"`
+	Name     string      `json:"name"`
+	Cron     string      `json:"cron"`
+	Disabled bool        `json:"disabled,omitempty"`
+	Database fleet.Entry `json:"database"` // Same format as an inventory entry
+}
+
+// Outcome records the result of one run of a definition.
+type Outcome struct {
+	StartedAt    time.Time `json:"startedAt"`
+	FinishedAt   time.Time `json:"finishedAt"`
+	Success      bool      `json:"success"`
+	ReportID     string    `json:"reportId,omitempty"`
+	ModuleErrors int       `json:"moduleErrors"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// Status is the externally visible state of a scheduled definition. It never contains credentials.
+type Status struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Cron        string     `json:"cron"`
+	Enabled     bool       `json:"enabled"`
+	Business    string     `json:"business,omitempty"`
+	Target      string     `json:"target"` // [tcps://]host:port/service, host:port:SID, or the connect string
+	Items       []string   `json:"items,omitempty"`
+	Running     bool       `json:"running"`
+	NextRun     *time.Time `json:"nextRun,omitempty"`
+	LastOutcome *Outcome   `json:"lastOutcome,omitempty"`
+}
+
+// job is the runtime state kept for each definition.
+type job struct {
+	def      Definition
+	schedule *Schedule
+	next     time.Time
+	running  bool
+	last     *Outcome
+}
+
+// validID keeps schedule IDs usable inside report IDs and URLs.
+var validID = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Scheduler runs definitions when their cron expression fires.
+type Scheduler struct {
+	mu       sync.Mutex
+	jobs     []*job
+	byID     map[string]*job
+	defaults fleet.Defaults
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// LoadDefinitions reads the schedules file.
+func LoadDefinitions(path string) ([]Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedules file %s: %w", path, err)
+	}
+	var file struct {
+		Schedules []Definition `json:"schedules"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse schedules file %s: %w", path, err)
+	}
+	return file.Schedules, nil
+}
+
+// New validates the definitions and prepares a scheduler. Call Start to begin running them.
+func New(defs []Definition) (*Scheduler, error) {
+	s := &Scheduler{
+		byID:     make(map[string]*job, len(defs)),
+		defaults: fleet.Defaults{Lang: "en"},
+		stop:     make(chan struct{}),
+	}
+	now := time.Now()
+	for _, def := range defs {
+		if def.ID == "" {
+			return nil, fmt.Errorf("schedule %q has no id", def.Name)
+		}
+		if !validID.MatchString(def.ID) {
+			return nil, fmt.Errorf("schedule id %q may only contain letters, digits, '.', '_' and '-'", def.ID)
+		}
+		if _, dup := s.byID[def.ID]; dup {
+			return nil, fmt.Errorf("schedule id %q is used more than once", def.ID)
+		}
+		schedule, err := ParseCron(def.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %s: %w", def.ID, err)
+		}
+		if def.Database.Name == "" {
+			def.Database.Name = def.ID
+		}
+		j := &job{def: def, schedule: schedule}
+		if !def.Disabled {
+			j.next = schedule.Next(now)
+		}
+		s.jobs = append(s.jobs, j)
+		s.byID[def.ID] = j
+	}
+	return s, nil
+}
+
+// Start launches the scheduling loop in the background.
+func (s *Scheduler) Start() {
+	logger.Infof("Scheduler started with %d schedule(s)", len(s.jobs))
+	go s.loop()
+}
+
+// Stop ends the scheduling loop. Inspections that are already running finish on their own.
+func (s *Scheduler) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// loop sleeps until the earliest next run, fires every due job and repeats.
+func (s *Scheduler) loop() {
+	for {
+		wait := time.Hour
+		s.mu.Lock()
+		for _, j := range s.jobs {
+			if !j.next.IsZero() {
+				if d := time.Until(j.next); d < wait {
+					wait = d
+				}
+			}
+		}
+		s.mu.Unlock()
+		if wait < 0 {
+			wait = 0
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-s.stop:
+			timer.Stop()
+			logger.Info("Scheduler stopped")
+			return
+		case now := <-timer.C:
+			s.fireDue(now)
+		}
+	}
+}
+
+// fireDue starts every job whose next run time has passed and computes its following run.
+func (s *Scheduler) fireDue(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range s.jobs {
+		if j.next.IsZero() || j.next.After(now) {
+			continue
+		}
+		j.next = j.schedule.Next(now)
+		if j.running {
+			logger.Warnf("Schedule %s is still running, skipping this occurrence", j.def.ID)
+			continue
+		}
+		j.running = true
+		go s.run(j)
+	}
+}
+
+// RunNow starts the definition immediately, outside of its cron schedule.
+func (s *Scheduler) RunNow(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.byID[id]
+	if !ok {
+		return fmt.Errorf("schedule %s not found", id)
+	}
+	if j.running {
+		return fmt.Errorf("schedule %s is already running", id)
+	}
+	j.running = true
+	go s.run(j)
+	return nil
+}
+
+// run executes one inspection for j and records its outcome.
+func (s *Scheduler) run(j *job) {
+	outcome := &Outcome{StartedAt: time.Now()}
+	defer func() {
+		if r := recover(); r != nil {
+			outcome.Success = false
+			outcome.Error = fmt.Sprintf("panic: %v", r)
+		}
+		outcome.FinishedAt = time.Now()
+		s.mu.Lock()
+		j.running = false
+		j.last = outcome
+		s.mu.Unlock()
+		if outcome.Success {
+			logger.Infof("Schedule %s finished, report ID %s", j.def.ID, outcome.ReportID)
+		} else {
+			logger.Errorf("Schedule %s failed: %s", j.def.ID, outcome.Error)
+		}
+	}()
+
+	logger.Infof("Schedule %s (%s) starting", j.def.ID, j.def.Name)
+	req, err := j.def.Database.Request(s.defaults)
+	if err != nil {
+		outcome.Error = err.Error()
+		return
+	}
+	req.ReportID = uniqueReportID(j.def.ID, outcome.StartedAt, func(id string) bool {
+		_, ok, _ := handler.Reports().Get(id)
+		return ok
+	})
+	reportData, reportID, err := handler.RunInspection(context.Background(), req)
+	if err != nil {
+		outcome.Error = err.Error()
+		handler.NotifyInspectionFailed(handler.NewInspectionFailure(req, err))
+		return
+	}
+
+	for _, module := range reportData.Modules {
+		if module.Error != "" {
+			outcome.ModuleErrors++
+		}
+	}
+	if err := handler.StoreReport(reportID, reportData); err != nil {
+		outcome.Error = err.Error()
+		return
+	}
+	outcome.ReportID = reportID
+	outcome.Success = true
+	handler.NotifyReportFinished(reportID, reportData)
+}
+
+// ReportID returns the predictable report ID used for a run of the schedule started at t,
+// e.g. "crm-storage-daily-20250601-060000".
+func ReportID(scheduleID string, t time.Time) string {
+	return fmt.Sprintf("%s-%s", scheduleID, t.Format("20060102-150405"))
+}
+
+// uniqueReportID returns ReportID, with "-2", "-3", ... appended while exists reports that a run
+// started in the same second already stored a report under the ID.
+func uniqueReportID(scheduleID string, t time.Time, exists func(id string) bool) string {
+	base := ReportID(scheduleID, t)
+	id := base
+	for n := 2; exists(id); n++ {
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+	return id
+}
+
+// List returns the status of every definition, ordered by ID.
+func (s *Scheduler) List() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]Status, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		statuses = append(statuses, j.status())
+	}
+	sort.Slice(statuses, func(a, b int) bool { return statuses[a].ID < statuses[b].ID })
+	return statuses
+}
+
+// Get returns the status of a single definition.
+func (s *Scheduler) Get(id string) (Status, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.byID[id]
+	if !ok {
+		return Status{}, false
+	}
+	return j.status(), true
+}
+
+// status must be called with the scheduler lock held.
+func (j *job) status() Status {
+	db := j.def.Database
+	port := db.Port
+	if port == 0 {
+		port = 1521
+	}
+	target := fmt.Sprintf("%s:%d/%s", db.Host, port, db.Service)
+	if strings.EqualFold(db.ConnectionType, "SID") {
+		target = fmt.Sprintf("%s:%d:%s", db.Host, port, db.Service)
+	}
+	if strings.EqualFold(db.Protocol, "TCPS") {
+		target = "tcps://" + target
+	}
+	if db.ConnectString != "" {
+		target = db.ConnectString
+	}
+	st := Status{
+		ID:       j.def.ID,
+		Name:     j.def.Name,
+		Cron:     j.def.Cron,
+		Enabled:  !j.def.Disabled,
+		Business: db.Business,
+		Target:   target,
+		Items:    db.Items,
+		Running:  j.running,
+	}
+	if !j.next.IsZero() {
+		next := j.next
+		st.NextRun = &next
+	}
+	if j.last != nil {
+		last := *j.last
+		st.LastOutcome = &last
+	}
+	return st
+}
@@ -0,0 +1,37 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUniqueReportID(t *testing.T) {
+	start := time.Date(2025, time.June, 1, 6, 0, 5, 900, time.UTC)
+	if got, want := ReportID("crm-daily", start), "crm-daily-20250601-060005"; got != want {
+		t.Errorf("ReportID = %q, want %q", got, want)
+	}
+	// Runs a second apart, e.g. a manual run right after a scheduled one, no longer share an ID
+	if ReportID("crm-daily", start) == ReportID("crm-daily", start.Add(time.Second)) {
+		t.Error("runs one second apart share a report ID")
+	}
+
+	tests := []struct {
+		name   string
+		stored []string
+		want   string
+	}{
+		{"first run", nil, "crm-daily-20250601-060005"},
+		{"same second", []string{"crm-daily-20250601-060005"}, "crm-daily-20250601-060005-2"},
+		{"third run", []string{"crm-daily-20250601-060005", "crm-daily-20250601-060005-2"}, "crm-daily-20250601-060005-3"},
+		{"other schedule", []string{"crm-weekly-20250601-060005"}, "crm-daily-20250601-060005"},
+	}
+	for _, tt := range tests {
+		stored := map[string]bool{}
+		for _, id := range tt.stored {
+			stored[id] = true
+		}
+		if got := uniqueReportID("crm-daily", start, func(id string) bool { return stored[id] }); got != tt.want {
+			t.Errorf("%s: uniqueReportID = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+// Package xlsx writes simple Office Open XML workbooks: text and number cells, a bold header
+// row that stays visible while scrolling, and column widths fitted to the content.
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits imposed by Excel.
+const (
+	maxSheetName = 31    // Characters in a sheet name
+	maxCellText  = 32767 // Characters in a cell
+)
+
+// Workbook is a workbook under construction.
+type Workbook struct {
+	sheets []*Sheet
+	names  map[string]bool // Lower-cased sheet names, which Excel compares case-insensitively
+}
+
+// Sheet is one worksheet. The first row is formatted as a header when Header is set.
+type Sheet struct {
+	Name   string
+	Header bool
+	Rows   [][]string
+}
+
+// New creates an empty workbook.
+func New() *Workbook {
+	return &Workbook{names: map[string]bool{}}
+}
+
+// invalidSheetChars are the characters Excel does not allow in sheet names.
+var invalidSheetChars = regexp.MustCompile(`[\[\]:*?/\\]`)
+
+// AddSheet appends a sheet. The name is cleaned up and shortened to what Excel accepts and made
+// unique within the workbook; the final name is available from the returned Sheet.
+func (wb *Workbook) AddSheet(name string, header bool, rows [][]string) *Sheet {
+	name = strings.TrimSpace(invalidSheetChars.ReplaceAllString(name, " "))
+	name = strings.Trim(name, "'")
+	if name == "" {
+		name = "Sheet"
+	}
+	unique := truncateRunes(name, maxSheetName)
+	for i := 2; wb.names[strings.ToLower(unique)]; i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		unique = strings.TrimSpace(truncateRunes(name, maxSheetName-len(suffix))) + suffix
+	}
+	wb.names[strings.ToLower(unique)] = true
+
+	sheet := &Sheet{Name: unique, Header: header, Rows: rows}
+	wb.sheets = append(wb.sheets, sheet)
+	return sheet
+}
+
+// WriteTo writes the workbook as an .xlsx file.
+func (wb *Workbook) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	zw := zip.NewWriter(cw)
+	parts := []part{
+		{"[Content_Types].xml", wb.writeContentTypes},
+		{"_rels/.rels", writeString(rootRels)},
+		{"xl/workbook.xml", wb.writeWorkbook},
+		{"xl/_rels/workbook.xml.rels", wb.writeWorkbookRels},
+		{"xl/styles.xml", writeString(styles)},
+	}
+	for i, sheet := range wb.sheets {
+		parts = append(parts, part{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), sheet.write})
+	}
+
+	for _, p := range parts {
+		fw, err := zw.Create(p.name)
+		if err != nil {
+			return cw.n, err
+		}
+		if err := p.write(fw); err != nil {
+			return cw.n, err
+		}
+	}
+	err := zw.Close()
+	return cw.n, err
+}
+
+// part is one file inside the .xlsx package.
+type part struct {
+	name  string
+	write func(io.Writer) error
+}
+
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+const rootRels = xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+// styles defines cell format 0 (default) and 1 (bold header with a grey fill).
+const styles = xmlHeader + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>` +
+	`<fill><patternFill patternType="solid"><fgColor rgb="FFE9ECEF"/><bgColor indexed="64"/></patternFill></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="2" borderId="0" xfId="0" applyFont="1" applyFill="1"/></cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
+
+func writeString(s string) func(io.Writer) error {
+	return func(w io.Writer) error {
+		_, err := io.WriteString(w, s)
+		return err
+	}
+}
+
+func (wb *Workbook) writeContentTypes(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := range wb.sheets {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	b.WriteString(`</Types>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (wb *Workbook) writeWorkbook(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, sheet := range wb.sheets {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(sheet.Name), i+1, i+1)
+	}
+	b.WriteString(`</sheets>`)
+	// Excel expects every sheet with an autofilter to have a matching hidden defined name.
+	var names strings.Builder
+	for i, sheet := range wb.sheets {
+		if ref := sheet.filterRange(); ref != "" {
+			fmt.Fprintf(&names, `<definedName name="_xlnm._FilterDatabase" localSheetId="%d" hidden="1">%s</definedName>`,
+				i, escape("'"+strings.ReplaceAll(sheet.Name, "'", "''")+"'!"+ref))
+		}
+	}
+	if names.Len() > 0 {
+		b.WriteString(`<definedNames>` + names.String() + `</definedNames>`)
+	}
+	b.WriteString(`</workbook>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (wb *Workbook) writeWorkbookRels(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := range wb.sheets {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(wb.sheets)+1)
+	b.WriteString(`</Relationships>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (s *Sheet) write(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	if s.Header && len(s.Rows) > 0 {
+		b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	}
+
+	widths := s.columnWidths()
+	if len(widths) > 0 {
+		b.WriteString(`<cols>`)
+		for i, width := range widths {
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, width)
+		}
+		b.WriteString(`</cols>`)
+	}
+
+	b.WriteString(`<sheetData>`)
+	for r, row := range s.Rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		header := s.Header && r == 0
+		for c, value := range row {
+			if value == "" {
+				continue
+			}
+			value = truncateRunes(value, maxCellText)
+			ref := CellRef(c, r)
+			switch {
+			case header:
+				fmt.Fprintf(&b, `<c r="%s" s="1" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, escape(value))
+			case isNumber(value):
+				fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, value)
+			default:
+				fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, escape(value))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData>`)
+	if ref := s.filterRange(); ref != "" {
+		fmt.Fprintf(&b, `<autoFilter ref="%s"/>`, strings.ReplaceAll(ref, "$", ""))
+	}
+	b.WriteString(`</worksheet>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// filterRange returns the absolute range covered by the autofilter, or "" for sheets without a header.
+func (s *Sheet) filterRange() string {
+	columns := 0
+	for _, row := range s.Rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	if !s.Header || len(s.Rows) < 2 || columns == 0 {
+		return ""
+	}
+	last := CellRef(columns-1, len(s.Rows)-1)
+	split := strings.IndexAny(last, "0123456789")
+	return "$A$1:$" + last[:split] + "$" + last[split:]
+}
+
+// columnWidths estimates a width in characters for every column, between 8 and 60.
+func (s *Sheet) columnWidths() []int {
+	var widths []int
+	for _, row := range s.Rows {
+		for c, value := range row {
+			for len(widths) <= c {
+				widths = append(widths, 8)
+			}
+			width := 2
+			for _, r := range value {
+				if r > 0x2e80 { // CJK characters take two columns
+					width += 2
+				} else {
+					width++
+				}
+			}
+			if width > widths[c] {
+				widths[c] = width
+			}
+		}
+	}
+	for i := range widths {
+		if widths[i] > 60 {
+			widths[i] = 60
+		}
+	}
+	return widths
+}
+
+// CellRef converts zero-based column and row numbers into an A1-style reference.
+func CellRef(col, row int) string {
+	name := ""
+	for col >= 0 {
+		name = string(rune('A'+col%26)) + name
+		col = col/26 - 1
+	}
+	return name + strconv.Itoa(row+1)
+}
+
+// numberPattern matches plain decimal numbers. Values with leading zeros, such as IDs, stay text.
+var numberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]{0,14})(\.[0-9]{1,10})?$`)
+
+func isNumber(s string) bool {
+	return numberPattern.MatchString(s)
+}
+
+// escape escapes s for XML and drops the control characters XML cannot represent.
+func escape(s string) string {
+	if !utf8.ValidString(s) {
+		s = strings.ToValidUTF8(s, "�")
+	}
+	s = strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			return -1
+		}
+		return r
+	}, s)
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// truncateRunes shortens s to at most n characters.
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,286 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestCellRef(t *testing.T) {
+	tests := []struct {
+		col, row int
+		want     string
+	}{
+		{0, 0, "A1"},
+		{25, 9, "Z10"},
+		{26, 0, "AA1"},
+		{51, 0, "AZ1"},
+		{52, 0, "BA1"},
+		{701, 0, "ZZ1"},
+		{702, 0, "AAA1"},
+		{16383, 1048575, "XFD1048576"}, // The last cell of a worksheet
+	}
+	for _, tt := range tests {
+		if got := CellRef(tt.col, tt.row); got != tt.want {
+			t.Errorf("CellRef(%d, %d) = %q, want %q", tt.col, tt.row, got, tt.want)
+		}
+	}
+}
+
+func TestAddSheetNames(t *testing.T) {
+	long := strings.Repeat("x", 40)
+	cjk := strings.Repeat("表", 35)
+	tests := []struct {
+		name  string
+		added []string
+		want  []string
+	}{
+		{"invalid characters", []string{`a/b\c?d*e[f]g:h`}, []string{"a b c d e f g h"}},
+		{"leading and trailing apostrophes", []string{"'Sessions'"}, []string{"Sessions"}},
+		{"nothing left", []string{"[]", "  ", "''"}, []string{"Sheet", "Sheet (2)", "Sheet (3)"}},
+		{"case-insensitive duplicates", []string{"Users", "USERS", "users"}, []string{"Users", "USERS (2)", "users (3)"}},
+		{"31 characters", []string{long}, []string{long[:31]}},
+		{"truncated duplicates", []string{long, long, long + "y"}, []string{long[:31], long[:27] + " (2)", long[:27] + " (3)"}},
+		{"duplicate after truncation", []string{long[:31], long[:35]}, []string{long[:31], long[:27] + " (2)"}},
+		{"suffix already taken", []string{"Data (2)", "Data", "Data"}, []string{"Data (2)", "Data", "Data (3)"}},
+		{"characters, not bytes", []string{cjk, cjk}, []string{cjk[:31*3], cjk[:27*3] + " (2)"}},
+		{"no space before the suffix", []string{long[:26] + " abcd", long[:26] + " abcd"}, []string{long[:26] + " abcd", long[:26] + " (2)"}},
+	}
+	for _, tt := range tests {
+		wb := New()
+		var got []string
+		for _, name := range tt.added {
+			got = append(got, wb.AddSheet(name, false, nil).Name)
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: names = %q, want %q", tt.name, got, tt.want)
+		}
+		for _, name := range got {
+			if n := utf8.RuneCountInString(name); n > maxSheetName {
+				t.Errorf("%s: %q has %d characters", tt.name, name, n)
+			}
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{`<a href="x">&'`, "&lt;a href=&#34;x&#34;&gt;&amp;&#39;"},
+		{"tab\tnew\nline\r", "tab&#x9;new&#xA;line&#xD;"},
+		{"nul\x00bell\x07esc\x1b", "nulbellesc"},
+		{"del\x7f", "del\x7f"},
+		{"bad \xff byte", "bad � byte"},
+		{"non-character ￾", "non-character �"},
+		{"数据库", "数据库"},
+	}
+	for _, tt := range tests {
+		if got := escape(tt.in); got != tt.want {
+			t.Errorf("escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestIsNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"0", true},
+		{"42", true},
+		{"-17", true},
+		{"3.14", true},
+		{"-0.5", true},
+		{"123456789012345", true},
+		{"1234567890123456", false}, // Beyond the 15 digits Excel keeps
+		{"007", false},              // Leading zeros, such as IDs
+		{"1.", false},
+		{".5", false},
+		{"1e5", false},
+		{"+1", false},
+		{" 1", false},
+		{"1,000", false},
+		{"85%", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isNumber(tt.in); got != tt.want {
+			t.Errorf("isNumber(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSheetCells(t *testing.T) {
+	s := &Sheet{Name: "Data", Header: true, Rows: [][]string{
+		{"Name", "Size"},
+		{"USERS", "1024"},
+		{"007", "", "a<b\x01"},
+	}}
+	var b strings.Builder
+	if err := s.write(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>`,
+		`<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">Name</t></is></c>`,
+		`<c r="B2"><v>1024</v></c>`,
+		`<c r="A3" t="inlineStr"><is><t xml:space="preserve">007</t></is></c>`,
+		`<c r="C3" t="inlineStr"><is><t xml:space="preserve">a&lt;b</t></is></c>`,
+		`<autoFilter ref="A1:C3"/>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("sheet XML does not contain %s", want)
+		}
+	}
+	if strings.Contains(out, `r="B3"`) {
+		t.Errorf("empty cell B3 written")
+	}
+}
+
+// Structures of the package parts the consistency test reads.
+type (
+	xlsxWorkbook struct {
+		Sheets []struct {
+			Name    string `xml:"name,attr"`
+			SheetID string `xml:"sheetId,attr"`
+			RID     string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+		DefinedNames []struct {
+			LocalSheetID int    `xml:"localSheetId,attr"`
+			Value        string `xml:",chardata"`
+		} `xml:"definedNames>definedName"`
+	}
+	xlsxRelationships struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Type   string `xml:"Type,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	xlsxContentTypes struct {
+		Overrides []struct {
+			PartName string `xml:"PartName,attr"`
+		} `xml:"Override"`
+	}
+	xlsxWorksheet struct {
+		AutoFilter struct {
+			Ref string `xml:"ref,attr"`
+		} `xml:"autoFilter"`
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"sheetData>row>c"`
+	}
+)
+
+func TestWorkbookPackage(t *testing.T) {
+	wb := New()
+	wb.AddSheet("Tablespaces", true, [][]string{{"Name", "Used %"}, {"USERS", "85.5"}, {"TEMP", "12"}})
+	wb.AddSheet("O'Brien's [sessions]", true, [][]string{{"SID", "Program"}, {"17", "sqlplus@db1 <TNS>"}})
+	wb.AddSheet("tablespaces", false, [][]string{{"参数", "值"}})
+	var buf bytes.Buffer
+	n, err := wb.WriteTo(&buf)
+	if err != nil || n != int64(buf.Len()) {
+		t.Fatalf("WriteTo = %d, %v; wrote %d bytes", n, err, buf.Len())
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = data
+	}
+	decode := func(name string, v interface{}) {
+		t.Helper()
+		data, ok := files[name]
+		if !ok {
+			t.Fatalf("%s is missing from the package", name)
+		}
+		if err := xml.Unmarshal(data, v); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+
+	var workbook xlsxWorkbook
+	var rels xlsxRelationships
+	var types xlsxContentTypes
+	var rootRels xlsxRelationships
+	decode("xl/workbook.xml", &workbook)
+	decode("xl/_rels/workbook.xml.rels", &rels)
+	decode("[Content_Types].xml", &types)
+	decode("_rels/.rels", &rootRels)
+
+	if len(rootRels.Relationships) != 1 || files[rootRels.Relationships[0].Target] == nil {
+		t.Errorf("root relationships = %+v", rootRels.Relationships)
+	}
+	overrides := map[string]bool{}
+	for _, o := range types.Overrides {
+		overrides[o.PartName] = true
+	}
+	targets := map[string]string{}
+	for _, r := range rels.Relationships {
+		targets[r.ID] = "xl/" + r.Target
+		if files["xl/"+r.Target] == nil {
+			t.Errorf("relationship %s points at missing part %s", r.ID, r.Target)
+		}
+		if !overrides["/xl/"+r.Target] {
+			t.Errorf("no content type for %s", r.Target)
+		}
+	}
+
+	wantNames := []string{"Tablespaces", "O'Brien's  sessions", "tablespaces (2)"}
+	if len(workbook.Sheets) != len(wantNames) || len(rels.Relationships) != len(wantNames)+1 {
+		t.Fatalf("%d sheets and %d relationships, want %d and %d", len(workbook.Sheets), len(rels.Relationships), len(wantNames), len(wantNames)+1)
+	}
+	wantFilters := []string{"A1:B3", "A1:B2", ""}
+	for i, sheet := range workbook.Sheets {
+		if sheet.Name != wantNames[i] {
+			t.Errorf("sheet %d is named %q, want %q", i+1, sheet.Name, wantNames[i])
+		}
+		part, ok := targets[sheet.RID]
+		if !ok {
+			t.Errorf("sheet %q refers to unknown relationship %q", sheet.Name, sheet.RID)
+			continue
+		}
+		var ws xlsxWorksheet
+		decode(part, &ws)
+		if ws.AutoFilter.Ref != wantFilters[i] {
+			t.Errorf("sheet %q autofilter = %q, want %q", sheet.Name, ws.AutoFilter.Ref, wantFilters[i])
+		}
+	}
+
+	// Every autofilter needs a hidden defined name quoting the sheet name.
+	if len(workbook.DefinedNames) != 2 {
+		t.Fatalf("defined names = %+v", workbook.DefinedNames)
+	}
+	for i, want := range []string{"'Tablespaces'!$A$1:$B$3", "'O''Brien''s  sessions'!$A$1:$B$2"} {
+		if dn := workbook.DefinedNames[i]; dn.LocalSheetID != i || dn.Value != want {
+			t.Errorf("defined name %d = %+v, want %q", i, dn, want)
+		}
+	}
+
+	var sessions xlsxWorksheet
+	decode(targets[workbook.Sheets[1].RID], &sessions)
+	if c := sessions.Cells; len(c) != 4 || c[2].Ref != "A2" || c[2].Value != "17" || c[3].Inline != "sqlplus@db1 <TNS>" {
+		t.Errorf("session cells = %+v", c)
+	}
+}
@@ -8,9 +8,13 @@ import (
 	"log"
 	"net/http"
 	"os" // Required for flag.Usage (os.Stderr, os.Args) and os.Exit
+	"time"
 
+	"github.com/goodwaysIT/inspect4oracle/internal/db"
 	"github.com/goodwaysIT/inspect4oracle/internal/handler"
 	"github.com/goodwaysIT/inspect4oracle/internal/logger"
+	"github.com/goodwaysIT/inspect4oracle/internal/profiles"
+	"github.com/goodwaysIT/inspect4oracle/internal/scheduler"
 
 	"github.com/gorilla/mux"
 )
@@ -21,10 +25,40 @@ var content embed.FS
 const AppVersion = "0.1.0" // Application version constant
 
 func main() {
+	handler.GeneratorVersion = AppVersion
+
+	// Headless subcommands run without starting the web server.
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "inspect":
+			os.Exit(runInspectCommand(os.Args[2:]))
+		case "fleet":
+			os.Exit(runFleetCommand(os.Args[2:]))
+		case "render":
+			os.Exit(runRenderCommand(os.Args[2:]))
+		case "notify":
+			os.Exit(runNotifyCommand(os.Args[2:]))
+		case "grants":
+			os.Exit(runGrantsCommand(os.Args[2:]))
+		}
+	}
+
 	host := flag.String("host", "0.0.0.0", "IP address")
 	port := flag.String("port", "8080", "Port")
 	debug := flag.Bool("debug", false, "Debug mode")
 	showVersion := flag.Bool("version", false, "Print version information and exit")
+	reportDir := flag.String("report-dir", "", "Directory for persisted reports (JSON per report); reports are kept in memory when empty")
+	reportMaxAge := flag.Duration("report-max-age", 0, "Delete reports older than this, e.g. 720h (0 = keep forever)")
+	reportMaxPerDB := flag.Int("report-max-per-db", 0, "Keep at most this many reports per database (0 = unlimited)")
+	schedulesFile := flag.String("schedules", "", "Schedules file (JSON) with recurring inspections to run in the background")
+	tnsnamesFile := flag.String("tnsnames", "", "tnsnames.ora used to resolve TNS aliases (defaults to $TNS_ADMIN/tnsnames.ora)")
+	thresholdsFile := flag.String("thresholds", "", "Thresholds file (JSON) with findings threshold profiles, e.g. prod and test")
+	profilesFile := flag.String("profiles", "", "Encrypted connection profiles file; enables saved profiles on the index page and /api/profiles")
+	profileKey := flag.String("profile-key", profiles.DefaultKeyRef, "Master key for the profiles file: env:VAR or file:PATH")
+	notifyConfig := flag.String("notify-config", "", "Notifications file (JSON), e.g. SMTP recipients and webhooks for inspection outcomes")
+	queryTimeout := flag.Duration("query-timeout", db.DefaultQueryTimeout, "Cancel a single query after this long and record it as a module error (0 = no limit)")
+	moduleTimeout := flag.Duration("module-timeout", handler.DefaultModuleTimeout, "Cancel the remaining queries of an inspection module after this long (0 = no limit)")
+	excludeOwnSessions := flag.Bool("exclude-own-sessions", true, "Leave the tool's own sessions (MODULE inspect4oracle) out of the session overview, wait events and ASH history")
 
 	// Custom usage message for -h/--help
 	flag.Usage = func() {
@@ -32,8 +66,20 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Version: %s\n\n", AppVersion)
 		fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
 		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nSubcommands:\n")
+		fmt.Fprintf(os.Stderr, "  inspect    Run a single inspection and write the report to a file (see '%s inspect -h')\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  fleet      Inspect every database listed in an inventory file (see '%s fleet -h')\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  render     Render a JSON report file in another format (see '%s render -h')\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  notify     Send the notifications for a JSON report file (see '%s notify -h')\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  grants     Write the GRANT script for a least-privilege monitoring user (see '%s grants -h')\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "\nExample:\n")
 		fmt.Fprintf(os.Stderr, "  %s -host 127.0.0.1 -port 9090 -debug\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -report-dir /var/lib/inspect4oracle/reports -report-max-age 2160h -report-max-per-db 30\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -schedules schedules.json\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -thresholds thresholds.json\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -notify-config notify.json\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -query-timeout 5m -module-timeout 20m\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -profiles /var/lib/inspect4oracle/profiles.json -profile-key file:/etc/inspect4oracle/profile.key\n", os.Args[0])
 	}
 
 	flag.Parse()
@@ -46,6 +92,77 @@ func main() {
 	// 初始化日志系统，防止 logger.Error 等为 nil 导致 panic
 	logger.Init(*debug)
 
+	// 报告存储：指定 -report-dir 时持久化到磁盘，重启后可继续访问
+	retention := handler.RetentionPolicy{MaxAge: *reportMaxAge, MaxPerDatabase: *reportMaxPerDB}
+	if *reportDir != "" {
+		repo, err := handler.NewFileReportRepository(*reportDir, retention)
+		if err != nil {
+			logger.Fatalf("Failed to open report directory: %v", err)
+		}
+		handler.SetReportRepository(repo)
+	} else {
+		handler.SetReportRepository(handler.NewMemoryReportRepository(retention))
+	}
+	go func() {
+		// Age-based expiry must also happen when no new reports arrive
+		for range time.Tick(time.Hour) {
+			if _, err := handler.Reports().Prune(); err != nil {
+				logger.Errorf("Report retention failed: %v", err)
+			}
+		}
+	}()
+
+	// TNS 别名解析：未指定 -tnsnames 时使用 $TNS_ADMIN 或 $ORACLE_HOME 下的 tnsnames.ora
+	db.SetTNSNamesFile(*tnsnamesFile)
+
+	// 查询超时：单条查询与单个巡检模块的最长执行时间，超时记为模块错误而不是一直等待
+	db.SetQueryTimeout(*queryTimeout)
+	handler.SetModuleTimeout(*moduleTimeout)
+
+	// 本工具的会话带有 MODULE=inspect4oracle，默认不计入会话统计
+	db.SetExcludeOwnSessions(*excludeOwnSessions)
+
+	// 巡检发现阈值：未指定 -thresholds 时只有内置的 default 配置
+	if *thresholdsFile != "" {
+		if err := handler.LoadThresholdProfiles(*thresholdsFile); err != nil {
+			logger.Fatalf("Failed to load thresholds: %v", err)
+		}
+	}
+
+	// 巡检完成通知：未指定 -notify-config 时不发送
+	if *notifyConfig != "" {
+		if err := setupNotifications(*notifyConfig); err != nil {
+			logger.Fatalf("Failed to load notifications: %v", err)
+		}
+	}
+
+	// 连接配置：未指定 -profiles 时 /api/profiles 返回未启用，请求必须携带密码
+	var profileStore *profiles.Store
+	if *profilesFile != "" {
+		var err error
+		profileStore, err = profiles.Open(*profilesFile, *profileKey)
+		if err != nil {
+			logger.Fatalf("Failed to open connection profiles: %v", err)
+		}
+		handler.SetConnectionProfileStore(profileStore)
+	}
+
+	// 定时巡检：未指定 -schedules 时使用空调度器，/api/schedules 仍然可用
+	var scheduleDefs []scheduler.Definition
+	if *schedulesFile != "" {
+		var err error
+		scheduleDefs, err = scheduler.LoadDefinitions(*schedulesFile)
+		if err != nil {
+			logger.Fatalf("Failed to load schedules: %v", err)
+		}
+	}
+	sched, err := scheduler.New(scheduleDefs)
+	if err != nil {
+		logger.Fatalf("Invalid schedules: %v", err)
+	}
+	sched.Start()
+	defer sched.Stop()
+
 	r := mux.NewRouter()
 
 	// Static file serving
@@ -66,6 +183,8 @@ func main() {
 
 	// 报告页面路由
 	r.HandleFunc("/report.html", handler.ViewReportHandler(content)).Methods("GET")
+	r.HandleFunc("/history.html", handler.HistoryHandler(content)).Methods("GET")
+	r.HandleFunc("/diff.html", handler.DiffPageHandler(content)).Methods("GET")
 
 	// Create a subrouter for the API
 	apiRouter := r.PathPrefix("/api").Subrouter()
@@ -90,6 +209,7 @@ func main() {
 
 	// 注册 API 路由
 	apiRouter.HandleFunc("/validate", handler.ValidateConnection).Methods("POST")
+	apiRouter.HandleFunc("/grants", handler.GrantScriptHandler()).Methods("GET")
 	apiRouter.HandleFunc("/inspect", func(w http.ResponseWriter, r *http.Request) {
 		logger.Info("Handling /api/inspect request")
 		handler.InspectHandler(*debug)(w, r)
@@ -97,6 +217,14 @@ func main() {
 	// Remove conflicting /api/report route as its functionality is ambiguous and overlaps with /report.html
 	// apiRouter.HandleFunc("/report", handler.ViewReportHandler(content)).Methods("GET")
 	apiRouter.HandleFunc("/report/status", handler.GetReportStatusHandler()).Methods("GET") // Use the new GetReportStatusHandler to return JSON
+	apiRouter.HandleFunc("/reports", handler.ListReportsHandler()).Methods("GET")
+	apiRouter.HandleFunc("/reports/import", handler.ImportReportHandler()).Methods("POST")
+	apiRouter.HandleFunc("/report/diff", handler.ReportDiffHandler()).Methods("GET")
+	apiRouter.HandleFunc("/report/export", handler.ExportReportHandler(content)).Methods("GET")
+	apiRouter.HandleFunc("/thresholds", handler.ThresholdProfilesHandler()).Methods("GET")
+	apiRouter.HandleFunc("/tnsnames", handler.TNSAliasesHandler()).Methods("GET")
+	sched.RegisterRoutes(apiRouter)
+	profileStore.RegisterRoutes(apiRouter)
 
 	// Logging middleware for the main router
 	r.Use(func(next http.Handler) http.Handler {
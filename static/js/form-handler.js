@@ -60,6 +60,16 @@ const formHandler = {
         // 初始化折叠面板
         this.initCollapsePanels();
         
+        // 加载阈值配置列表
+        this.loadThresholdProfiles();
+        
+        // 连接方式：服务名/SID 或连接串（EZConnect、描述符、TNS 别名）
+        this.initConnectMode();
+        this.initTLSOptions();
+        
+        // 已保存的连接配置（启动时指定 -profiles 才显示）
+        this.initProfiles();
+        
         // 初始化验证按钮
         const validateBtn = document.getElementById('validateBtn');
         if (validateBtn) {
@@ -83,6 +93,7 @@ const formHandler = {
             
             // 验证数据库连接
             const validation = await this.validateConnection(formData);
+            this.showModuleReadiness(validation.modules, formData);
             
             if (validation.success) {
                 this.showMessage('Database connection validation successful!', 'success');
@@ -173,8 +184,13 @@ const formHandler = {
                 formObj[key] = value;
             });
             
-            // 检查必填字段
-            const requiredFields = ['host', 'port', 'service', 'username', 'password'];
+            // 检查必填字段；使用已保存的连接时由服务器补全
+            let requiredFields = formObj.connectionType === ''
+                ? ['connectString', 'username', 'password']
+                : ['host', 'port', 'service', 'username', 'password'];
+            if (formObj.profileId) {
+                requiredFields = [];
+            }
             const missingFields = requiredFields.filter(field => !formObj[field]);
             
             if (missingFields.length > 0) {
@@ -193,8 +209,17 @@ const formHandler = {
                     host: formObj.host,
                     port: formObj.port,
                     service: formObj.service,
+                    connectionType: formObj.connectionType,
+                    connectString: formObj.connectString,
+                    protocol: formObj.protocol,
+                    walletDir: formObj.walletDir,
+                    serverCertDn: formObj.serverCertDn,
+                    privilege: formObj.privilege,
+                    profileId: formObj.profileId,
                     username: formObj.username,
-                    password: formObj.password
+                    password: formObj.password,
+                    items: formData.getAll('items'),
+                    lang: document.getElementById('lang')?.value || 'zh'
                 })
             });
             
@@ -203,11 +228,12 @@ const formHandler = {
             if (!response.ok || !result.success) {
                 return {
                     success: false,
-                    message: result.message || 'Validation failed: unknown error'
+                    message: result.message || 'Validation failed: unknown error',
+                    modules: result.modules
                 };
             }
             
-            return { success: true };
+            return { success: true, modules: result.modules };
             
         } catch (error) {
             // console.error('Error validating connection:', error);
@@ -218,6 +244,45 @@ const formHandler = {
         }
     },
     
+    // 显示所选巡检项的权限情况，缺少视图时提供最小权限授权脚本
+    showModuleReadiness(modules, formData) {
+        const container = document.getElementById('module-readiness');
+        const list = document.getElementById('module-readiness-list');
+        const link = document.getElementById('grantScriptLink');
+        if (!container || !list || !link) return;
+        list.innerHTML = '';
+        if (!Array.isArray(modules) || modules.length === 0) {
+            container.classList.add('d-none');
+            return;
+        }
+
+        const lang = document.getElementById('lang')?.value || 'zh';
+        const text = (key, fallback) => (window.langMap && window.langMap[lang]?.[key]) || fallback;
+        let missing = false;
+        modules.forEach(module => {
+            const partial = module.ready && (module.missingOptionalViews || []).length > 0;
+            const views = (module.missingViews || []).concat(module.missingOptionalViews || []);
+            missing = missing || views.length > 0;
+
+            const item = document.createElement('li');
+            const badge = document.createElement('span');
+            badge.className = `badge me-1 ${!module.ready ? 'bg-danger' : partial ? 'bg-warning text-dark' : 'bg-success'}`;
+            badge.textContent = !module.ready ? text('module_not_ready', 'Missing privileges')
+                : partial ? text('module_partial', 'Partially available') : text('module_ready', 'Ready');
+            item.appendChild(badge);
+            item.appendChild(document.createTextNode(module.name + (views.length ? `: ${views.join(', ')}` : '')));
+            list.appendChild(item);
+        });
+
+        // 代理登录 proxy[client] 时服务器会授权给客户端用户
+        const user = formData.get('username') || '';
+        const params = new URLSearchParams({ user });
+        formData.getAll('items').forEach(item => params.append('items', item));
+        link.href = `/api/grants?${params.toString()}`;
+        link.classList.toggle('d-none', !missing || !user);
+        container.classList.remove('d-none');
+    },
+    
     // Submit inspection request
     async submitInspection(formData) {
         try {
@@ -450,6 +515,198 @@ const formHandler = {
 
     },
     
+    // 选择"连接串"时隐藏主机/端口/服务名，并从 /api/tnsnames 加载 TNS 别名供输入提示
+    initConnectMode() {
+        const select = document.getElementById('connectionType');
+        const group = document.getElementById('connect-string-group');
+        if (!select || !group) return;
+        const update = () => {
+            const byConnectString = select.value === '';
+            document.querySelectorAll('.direct-connect').forEach(col => {
+                col.classList.toggle('d-none', byConnectString);
+                col.querySelectorAll('input').forEach(input => { input.disabled = byConnectString; });
+            });
+            group.classList.toggle('d-none', !byConnectString);
+            document.getElementById('connectString').disabled = !byConnectString;
+        };
+        select.addEventListener('change', update);
+        update();
+
+        fetch('/api/tnsnames')
+            .then(response => response.ok ? response.json() : null)
+            .then(result => {
+                const list = document.getElementById('tns-aliases');
+                (result?.aliases || []).forEach(alias => {
+                    const option = document.createElement('option');
+                    option.value = alias;
+                    list.appendChild(option);
+                });
+            })
+            .catch(error => console.error('Failed to load TNS aliases:', error));
+    },
+    
+    // 选择 TCPS 时显示 Wallet 目录和服务器证书 DN
+    initTLSOptions() {
+        const select = document.getElementById('protocol');
+        if (!select) return;
+        const update = () => {
+            const tcps = select.value === 'TCPS';
+            document.querySelectorAll('.tls-option').forEach(col => {
+                col.classList.toggle('d-none', !tcps);
+                col.querySelectorAll('input').forEach(input => { input.disabled = !tcps; });
+            });
+        };
+        select.addEventListener('change', update);
+        update();
+    },
+    
+    // 已保存的连接：选择后填入表单，密码留在服务器端；修改连接字段即不再使用该配置
+    profileFields: ['business', 'host', 'port', 'connectionType', 'service', 'connectString', 'protocol', 'walletDir', 'serverCertDn', 'privilege', 'username'],
+    
+    async initProfiles() {
+        const select = document.getElementById('profileId');
+        if (!select) return;
+        this.profiles = {};
+        await this.loadProfiles();
+        
+        select.addEventListener('change', () => this.applyProfile(this.profiles[select.value]));
+        this.profileFields.concat(['password']).forEach(name => {
+            const field = document.getElementById(name);
+            if (field) {
+                field.addEventListener('input', () => {
+                    if (select.value && name !== 'business') {
+                        select.value = '';
+                        this.applyProfile(null);
+                    }
+                });
+            }
+        });
+        document.getElementById('saveProfileBtn').addEventListener('click', () => this.saveProfile());
+        document.getElementById('deleteProfileBtn').addEventListener('click', () => this.deleteProfile());
+    },
+    
+    async loadProfiles(selectedId = '') {
+        try {
+            const response = await fetch('/api/profiles');
+            if (!response.ok) return;
+            const result = await response.json();
+            if (!result.enabled) return;
+            document.getElementById('profile-group').classList.remove('d-none');
+            
+            const select = document.getElementById('profileId');
+            select.querySelectorAll('option:not([value=""])').forEach(option => option.remove());
+            this.profiles = {};
+            (result.profiles || []).forEach(profile => {
+                this.profiles[profile.id] = profile;
+                const option = document.createElement('option');
+                option.value = profile.id;
+                option.textContent = profile.name;
+                select.appendChild(option);
+            });
+            select.value = this.profiles[selectedId] ? selectedId : '';
+            this.applyProfile(this.profiles[select.value]);
+        } catch (error) {
+            console.error('Failed to load connection profiles:', error);
+        }
+    },
+    
+    applyProfile(profile) {
+        const password = document.getElementById('password');
+        document.getElementById('deleteProfileBtn').disabled = !profile;
+        password.required = !profile;
+        password.placeholder = profile ? '••••••••' : '';
+        if (!profile) return;
+        
+        password.value = '';
+        this.profileFields.forEach(name => {
+            const field = document.getElementById(name);
+            if (!field) return;
+            let value = profile[name] ?? '';
+            if (name === 'port' && !value) value = '1521';
+            if (name === 'connectionType' && !profile.connectString && !value) value = 'SERVICE_NAME';
+            if (name === 'protocol' && !value) value = 'TCP';
+            if (name === 'username' && profile.proxyClient) value = `${profile.username}[${profile.proxyClient}]`;
+            field.value = value;
+        });
+        // 更新连接方式、协议对应的输入框
+        ['connectionType', 'protocol'].forEach(id => document.getElementById(id).dispatchEvent(new Event('change')));
+    },
+    
+    async saveProfile() {
+        const select = document.getElementById('profileId');
+        const current = this.profiles[select.value];
+        const lang = document.getElementById('lang')?.value || 'zh';
+        const promptText = (window.langMap && window.langMap[lang]?.profile_name_prompt) || 'Profile name:';
+        const name = window.prompt(promptText, current ? current.name : document.getElementById('business').value);
+        if (!name) return;
+        
+        const body = { name };
+        this.profileFields.forEach(field => {
+            const element = document.getElementById(field);
+            if (element && !element.disabled) body[field] = element.value;
+        });
+        body.password = document.getElementById('password').value;
+        try {
+            const response = await fetch(current ? `/api/profiles/${encodeURIComponent(current.id)}` : '/api/profiles', {
+                method: current ? 'PUT' : 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify(body)
+            });
+            const result = await response.json();
+            if (!response.ok || !result.success) {
+                this.showMessage(`Failed to save profile: ${result.message}`, 'danger');
+                return;
+            }
+            this.showMessage(`Profile "${result.profile.name}" saved`, 'success');
+            await this.loadProfiles(result.profile.id);
+        } catch (error) {
+            this.showMessage(`Error occurred: ${error.message}`, 'danger');
+        }
+    },
+    
+    async deleteProfile() {
+        const select = document.getElementById('profileId');
+        const current = this.profiles[select.value];
+        if (!current) return;
+        const lang = document.getElementById('lang')?.value || 'zh';
+        const confirmText = (window.langMap && window.langMap[lang]?.profile_delete_confirm) || 'Delete this profile?';
+        if (!window.confirm(`${confirmText} ${current.name}`)) return;
+        try {
+            const response = await fetch(`/api/profiles/${encodeURIComponent(current.id)}`, { method: 'DELETE' });
+            const result = await response.json();
+            if (!response.ok || !result.success) {
+                this.showMessage(`Failed to delete profile: ${result.message}`, 'danger');
+                return;
+            }
+            await this.loadProfiles();
+        } catch (error) {
+            this.showMessage(`Error occurred: ${error.message}`, 'danger');
+        }
+    },
+    
+    // 从 /api/thresholds 加载阈值配置；只有一个配置时隐藏选择框
+    async loadThresholdProfiles() {
+        const select = document.getElementById('thresholdProfile');
+        if (!select) return;
+        try {
+            const response = await fetch('/api/thresholds');
+            if (!response.ok) return;
+            const result = await response.json();
+            const names = Object.keys(result.profiles || {}).sort();
+            select.innerHTML = '';
+            names.forEach(name => {
+                const option = document.createElement('option');
+                option.value = name;
+                option.textContent = name;
+                option.selected = name === result.default;
+                select.appendChild(option);
+            });
+            document.getElementById('threshold-profile-group').classList.toggle('d-none', names.length < 2);
+        } catch (error) {
+            console.error('Failed to load threshold profiles:', error);
+        }
+    },
+    
     // 初始化折叠面板
     initCollapsePanels() {
         // Use Bootstrap's collapse component
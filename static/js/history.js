@@ -0,0 +1,114 @@
+// 报告历史页面：从 /api/reports 加载并过滤报告列表
+const historyPage = {
+    escape(value) {
+        const div = document.createElement('div');
+        div.textContent = value == null ? '' : String(value);
+        return div.innerHTML;
+    },
+
+    text(key) {
+        const lang = window.languageModule.getCurrentLang();
+        return (window.langMap[lang] && window.langMap[lang][key]) || key;
+    },
+
+    async load() {
+        const form = document.getElementById('history-filter');
+        const params = new URLSearchParams();
+        new FormData(form).forEach((value, key) => {
+            if (value) params.append(key, value);
+        });
+
+        const rows = document.getElementById('history-rows');
+        const empty = document.getElementById('history-empty');
+        rows.innerHTML = '';
+        this.updateCompareButton();
+
+        try {
+            const response = await fetch(`/api/reports?${params.toString()}`);
+            if (!response.ok) {
+                throw new Error(await response.text());
+            }
+            const result = await response.json();
+            const reports = result.reports || [];
+            empty.classList.toggle('d-none', reports.length > 0);
+
+            reports.forEach(report => {
+                const tr = document.createElement('tr');
+                const errors = report.errorCount > 0
+                    ? `<span class="badge bg-danger">${report.errorCount}</span>`
+                    : '<span class="badge bg-success">0</span>';
+                const scoreClass = report.healthScore >= 80 ? 'bg-success'
+                    : report.healthScore >= 60 ? 'bg-warning text-dark' : 'bg-danger';
+                tr.innerHTML = `
+                    <td><input class="form-check-input history-select" type="checkbox" value="${this.escape(report.id)}" data-generated-at="${this.escape(report.generatedAt)}"></td>
+                    <td class="text-nowrap">${this.escape(report.generatedAt)}</td>
+                    <td>${this.escape(report.businessName)}</td>
+                    <td>${this.escape(report.dbName)}</td>
+                    <td class="text-nowrap">${this.escape(report.dbConnection)}</td>
+                    <td>${(report.modules || []).map(m => this.escape(m)).join(', ')}</td>
+                    <td>${errors}</td>
+                    <td><span class="badge ${scoreClass}">${this.escape(report.healthScore)}</span></td>
+                    <td class="text-end"><a class="btn btn-outline-dark btn-sm py-0" target="_blank" href="/report.html?id=${encodeURIComponent(report.id)}">${this.escape(this.text('history_open'))}</a></td>`;
+                rows.appendChild(tr);
+            });
+        } catch (error) {
+            console.error('Failed to load report history:', error);
+            empty.textContent = error.message;
+            empty.classList.remove('d-none');
+        }
+    },
+
+    selected() {
+        return Array.from(document.querySelectorAll('.history-select:checked'));
+    },
+
+    updateCompareButton() {
+        document.getElementById('history-compare').disabled = this.selected().length !== 2;
+    },
+
+    // 对比两份选中的报告，较早的一份作为基准
+    compare() {
+        const picked = this.selected().sort((a, b) => a.dataset.generatedAt.localeCompare(b.dataset.generatedAt));
+        if (picked.length !== 2) return;
+        const params = new URLSearchParams({ base: picked[0].value, target: picked[1].value });
+        window.open(`/diff.html?${params.toString()}`, '_blank');
+    },
+
+    // 导入 JSON 报告文件，成功后刷新列表并打开导入的报告
+    async importReport(input) {
+        const file = input.files[0];
+        if (!file) return;
+        const body = new FormData();
+        body.append('file', file);
+        try {
+            const response = await fetch('/api/reports/import', { method: 'POST', body });
+            const result = await response.json();
+            if (!response.ok || !result.success) {
+                throw new Error(result.message || response.statusText);
+            }
+            this.load();
+            window.open(`/report.html?id=${encodeURIComponent(result.reportId)}`, '_blank');
+        } catch (error) {
+            console.error('Failed to import report:', error);
+            alert(`${this.text('history_import_failed')}: ${error.message}`);
+        } finally {
+            input.value = '';
+        }
+    },
+
+    init() {
+        const form = document.getElementById('history-filter');
+        form.addEventListener('submit', event => {
+            event.preventDefault();
+            this.load();
+        });
+        form.addEventListener('reset', () => setTimeout(() => this.load(), 0));
+        document.getElementById('history-rows').addEventListener('change', () => this.updateCompareButton());
+        document.getElementById('history-compare').addEventListener('click', () => this.compare());
+        document.getElementById('history-import').addEventListener('change', event => this.importReport(event.target));
+        window.languageModule.updateTexts(window.languageModule.getCurrentLang());
+        this.load();
+    }
+};
+
+document.addEventListener('DOMContentLoaded', () => historyPage.init());
@@ -8,6 +8,22 @@ const langMap = {
         'host': '主机地址',
         'port': '端口',
         'service': '服务名/SID',
+        'connection_type': '连接方式',
+        'connection_type_service': '服务名',
+        'connection_type_sid': 'SID',
+        'connection_type_connect_string': '连接串',
+        'connect_string': 'EZConnect / 描述符 / TNS 别名',
+        'protocol': '协议',
+        'wallet_dir': 'Wallet 目录（服务器端）',
+        'server_cert_dn': '服务器证书 DN',
+        'privilege': '连接权限',
+        'privilege_normal': '普通',
+        'saved_profile': '已保存的连接',
+        'profile_none': '（不使用）',
+        'profile_save': '保存连接',
+        'profile_delete': '删除',
+        'profile_name_prompt': '连接配置名称：',
+        'profile_delete_confirm': '确定删除连接配置？',
         'username': '用户名',
         'password': '密码',
         'inspection_items': '巡检项',
@@ -29,12 +45,24 @@ const langMap = {
         'generate_report': '生成报告中...',
         'validate_only': '仅验证连接',
         'validating': '验证中...',
+        'module_ready': '就绪',
+        'module_partial': '部分可用',
+        'module_not_ready': '缺少权限',
+        'grant_script': '下载最小权限授权脚本',
         'inspection_log': '巡检日志',
 
         // report.html 页面的文本
         'sidebar_title': 'Oracle 巡检报告',
         'db_info': '数据库信息',
         'inspection_time': '巡检时间:',
+        'threshold_profile': '阈值配置:',
+        'session_user': '巡检用户:',
+        'proxy_user': '代理用户:',
+        'threshold_profile_label': '阈值配置',
+        'health_score': '健康评分',
+        'export_more': '更多格式',
+        'export_text': '纯文本 (.txt)',
+        'export_offline_html': '离线 HTML (.html)',
         'inspection_modules': '巡检模块',
         'report_overview': '报告总览',
         'report_settings': '报告设置',
@@ -47,7 +75,30 @@ const langMap = {
         'print_btn': '打印',
         'export_btn': '导出',
         'summary': '巡检概要',
-        'summary_desc': '本报告包含以下巡检模块的数据分析结果：'
+        'summary_desc': '本报告包含以下巡检模块的数据分析结果：',
+        'findings_title': '巡检发现',
+        'findings_none': '未发现问题',
+
+        // history.html 页面的文本
+        'history_link': '巡检历史',
+        'history_title': '巡检历史',
+        'history_back': '返回巡检',
+        'history_db_name': '数据库名',
+        'history_connection': '连接',
+        'history_module': '模块',
+        'history_any': '全部',
+        'history_from': '开始日期',
+        'history_to': '结束日期',
+        'history_search': '查询',
+        'history_reset': '重置',
+        'history_generated_at': '生成时间',
+        'history_modules': '巡检模块',
+        'history_errors': '错误',
+        'history_empty': '没有符合条件的报告',
+        'history_open': '打开',
+        'history_compare': '对比所选报告',
+        'history_import': '导入报告',
+        'history_import_failed': '导入报告失败'
     },
     'en': {
         // Text for the index.html page
@@ -57,6 +108,22 @@ const langMap = {
         'host': 'Host',
         'port': 'Port',
         'service': 'Service/SID',
+        'connection_type': 'Connect By',
+        'connection_type_service': 'Service Name',
+        'connection_type_sid': 'SID',
+        'connection_type_connect_string': 'Connect String',
+        'connect_string': 'EZConnect / Descriptor / TNS Alias',
+        'protocol': 'Protocol',
+        'wallet_dir': 'Wallet Directory (on server)',
+        'server_cert_dn': 'Server Certificate DN',
+        'privilege': 'Connect As',
+        'privilege_normal': 'Normal',
+        'saved_profile': 'Saved Connection',
+        'profile_none': '(none)',
+        'profile_save': 'Save Connection',
+        'profile_delete': 'Delete',
+        'profile_name_prompt': 'Profile name:',
+        'profile_delete_confirm': 'Delete connection profile?',
         'username': 'Username',
         'password': 'Password',
         'inspection_items': 'Inspection Items',
@@ -79,11 +146,23 @@ const langMap = {
         'validate_only': 'Validate Only',
         'inspection_log': 'Inspection Log',
         'validating': 'Validating...',
+        'module_ready': 'Ready',
+        'module_partial': 'Partially available',
+        'module_not_ready': 'Missing privileges',
+        'grant_script': 'Download least-privilege GRANT script',
         
         // Text for the report.html page
         'sidebar_title': 'Oracle Inspection Report',
         'db_info': 'Database Info',
         'inspection_time': 'Inspection Time:',
+        'threshold_profile': 'Threshold Profile:',
+        'session_user': 'Inspected As:',
+        'proxy_user': 'proxy user:',
+        'threshold_profile_label': 'Threshold Profile',
+        'health_score': 'Health Score',
+        'export_more': 'More Formats',
+        'export_text': 'Plain Text (.txt)',
+        'export_offline_html': 'Offline HTML (.html)',
         'inspection_modules': 'Inspection Modules',
         'report_overview': 'Report Overview',
         'report_settings': 'Report Settings',
@@ -96,7 +175,30 @@ const langMap = {
         'print_btn': 'Print',
         'export_btn': 'Export',
         'summary': 'Inspection Summary',
-        'summary_desc': 'This report contains data analysis results for the following inspection modules:'
+        'summary_desc': 'This report contains data analysis results for the following inspection modules:',
+        'findings_title': 'Findings',
+        'findings_none': 'No issues found',
+
+        // Text for the history.html page
+        'history_link': 'Report History',
+        'history_title': 'Report History',
+        'history_back': 'Back to Inspection',
+        'history_db_name': 'Database',
+        'history_connection': 'Connection',
+        'history_module': 'Module',
+        'history_any': 'Any',
+        'history_from': 'From',
+        'history_to': 'To',
+        'history_search': 'Search',
+        'history_reset': 'Reset',
+        'history_generated_at': 'Generated At',
+        'history_modules': 'Modules',
+        'history_errors': 'Errors',
+        'history_empty': 'No reports match the filter',
+        'history_open': 'Open',
+        'history_compare': 'Compare Selected',
+        'history_import': 'Import Report',
+        'history_import_failed': 'Failed to import report'
     },
     'jp': {
         // Text for the index.html page
@@ -106,6 +208,22 @@ const langMap = {
         'host': 'ホスト',
         'port': 'ポート',
         'service': 'サービス名/SID',
+        'connection_type': '接続方式',
+        'connection_type_service': 'サービス名',
+        'connection_type_sid': 'SID',
+        'connection_type_connect_string': '接続文字列',
+        'connect_string': 'EZConnect / 記述子 / TNS 別名',
+        'protocol': 'プロトコル',
+        'wallet_dir': 'Wallet ディレクトリ（サーバー側）',
+        'server_cert_dn': 'サーバー証明書 DN',
+        'privilege': '接続権限',
+        'privilege_normal': '通常',
+        'saved_profile': '保存済みの接続',
+        'profile_none': '（使用しない）',
+        'profile_save': '接続を保存',
+        'profile_delete': '削除',
+        'profile_name_prompt': '接続プロファイル名：',
+        'profile_delete_confirm': '接続プロファイルを削除しますか？',
         'username': 'ユーザー名',
         'password': 'パスワード',
         'inspection_items': '検査項目',
@@ -125,6 +243,10 @@ const langMap = {
         'validate_only': '接続のみ検証',
         'inspection_log': '検査記録',
         'validating': '検証中...',
+        'module_ready': '準備完了',
+        'module_partial': '一部利用可能',
+        'module_not_ready': '権限不足',
+        'grant_script': '最小権限の GRANT スクリプトをダウンロード',
         'connecting': '接続中...',
         'in_progress': '検査中...',
         'generate_report': 'レポート生成中...',
@@ -133,6 +255,14 @@ const langMap = {
         'sidebar_title': 'Oracle 検査レポート',
         'db_info': 'データベース情報',
         'inspection_time': '検査時間:',
+        'threshold_profile': 'しきい値プロファイル:',
+        'session_user': '検査ユーザー:',
+        'proxy_user': 'プロキシユーザー:',
+        'threshold_profile_label': 'しきい値プロファイル',
+        'health_score': 'ヘルススコア',
+        'export_more': 'その他の形式',
+        'export_text': 'テキスト (.txt)',
+        'export_offline_html': 'オフライン HTML (.html)',
         'inspection_modules': '検査モジュール',
         'report_overview': 'レポート概要',
         'report_settings': 'レポート設定',
@@ -145,7 +275,30 @@ const langMap = {
         'print_btn': '印刷',
         'export_btn': 'エクスポート',
         'summary': '検査概要',
-        'summary_desc': 'このレポートには、次の検査モジュールのデータ分析結果が含まれています：'
+        'summary_desc': 'このレポートには、次の検査モジュールのデータ分析結果が含まれています：',
+        'findings_title': '検出事項',
+        'findings_none': '問題は検出されませんでした',
+
+        // Text for the history.html page
+        'history_link': '検査履歴',
+        'history_title': '検査履歴',
+        'history_back': '検査に戻る',
+        'history_db_name': 'データベース名',
+        'history_connection': '接続',
+        'history_module': 'モジュール',
+        'history_any': 'すべて',
+        'history_from': '開始日',
+        'history_to': '終了日',
+        'history_search': '検索',
+        'history_reset': 'リセット',
+        'history_generated_at': '生成日時',
+        'history_modules': 'モジュール',
+        'history_errors': 'エラー',
+        'history_empty': '条件に一致するレポートはありません',
+        'history_open': '開く',
+        'history_compare': '選択したレポートを比較',
+        'history_import': 'レポートをインポート',
+        'history_import_failed': 'レポートのインポートに失敗しました'
     }
 };
 
@@ -169,7 +322,7 @@ function updateTexts(lang) {
             } else if (el.tagName === 'LABEL' || el.tagName === 'SPAN' || el.tagName === 'DIV' || 
                        el.tagName === 'H1' || el.tagName === 'H2' || el.tagName === 'H3' || 
                        el.tagName === 'H4' || el.tagName === 'H5' || el.tagName === 'H6' || 
-                       el.tagName === 'P') {
+                       el.tagName === 'P' || el.tagName === 'TH' || el.tagName === 'OPTION') {
                 // Add support for paragraph tags
                 el.textContent = langMap[lang][key];
                 // console.log(`Updated ${el.tagName} element with key '${key}' to: '${langMap[lang][key]}'`);
@@ -842,6 +842,24 @@ const ReportExporter = (function() {
       
       // Save the file
       saveHtmlToFile(htmlDocument, fileName);
+    },
+
+    /**
+     * Download the report rendered by the server (e.g. as PDF)
+     * @param {string} format Export format understood by /api/report/export
+     */
+    exportServer: function(format) {
+      const reportId = new URLSearchParams(window.location.search).get('id');
+      if (!reportId) return;
+      const params = new URLSearchParams({ id: reportId, format: format });
+      window.location.href = `/api/report/export?${params.toString()}`;
     }
   };
 })();
+
+// 服务端导出仅在由服务器提供的报告页面上可用（导出的离线 HTML 没有报告 ID）
+document.addEventListener('DOMContentLoaded', () => {
+  if (window.location.protocol.startsWith('http') && new URLSearchParams(window.location.search).get('id')) {
+    document.querySelectorAll('.server-export').forEach(button => button.classList.remove('d-none'));
+  }
+});